@@ -0,0 +1,119 @@
+package iotago
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUTXOAlreadySpent gets returned if an UTXO is consumed which is already spent.
+	ErrUTXOAlreadySpent = errors.New("utxo already spent")
+	// ErrSemanticValidationFailed gets returned if a transaction fails a semantic validation not covered by another ConflictReason.
+	ErrSemanticValidationFailed = errors.New("semantic validation failed")
+	// ErrUnknownConflictReason gets returned for a ConflictReason which is not known.
+	ErrUnknownConflictReason = errors.New("unknown conflict reason")
+)
+
+// LedgerInclusionState is the state of a referenced message in regards to the ledger.
+type LedgerInclusionState string
+
+const (
+	// LedgerInclusionStateIncluded denotes that the transaction of the message got applied to the ledger.
+	LedgerInclusionStateIncluded LedgerInclusionState = "included"
+	// LedgerInclusionStateConflicting denotes that the transaction of the message conflicts with the ledger.
+	LedgerInclusionStateConflicting LedgerInclusionState = "conflicting"
+	// LedgerInclusionStateNoTransaction denotes that the message does not contain a transaction.
+	LedgerInclusionStateNoTransaction LedgerInclusionState = "noTransaction"
+)
+
+// ConflictReason is the reason why the transaction of a referenced message conflicts with the ledger.
+type ConflictReason uint8
+
+const (
+	// ConflictNone denotes that the message is not conflicting.
+	ConflictNone ConflictReason = 0
+	// ConflictInputUTXOAlreadySpent denotes that an input of the transaction was already spent by a previous milestone.
+	ConflictInputUTXOAlreadySpent ConflictReason = 1
+	// ConflictInputUTXOAlreadySpentInThisMilestone denotes that an input of the transaction was already spent
+	// by another transaction referenced by the same milestone.
+	ConflictInputUTXOAlreadySpentInThisMilestone ConflictReason = 2
+	// ConflictInputUTXONotFound denotes that an input of the transaction is not known.
+	ConflictInputUTXONotFound ConflictReason = 3
+	// ConflictInputOutputSumMismatch denotes that the sum of the inputs and outputs of the transaction do not match.
+	ConflictInputOutputSumMismatch ConflictReason = 4
+	// ConflictInvalidSignature denotes that an unlock block of the transaction contains an invalid signature.
+	ConflictInvalidSignature ConflictReason = 5
+	// ConflictInvalidDustAllowance denotes that the transaction violates the dust rules.
+	ConflictInvalidDustAllowance ConflictReason = 6
+	// ConflictSemanticValidationFailed denotes that the transaction failed any other semantic validation.
+	ConflictSemanticValidationFailed ConflictReason = 255
+)
+
+// the sentinel errors corresponding to the ConflictReason(s).
+var conflictReasonErrs = map[ConflictReason]error{
+	ConflictInputUTXOAlreadySpent:                ErrUTXOAlreadySpent,
+	ConflictInputUTXOAlreadySpentInThisMilestone: ErrUTXOAlreadySpent,
+	ConflictInputUTXONotFound:                    ErrMissingUTXO,
+	ConflictInputOutputSumMismatch:               ErrInputOutputSumMismatch,
+	ConflictInvalidSignature:                     ErrEd25519SignatureInvalid,
+	ConflictInvalidDustAllowance:                 ErrInvalidDustAllowance,
+	ConflictSemanticValidationFailed:             ErrSemanticValidationFailed,
+}
+
+func (r ConflictReason) String() string {
+	switch r {
+	case ConflictNone:
+		return "none"
+	case ConflictInputUTXOAlreadySpent:
+		return "input UTXO already spent"
+	case ConflictInputUTXOAlreadySpentInThisMilestone:
+		return "input UTXO already spent in this milestone"
+	case ConflictInputUTXONotFound:
+		return "input UTXO not found"
+	case ConflictInputOutputSumMismatch:
+		return "input/output sum mismatch"
+	case ConflictInvalidSignature:
+		return "invalid signature"
+	case ConflictInvalidDustAllowance:
+		return "invalid dust allowance"
+	case ConflictSemanticValidationFailed:
+		return "semantic validation failed"
+	default:
+		return fmt.Sprintf("unknown conflict reason %d", uint8(r))
+	}
+}
+
+// Err returns an error wrapping the sentinel error which local validation returns for the same violation,
+// i.e. ErrInputOutputSumMismatch for ConflictInputOutputSumMismatch. It returns nil for ConflictNone
+// and an error wrapping ErrUnknownConflictReason for unknown reasons.
+func (r ConflictReason) Err() error {
+	if r == ConflictNone {
+		return nil
+	}
+	sentinel, has := conflictReasonErrs[r]
+	if !has {
+		return fmt.Errorf("%w: %d", ErrUnknownConflictReason, uint8(r))
+	}
+	return fmt.Errorf("%w: conflict reason %d (%s)", sentinel, uint8(r), r)
+}
+
+// ConflictReasonFromError returns the ConflictReason corresponding to the given error of a local validation.
+// Errors not corresponding to a more specific ConflictReason result in ConflictSemanticValidationFailed.
+func ConflictReasonFromError(err error) ConflictReason {
+	switch {
+	case err == nil:
+		return ConflictNone
+	case errors.Is(err, ErrUTXOAlreadySpent):
+		return ConflictInputUTXOAlreadySpent
+	case errors.Is(err, ErrMissingUTXO):
+		return ConflictInputUTXONotFound
+	case errors.Is(err, ErrInputOutputSumMismatch):
+		return ConflictInputOutputSumMismatch
+	case errors.Is(err, ErrEd25519SignatureInvalid), errors.Is(err, ErrEd25519PubKeyAndAddrMismatch):
+		return ConflictInvalidSignature
+	case errors.Is(err, ErrInvalidDustAllowance):
+		return ConflictInvalidDustAllowance
+	default:
+		return ConflictSemanticValidationFailed
+	}
+}
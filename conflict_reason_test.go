@@ -0,0 +1,76 @@
+package iotago_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+func TestConflictReason_Err(t *testing.T) {
+	tests := []struct {
+		reason   iotago.ConflictReason
+		sentinel error
+	}{
+		{iotago.ConflictInputUTXOAlreadySpent, iotago.ErrUTXOAlreadySpent},
+		{iotago.ConflictInputUTXOAlreadySpentInThisMilestone, iotago.ErrUTXOAlreadySpent},
+		{iotago.ConflictInputUTXONotFound, iotago.ErrMissingUTXO},
+		{iotago.ConflictInputOutputSumMismatch, iotago.ErrInputOutputSumMismatch},
+		{iotago.ConflictInvalidSignature, iotago.ErrEd25519SignatureInvalid},
+		{iotago.ConflictInvalidDustAllowance, iotago.ErrInvalidDustAllowance},
+		{iotago.ConflictSemanticValidationFailed, iotago.ErrSemanticValidationFailed},
+		{iotago.ConflictReason(42), iotago.ErrUnknownConflictReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason.String(), func(t *testing.T) {
+			err := tt.reason.Err()
+			require.True(t, errors.Is(err, tt.sentinel))
+			if tt.sentinel == iotago.ErrUnknownConflictReason {
+				return
+			}
+
+			// local validation can not tell whether an output was spent within the same milestone
+			expected := tt.reason
+			if expected == iotago.ConflictInputUTXOAlreadySpentInThisMilestone {
+				expected = iotago.ConflictInputUTXOAlreadySpent
+			}
+			require.Equal(t, expected, iotago.ConflictReasonFromError(err))
+		})
+	}
+	require.NoError(t, iotago.ConflictNone.Err())
+	require.Equal(t, iotago.ConflictNone, iotago.ConflictReasonFromError(nil))
+}
+
+func TestConflictReasonFromError_LocalValidation(t *testing.T) {
+	prvKey := tpkg.RandEd25519PrivateKey()
+	inputAddr := iotago.AddressFromEd25519PubKey(prvKey.Public().(ed25519.PublicKey))
+	input := randUTXOInput()
+
+	tx, err := iotago.NewTransactionBuilder().
+		AddInput(&iotago.ToBeSignedUTXOInput{Address: &inputAddr, Input: input}).
+		AddOutput(&iotago.SigLockedSingleOutput{Address: &inputAddr, Amount: 1_000_000}).
+		Build(iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(&inputAddr, prvKey)))
+	require.NoError(t, err)
+
+	err = tx.SemanticallyValidate(iotago.InputToOutputMapping{
+		input.ID(): &iotago.SigLockedSingleOutput{Address: &inputAddr, Amount: 2_000_000},
+	})
+	require.True(t, errors.Is(err, iotago.ErrInputOutputSumMismatch))
+	require.Equal(t, iotago.ConflictInputOutputSumMismatch, iotago.ConflictReasonFromError(err))
+	require.True(t, errors.Is(iotago.ConflictReasonFromError(err).Err(), iotago.ErrInputOutputSumMismatch))
+
+	require.Equal(t, iotago.ConflictInputUTXONotFound, iotago.ConflictReasonFromError(tx.SemanticallyValidate(iotago.InputToOutputMapping{})))
+}
+
+func TestMessageMetadataResponse_TypedStates(t *testing.T) {
+	metadata := &iotago.MessageMetadataResponse{}
+	require.NoError(t, json.Unmarshal([]byte(`{"ledgerInclusionState":"conflicting","conflictReason":4}`), metadata))
+	require.Equal(t, iotago.LedgerInclusionStateConflicting, *metadata.LedgerInclusionState)
+	require.Equal(t, iotago.ConflictInputOutputSumMismatch, metadata.ConflictReason)
+	require.True(t, errors.Is(metadata.ConflictReason.Err(), iotago.ErrInputOutputSumMismatch))
+}
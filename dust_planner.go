@@ -0,0 +1,433 @@
+package iotago
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrDustPlanRefused gets returned when a DustPlanner can not make a transaction adhere to the dust rules.
+	ErrDustPlanRefused = fmt.Errorf("%w: transaction plan violates the dust rules", ErrInvalidDustAllowance)
+)
+
+// DustPlannerOption is a function setting a DustPlanner option.
+type DustPlannerOption func(opts *DustPlannerOptions)
+
+// DustPlannerOptions define options for the DustPlanner.
+type DustPlannerOptions struct {
+	// Whether existing dust outputs on a violating address may be swept into the change output.
+	sweepDust bool
+	// Whether the change output may be turned into a SigLockedDustAllowanceOutput.
+	allowanceOutput bool
+}
+
+// applies the given DustPlannerOption.
+func (dpo *DustPlannerOptions) apply(opts ...DustPlannerOption) {
+	for _, opt := range opts {
+		opt(dpo)
+	}
+}
+
+// WithDustPlannerSweepDust defines whether the DustPlanner may consume existing dust outputs on a violating address
+// as additional inputs and merge them into the change output, which lowers the amount of dust outputs on the address.
+// This fix only applies to addresses for which the signer of the transaction holds the keys.
+func WithDustPlannerSweepDust(enabled bool) DustPlannerOption {
+	return func(opts *DustPlannerOptions) {
+		opts.sweepDust = enabled
+	}
+}
+
+// WithDustPlannerAllowanceOutput defines whether the DustPlanner may turn a change output of at least
+// OutputSigLockedDustAllowanceOutputMinDeposit on a violating address into a SigLockedDustAllowanceOutput.
+func WithDustPlannerAllowanceOutput(enabled bool) DustPlannerOption {
+	return func(opts *DustPlannerOptions) {
+		opts.allowanceOutput = enabled
+	}
+}
+
+// the default options applied to the DustPlanner.
+var defaultDustPlannerOptions = []DustPlannerOption{
+	WithDustPlannerSweepDust(true),
+	WithDustPlannerAllowanceOutput(true),
+}
+
+// NewDustPlanner creates a new DustPlanner which uses the given NodeHTTPAPIClient to query the dust state of addresses.
+func NewDustPlanner(nodeHTTPAPIClient NodeAPI, opts ...DustPlannerOption) *DustPlanner {
+	options := &DustPlannerOptions{}
+	options.apply(defaultDustPlannerOptions...)
+	options.apply(opts...)
+	return &DustPlanner{nodeAPI: nodeHTTPAPIClient, opts: options}
+}
+
+// DustPlanner checks transaction plans against the dust rules enforced by NewDustSemanticValidation before they are signed
+// and either fixes them or refuses them with a detailed explanation.
+type DustPlanner struct {
+	nodeAPI NodeAPI
+	opts    *DustPlannerOptions
+}
+
+// DustAddressState is the dust related state of an address.
+type DustAddressState struct {
+	// The address.
+	Address Address
+	// The sum of deposits of all SigLockedDustAllowanceOutput(s) on the address.
+	DustAllowanceSum uint64
+	// The dust outputs residing on the address.
+	DustOutputs map[*UTXOInput]Output
+	// The ledger index at which the state was queried.
+	LedgerIndex uint64
+}
+
+// DustAddressState queries the dust related state of the given address.
+func (dp *DustPlanner) DustAddressState(ctx context.Context, addr Address) (*DustAddressState, error) {
+	edAddr, ok := addr.(*Ed25519Address)
+	if !ok {
+		return nil, fmt.Errorf("%w: dust planner only supports Ed25519Address but got %T", ErrUnknownAddrType, addr)
+	}
+
+	res, unspentOutputs, err := dp.nodeAPI.OutputsByEd25519Address(ctx, edAddr, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query outputs of address %s: %w", addr, err)
+	}
+
+	state := &DustAddressState{Address: addr, DustOutputs: make(map[*UTXOInput]Output), LedgerIndex: res.LedgerIndex}
+	for utxoInput, output := range unspentOutputs {
+		deposit, err := output.Deposit()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get deposit of output %s: %w", utxoInput.ID().ToHex(), err)
+		}
+		switch output.Type() {
+		case OutputSigLockedDustAllowanceOutput:
+			state.DustAllowanceSum += deposit
+		case OutputSigLockedSingleOutput:
+			if deposit < OutputSigLockedDustAllowanceOutputMinDeposit {
+				state.DustOutputs[utxoInput] = output
+			}
+		}
+	}
+	return state, nil
+}
+
+// DustAddressReport describes the effect of a transaction on the dust state of an address.
+type DustAddressReport struct {
+	// The address.
+	Address Address
+	// The sum of deposits of SigLockedDustAllowanceOutput(s) on the address before the transaction.
+	DustAllowanceSum uint64
+	// The change of the sum of deposits of SigLockedDustAllowanceOutput(s) by the transaction.
+	DustAllowanceSumDelta int64
+	// The amount of dust outputs on the address before the transaction.
+	DustOutputs int64
+	// The change of the amount of dust outputs by the transaction.
+	DustOutputsDelta int64
+}
+
+// Allowed returns the amount of dust outputs allowed on the address after the transaction.
+func (r *DustAddressReport) Allowed() int64 {
+	allowed := (int64(r.DustAllowanceSum) + r.DustAllowanceSumDelta) / DustAllowanceDivisor
+	if allowed > MaxDustOutputsOnAddress {
+		allowed = MaxDustOutputsOnAddress
+	}
+	return allowed
+}
+
+// Excess returns the amount of dust outputs exceeding the allowance after the transaction.
+func (r *DustAddressReport) Excess() int64 {
+	if excess := r.DustOutputs + r.DustOutputsDelta - r.Allowed(); excess > 0 {
+		return excess
+	}
+	return 0
+}
+
+func (r *DustAddressReport) String() string {
+	return fmt.Sprintf("addr %s: dust outputs %d (%+d), dust allowance deposit %d (%+d), allowed dust outputs %d, excess %d",
+		r.Address, r.DustOutputs, r.DustOutputsDelta, r.DustAllowanceSum, r.DustAllowanceSumDelta, r.Allowed(), r.Excess())
+}
+
+// DustPlan is the result of a DustPlanner run.
+type DustPlan struct {
+	// The reports for every address whose dust state is affected by the transaction.
+	Reports []*DustAddressReport
+	// The inputs which were added to the transaction by the planner.
+	AddedInputs []*ToBeSignedUTXOInput
+}
+
+// Plan checks the given transaction essence against the dust rules and fixes it in place if it violates them and the
+// enabled fixes allow to do so. utxos must contain the outputs referenced by the inputs of the essence, missing ones are
+// queried from the node. The change address is where swept dust is deposited to, if nil, swept dust is deposited
+// back onto the violating address. Inputs added by the planner are returned in the DustPlan and must be signed
+// by the caller. If the plan can't be fixed, an error wrapping ErrDustPlanRefused is returned.
+func (dp *DustPlanner) Plan(ctx context.Context, essence *TransactionEssence, utxos InputToOutputMapping, changeAddr Address) (*DustPlan, error) {
+	if err := dp.resolveUTXOs(ctx, essence, utxos); err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]*DustAddressState)
+	dustAllowanceFunc := func(addr Address) (uint64, int64, error) {
+		state, err := dp.cachedDustAddressState(ctx, states, addr)
+		if err != nil {
+			return 0, 0, err
+		}
+		return state.DustAllowanceSum, int64(len(state.DustOutputs)), nil
+	}
+
+	plan := &DustPlan{}
+	for {
+		reports, err := dustAddressReports(essence, utxos, dustAllowanceFunc)
+		if err != nil {
+			return nil, err
+		}
+		plan.Reports = reports
+
+		fixed := false
+		for _, report := range reports {
+			if report.Excess() == 0 {
+				continue
+			}
+			if fixed, err = dp.fix(report, states[report.Address.String()], essence, utxos, changeAddr, plan); err != nil {
+				return nil, err
+			}
+			if !fixed {
+				return nil, dustPlanRefusedError(reports)
+			}
+			// reports must be recomputed as the fix changed the transaction
+			break
+		}
+
+		if !fixed {
+			break
+		}
+	}
+
+	// the final plan must pass the same validation the node runs
+	dustValidation := NewDustSemanticValidation(DustAllowanceDivisor, MaxDustOutputsOnAddress, dustAllowanceFunc)
+	if err := dustValidation(&Transaction{Essence: essence}, utxos); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDustPlanRefused, err)
+	}
+
+	return plan, nil
+}
+
+// fixes the dust violation described by the report, returns false if no enabled fix was applicable.
+func (dp *DustPlanner) fix(report *DustAddressReport, state *DustAddressState, essence *TransactionEssence, utxos InputToOutputMapping, changeAddr Address, plan *DustPlan) (bool, error) {
+	addrKey := report.Address.String()
+
+	if dp.opts.allowanceOutput && changeAddr != nil && changeAddr.String() == addrKey {
+		for i, output := range essence.Outputs {
+			singleOutput, ok := output.(*SigLockedSingleOutput)
+			if !ok || singleOutput.Address.(Address).String() != addrKey || singleOutput.Amount < OutputSigLockedDustAllowanceOutputMinDeposit {
+				continue
+			}
+			essence.Outputs = append(essence.Outputs[:i], essence.Outputs[i+1:]...)
+			addAmountToAddress(essence, &SigLockedDustAllowanceOutput{Address: changeAddr, Amount: singleOutput.Amount})
+			return true, nil
+		}
+	}
+
+	if !dp.opts.sweepDust || !dustPlanControlsAddress(essence, utxos, changeAddr, addrKey) {
+		return false, nil
+	}
+
+	// sweep the smallest dust outputs first which are not yet consumed by the transaction
+	sweepable := make(InputCandidates, 0)
+	for utxoInput, output := range state.DustOutputs {
+		if _, consumed := utxos[utxoInput.ID()]; consumed {
+			continue
+		}
+		candidate, err := NewInputCandidate(report.Address, utxoInput, output)
+		if err != nil {
+			return false, err
+		}
+		sweepable = append(sweepable, candidate)
+	}
+	sort.Slice(sweepable, func(i, j int) bool {
+		return sweepable[i].Deposit() < sweepable[j].Deposit()
+	})
+
+	// sweeping n dust outputs into an output lowers the dust count by n-1 in the worst case
+	sweepCount := int(report.Excess()) + 1
+	if free := MaxInputsCount - len(essence.Inputs); sweepCount > free {
+		sweepCount = free
+	}
+	if sweepCount > len(sweepable) {
+		sweepCount = len(sweepable)
+	}
+	if sweepCount < 2 {
+		return false, nil
+	}
+
+	sweepTarget := changeAddr
+	if sweepTarget == nil {
+		sweepTarget = report.Address
+	}
+
+	for _, candidate := range sweepable[:sweepCount] {
+		toBeSigned := &ToBeSignedUTXOInput{Address: candidate.Address, Input: candidate.Input}
+		essence.Inputs = append(essence.Inputs, candidate.Input)
+		utxos[candidate.Input.ID()] = candidate.Output
+		plan.AddedInputs = append(plan.AddedInputs, toBeSigned)
+	}
+	addAmountToAddress(essence, &SigLockedSingleOutput{Address: sweepTarget, Amount: sweepable[:sweepCount].Sum()})
+	return true, nil
+}
+
+// tells whether the address is either the change address or the address of one of the inputs.
+func dustPlanControlsAddress(essence *TransactionEssence, utxos InputToOutputMapping, changeAddr Address, addrKey string) bool {
+	if changeAddr != nil && changeAddr.String() == addrKey {
+		return true
+	}
+	for _, input := range essence.Inputs {
+		target, err := utxos[input.(*UTXOInput).ID()].Target()
+		if err != nil {
+			continue
+		}
+		if addr, ok := target.(Address); ok && addr.String() == addrKey {
+			return true
+		}
+	}
+	return false
+}
+
+// queries the UTXOs of inputs which are not in the given mapping.
+func (dp *DustPlanner) resolveUTXOs(ctx context.Context, essence *TransactionEssence, utxos InputToOutputMapping) error {
+	for i, input := range essence.Inputs {
+		utxoInput, ok := input.(*UTXOInput)
+		if !ok {
+			return fmt.Errorf("%w: unsupported input type at index %d", ErrUnknownInputType, i)
+		}
+		if _, has := utxos[utxoInput.ID()]; has {
+			continue
+		}
+		res, err := dp.nodeAPI.OutputByID(ctx, utxoInput.ID())
+		if err != nil {
+			return fmt.Errorf("unable to query UTXO %s (input at index %d): %w", utxoInput.ID().ToHex(), i, err)
+		}
+		output, err := res.Output()
+		if err != nil {
+			return fmt.Errorf("unable to decode UTXO %s (input at index %d): %w", utxoInput.ID().ToHex(), i, err)
+		}
+		utxos[utxoInput.ID()] = output
+	}
+	return nil
+}
+
+// returns the DustAddressState from the cache or queries it.
+func (dp *DustPlanner) cachedDustAddressState(ctx context.Context, states map[string]*DustAddressState, addr Address) (*DustAddressState, error) {
+	if state, has := states[addr.String()]; has {
+		return state, nil
+	}
+	state, err := dp.DustAddressState(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	states[addr.String()] = state
+	return state, nil
+}
+
+// computes the DustAddressReport for every address whose dust state is affected by the transaction
+// by applying the same accounting as NewDustSemanticValidation.
+func dustAddressReports(essence *TransactionEssence, utxos InputToOutputMapping, dustAllowanceFunc DustAllowanceFunc) ([]*DustAddressReport, error) {
+	reports := make(map[string]*DustAddressReport)
+	reportFor := func(addr Address) *DustAddressReport {
+		report, has := reports[addr.String()]
+		if !has {
+			report = &DustAddressReport{Address: addr}
+			reports[addr.String()] = report
+		}
+		return report
+	}
+
+	for _, output := range essence.Outputs {
+		switch out := output.(type) {
+		case *SigLockedDustAllowanceOutput:
+			reportFor(out.Address.(Address)).DustAllowanceSumDelta += int64(out.Amount)
+		case *SigLockedSingleOutput:
+			if out.Amount < OutputSigLockedDustAllowanceOutputMinDeposit {
+				reportFor(out.Address.(Address)).DustOutputsDelta++
+			}
+		}
+	}
+
+	for i, input := range essence.Inputs {
+		utxoID := input.(*UTXOInput).ID()
+		utxo, ok := utxos[utxoID]
+		if !ok {
+			return nil, fmt.Errorf("%w: UTXO for ID %v is not provided (input at index %d)", ErrMissingUTXO, utxoID, i)
+		}
+
+		deposit, err := utxo.Deposit()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get deposit from UTXO %v (input at index %d): %w", utxoID, i, err)
+		}
+
+		target, err := utxo.Target()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get target of UTXO %v (input at index %d): %w", utxoID, i, err)
+		}
+
+		switch {
+		case deposit < OutputSigLockedDustAllowanceOutputMinDeposit:
+			reportFor(target.(Address)).DustOutputsDelta--
+		case utxo.Type() == OutputSigLockedDustAllowanceOutput:
+			reportFor(target.(Address)).DustAllowanceSumDelta -= int64(deposit)
+		}
+	}
+
+	sorted := make([]*DustAddressReport, 0, len(reports))
+	for _, report := range reports {
+		dustAllowanceSum, dustOutputs, err := dustAllowanceFunc(report.Address)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch dust allowance information on address %v: %w", report.Address, err)
+		}
+		report.DustAllowanceSum = dustAllowanceSum
+		report.DustOutputs = dustOutputs
+		sorted = append(sorted, report)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Address.String() < sorted[j].Address.String()
+	})
+
+	return sorted, nil
+}
+
+// builds the error explaining why the plan was refused.
+func dustPlanRefusedError(reports []*DustAddressReport) error {
+	var b strings.Builder
+	for _, report := range reports {
+		if report.Excess() == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(report.String())
+	}
+	return fmt.Errorf("%w: %s", ErrDustPlanRefused, b.String())
+}
+
+// adds the given output's deposit onto an existing output of the same type and address
+// within the essence or appends the output if there is none. The existing output is replaced
+// by a new one instead of being modified, as it might be the one passed to TransactionBuilder.AddOutput.
+func addAmountToAddress(essence *TransactionEssence, output Output) {
+	target, _ := output.Target()
+	addrKey := target.(Address).String()
+	amount, _ := output.Deposit()
+
+	for i, existing := range essence.Outputs {
+		switch out := existing.(type) {
+		case *SigLockedSingleOutput:
+			if output.Type() == OutputSigLockedSingleOutput && out.Address.(Address).String() == addrKey {
+				essence.Outputs[i] = &SigLockedSingleOutput{Address: out.Address, Amount: out.Amount + amount}
+				return
+			}
+		case *SigLockedDustAllowanceOutput:
+			if output.Type() == OutputSigLockedDustAllowanceOutput && out.Address.(Address).String() == addrKey {
+				essence.Outputs[i] = &SigLockedDustAllowanceOutput{Address: out.Address, Amount: out.Amount + amount}
+				return
+			}
+		}
+	}
+	essence.Outputs = append(essence.Outputs, output)
+}
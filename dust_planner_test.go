@@ -0,0 +1,149 @@
+package iotago_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+// mocks the outputs route of the given address and the output route of each output.
+func mockAddressOutputs(t *testing.T, addr *iotago.Ed25519Address, outputs map[*iotago.UTXOInput]iotago.Output) {
+	outputIDs := make([]iotago.OutputIDHex, 0, len(outputs))
+	for utxoInput, output := range outputs {
+		utxoInputID := utxoInput.ID()
+		outputIDs = append(outputIDs, iotago.OutputIDHex(utxoInputID.ToHex()))
+
+		outputJson, err := output.MarshalJSON()
+		require.NoError(t, err)
+		rawOutputJson := json.RawMessage(outputJson)
+
+		gock.New(nodeAPIUrl).
+			Get(fmt.Sprintf(iotago.NodeAPIRouteOutput, utxoInputID.ToHex())).
+			Reply(200).
+			JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeOutputResponse{
+				TransactionID: fmt.Sprintf("%x", utxoInput.TransactionID),
+				OutputIndex:   utxoInput.TransactionOutputIndex,
+				LedgerIndex:   1337,
+				RawOutput:     &rawOutputJson,
+			}})
+	}
+
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Outputs, addr.String())).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+			AddressType: iotago.AddressEd25519,
+			Address:     addr.String(),
+			MaxResults:  1000,
+			Count:       uint32(len(outputIDs)),
+			OutputIDs:   outputIDs,
+			LedgerIndex: 1337,
+		}})
+}
+
+func randUTXOInput() *iotago.UTXOInput {
+	return &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray(), TransactionOutputIndex: 0}
+}
+
+func TestDustPlanner_Plan(t *testing.T) {
+	const mi = iotago.OutputSigLockedDustAllowanceOutputMinDeposit
+
+	t.Run("err - dust to recipient without allowance", func(t *testing.T) {
+		defer gock.Off()
+
+		senderAddr, _ := tpkg.RandEd25519Address()
+		recipientAddr, _ := tpkg.RandEd25519Address()
+		mockAddressOutputs(t, recipientAddr, nil)
+
+		input := randUTXOInput()
+		essence := &iotago.TransactionEssence{
+			Inputs: []serializer.Serializable{input},
+			Outputs: []serializer.Serializable{
+				&iotago.SigLockedSingleOutput{Address: recipientAddr, Amount: 500},
+				&iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 5*mi - 500},
+			},
+		}
+		utxos := iotago.InputToOutputMapping{input.ID(): &iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 5 * mi}}
+
+		planner := iotago.NewDustPlanner(iotago.NewNodeHTTPAPIClient(nodeAPIUrl))
+		_, err := planner.Plan(context.Background(), essence, utxos, senderAddr)
+		require.True(t, errors.Is(err, iotago.ErrDustPlanRefused))
+		require.True(t, errors.Is(err, iotago.ErrInvalidDustAllowance))
+		require.Contains(t, err.Error(), recipientAddr.String())
+	})
+
+	t.Run("ok - change turned into dust allowance output", func(t *testing.T) {
+		defer gock.Off()
+
+		senderAddr, _ := tpkg.RandEd25519Address()
+		recipientAddr, _ := tpkg.RandEd25519Address()
+
+		allowanceInput, input := randUTXOInput(), randUTXOInput()
+		allowanceOutput := &iotago.SigLockedDustAllowanceOutput{Address: senderAddr, Amount: 1 * mi}
+		output := &iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 3 * mi}
+		senderOutputs := map[*iotago.UTXOInput]iotago.Output{allowanceInput: allowanceOutput, input: output}
+		for i := 0; i < 5; i++ {
+			senderOutputs[randUTXOInput()] = &iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 100}
+		}
+		mockAddressOutputs(t, senderAddr, senderOutputs)
+
+		essence := &iotago.TransactionEssence{
+			Inputs: []serializer.Serializable{allowanceInput, input},
+			Outputs: []serializer.Serializable{
+				&iotago.SigLockedSingleOutput{Address: recipientAddr, Amount: 2 * mi},
+				&iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 2 * mi},
+			},
+		}
+		utxos := iotago.InputToOutputMapping{allowanceInput.ID(): allowanceOutput, input.ID(): output}
+
+		planner := iotago.NewDustPlanner(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), iotago.WithDustPlannerSweepDust(false))
+		plan, err := planner.Plan(context.Background(), essence, utxos, senderAddr)
+		require.NoError(t, err)
+		require.Empty(t, plan.AddedInputs)
+		require.Len(t, essence.Outputs, 2)
+		require.Equal(t, &iotago.SigLockedDustAllowanceOutput{Address: senderAddr, Amount: 2 * mi}, essence.Outputs[1])
+	})
+
+	t.Run("ok - dust swept into change", func(t *testing.T) {
+		defer gock.Off()
+
+		senderAddr, _ := tpkg.RandEd25519Address()
+		recipientAddr, _ := tpkg.RandEd25519Address()
+
+		input := randUTXOInput()
+		output := &iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 5 * mi}
+		senderOutputs := map[*iotago.UTXOInput]iotago.Output{
+			input:           output,
+			randUTXOInput(): &iotago.SigLockedDustAllowanceOutput{Address: senderAddr, Amount: 1 * mi},
+		}
+		for i := 0; i < 10; i++ {
+			senderOutputs[randUTXOInput()] = &iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 100}
+		}
+		mockAddressOutputs(t, senderAddr, senderOutputs)
+
+		essence := &iotago.TransactionEssence{
+			Inputs: []serializer.Serializable{input},
+			Outputs: []serializer.Serializable{
+				&iotago.SigLockedSingleOutput{Address: recipientAddr, Amount: 5*mi - 500},
+				&iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 500},
+			},
+		}
+		utxos := iotago.InputToOutputMapping{input.ID(): output}
+
+		planner := iotago.NewDustPlanner(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), iotago.WithDustPlannerAllowanceOutput(false))
+		plan, err := planner.Plan(context.Background(), essence, utxos, senderAddr)
+		require.NoError(t, err)
+		require.Len(t, plan.AddedInputs, 2)
+		require.Len(t, essence.Inputs, 3)
+		require.Equal(t, &iotago.SigLockedSingleOutput{Address: senderAddr, Amount: 700}, essence.Outputs[1])
+	})
+}
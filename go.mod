@@ -8,8 +8,10 @@ require (
 	github.com/finderAUT/hive.go/v2 v2.0.0
 	github.com/iotaledger/iota.go v1.0.0
 	github.com/stretchr/testify v1.7.0
+	github.com/tyler-smith/go-bip39 v1.1.0
 	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
 	google.golang.org/grpc v1.40.0
 	google.golang.org/protobuf v1.27.1
 	gopkg.in/h2non/gock.v1 v1.1.2
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -0,0 +1,340 @@
+package iotago
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/iotaledger/iota.go/v2/ed25519"
+)
+
+const (
+	// BIP32HardenedOffset is the offset added to an index within a BIP32Path to denote a hardened derivation.
+	BIP32HardenedOffset uint32 = 1 << 31
+	// BIP44Purpose is the purpose of a BIP-44 derivation path.
+	BIP44Purpose uint32 = 44
+	// IOTABIP44CoinType is the registered SLIP-44 coin type of IOTA.
+	IOTABIP44CoinType uint32 = 4218
+
+	// DefaultMnemonicEntropyBitSize is the entropy bit size of a mnemonic created via NewMnemonic (24 words).
+	DefaultMnemonicEntropyBitSize = 256
+
+	// the HMAC key used to derive the SLIP-10 Ed25519 master key from a seed.
+	slip10Ed25519SeedKey = "ed25519 seed"
+)
+
+var (
+	// ErrMnemonicInvalid gets returned when a mnemonic has an invalid word count, unknown words or an invalid checksum.
+	ErrMnemonicInvalid = errors.New("invalid mnemonic")
+	// ErrBIP32PathInvalid gets returned when a BIP32Path can not be parsed.
+	ErrBIP32PathInvalid = errors.New("invalid BIP32 path")
+	// ErrBIP32PathNotHardened gets returned when a BIP32Path containing non-hardened indices is used for a
+	// SLIP-10 Ed25519 derivation, which only supports hardened derivations.
+	ErrBIP32PathNotHardened = errors.New("SLIP-10 Ed25519 derivation only supports hardened indices")
+	// ErrHDKeychainAddressUnknown gets returned when a HDKeychain can not find the derivation path of an address.
+	ErrHDKeychainAddressUnknown = fmt.Errorf("%w: address is not derived from the keychain", ErrAddressKeysNotMapped)
+)
+
+// NewMnemonic creates a new BIP-39 mnemonic from random entropy of the given bit size.
+// The bit size must be a multiple of 32 within [128, 256], use DefaultMnemonicEntropyBitSize for a 24 words mnemonic.
+func NewMnemonic(entropyBitSize int) (string, error) {
+	entropy, err := bip39.NewEntropy(entropyBitSize)
+	if err != nil {
+		return "", fmt.Errorf("unable to create mnemonic entropy: %w", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// SeedFromMnemonic validates the given BIP-39 mnemonic and derives the seed from it and the given (optional) passphrase.
+func SeedFromMnemonic(mnemonic string, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMnemonicInvalid, err)
+	}
+	return seed, nil
+}
+
+// BIP32Path is a BIP-32 derivation path. Hardened indices include the BIP32HardenedOffset.
+type BIP32Path []uint32
+
+// IOTABIP32Path returns the BIP32Path m/44'/4218'/account'/change'/index' of the given account, change and address index.
+func IOTABIP32Path(account uint32, change bool, index uint32) BIP32Path {
+	var changeIndex uint32
+	if change {
+		changeIndex = 1
+	}
+	return BIP32Path{
+		BIP44Purpose | BIP32HardenedOffset,
+		IOTABIP44CoinType | BIP32HardenedOffset,
+		account | BIP32HardenedOffset,
+		changeIndex | BIP32HardenedOffset,
+		index | BIP32HardenedOffset,
+	}
+}
+
+// ParseBIP32Path parses a BIP32Path in its string form, i.e. "m/44'/4218'/0'/0'/0'".
+// Hardened indices are denoted by a trailing "'", "h" or "H".
+func ParseBIP32Path(s string) (BIP32Path, error) {
+	segments := strings.Split(s, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("%w: %s must start with 'm'", ErrBIP32PathInvalid, s)
+	}
+
+	path := make(BIP32Path, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		var hardened bool
+		if trimmed := strings.TrimRight(segment, "'hH"); len(trimmed) == len(segment)-1 {
+			segment, hardened = trimmed, true
+		}
+
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || uint32(index) >= BIP32HardenedOffset {
+			return nil, fmt.Errorf("%w: invalid segment '%s' in %s", ErrBIP32PathInvalid, segment, s)
+		}
+		if hardened {
+			index |= uint64(BIP32HardenedOffset)
+		}
+		path = append(path, uint32(index))
+	}
+	return path, nil
+}
+
+func (p BIP32Path) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range p {
+		b.WriteString("/")
+		if index >= BIP32HardenedOffset {
+			b.WriteString(strconv.FormatUint(uint64(index-BIP32HardenedOffset), 10))
+			b.WriteString("'")
+			continue
+		}
+		b.WriteString(strconv.FormatUint(uint64(index), 10))
+	}
+	return b.String()
+}
+
+// Slip10ExtendedKey is a SLIP-10 Ed25519 extended private key.
+type Slip10ExtendedKey struct {
+	// The private key material, which is the seed of the Ed25519 private key.
+	Key [32]byte
+	// The chain code.
+	ChainCode [32]byte
+}
+
+// NewSlip10MasterKey derives the SLIP-10 Ed25519 master key from the given seed.
+func NewSlip10MasterKey(seed []byte) *Slip10ExtendedKey {
+	mac := hmac.New(sha512.New, []byte(slip10Ed25519SeedKey))
+	_, _ = mac.Write(seed)
+	return slip10ExtendedKeyFromHMAC(mac.Sum(nil))
+}
+
+// Child derives the hardened child with the given index. The index must include the BIP32HardenedOffset.
+func (k *Slip10ExtendedKey) Child(index uint32) (*Slip10ExtendedKey, error) {
+	if index < BIP32HardenedOffset {
+		return nil, fmt.Errorf("%w: index %d", ErrBIP32PathNotHardened, index)
+	}
+
+	var data [1 + 32 + serializer.UInt32ByteSize]byte
+	copy(data[1:], k.Key[:])
+	binary.BigEndian.PutUint32(data[1+32:], index)
+
+	mac := hmac.New(sha512.New, k.ChainCode[:])
+	_, _ = mac.Write(data[:])
+	return slip10ExtendedKeyFromHMAC(mac.Sum(nil)), nil
+}
+
+// Derive derives the descendant along the given BIP32Path.
+func (k *Slip10ExtendedKey) Derive(path BIP32Path) (*Slip10ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKey returns the Ed25519 private key of the extended key.
+func (k *Slip10ExtendedKey) PrivateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(k.Key[:])
+}
+
+func slip10ExtendedKeyFromHMAC(sum []byte) *Slip10ExtendedKey {
+	k := &Slip10ExtendedKey{}
+	copy(k.Key[:], sum[:32])
+	copy(k.ChainCode[:], sum[32:])
+	return k
+}
+
+// HDKeychainOption is a function setting a HDKeychain option.
+type HDKeychainOption func(opts *HDKeychainOptions)
+
+// HDKeychainOptions define options for the HDKeychain.
+type HDKeychainOptions struct {
+	// The amount of address indices per chain scanned for the address to sign for, if it was not derived before.
+	lookahead uint32
+}
+
+// applies the given HDKeychainOption.
+func (hko *HDKeychainOptions) apply(opts ...HDKeychainOption) {
+	for _, opt := range opts {
+		opt(hko)
+	}
+}
+
+// WithHDKeychainLookahead defines the amount of address indices per chain (public and change) the HDKeychain
+// scans for when it is asked to sign for an address which was not derived through it before.
+func WithHDKeychainLookahead(lookahead uint32) HDKeychainOption {
+	return func(opts *HDKeychainOptions) {
+		opts.lookahead = lookahead
+	}
+}
+
+// the default options applied to the HDKeychain.
+var defaultHDKeychainOptions = []HDKeychainOption{
+	WithHDKeychainLookahead(20),
+}
+
+// NewHDKeychain creates a new HDKeychain for the given account deriving its keys from the given seed.
+func NewHDKeychain(seed []byte, account uint32, opts ...HDKeychainOption) (*HDKeychain, error) {
+	options := &HDKeychainOptions{}
+	options.apply(defaultHDKeychainOptions...)
+	options.apply(opts...)
+
+	accountKey, err := NewSlip10MasterKey(seed).Derive(IOTABIP32Path(account, false, 0)[:3])
+	if err != nil {
+		return nil, err
+	}
+
+	return &HDKeychain{
+		opts:       options,
+		account:    account,
+		accountKey: accountKey,
+		paths:      make(map[string]hdKeychainPath),
+	}, nil
+}
+
+// NewHDKeychainFromMnemonic creates a new HDKeychain for the given account deriving its keys from the seed
+// of the given BIP-39 mnemonic and (optional) passphrase.
+func NewHDKeychainFromMnemonic(mnemonic string, passphrase string, account uint32, opts ...HDKeychainOption) (*HDKeychain, error) {
+	seed, err := SeedFromMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewHDKeychain(seed, account, opts...)
+}
+
+// HDKeychain derives the Ed25519Address(es) of an account along m/44'/4218'/account'/change'/index'.
+// It implements AddressSigner by deriving the private key of an address on demand, private keys are not retained.
+type HDKeychain struct {
+	mu         sync.Mutex
+	opts       *HDKeychainOptions
+	account    uint32
+	accountKey *Slip10ExtendedKey
+	// maps addresses derived through the keychain to their path
+	paths map[string]hdKeychainPath
+}
+
+// the change and address index of an address within the account.
+type hdKeychainPath struct {
+	change bool
+	index  uint32
+}
+
+// Account returns the account index of the HDKeychain.
+func (k *HDKeychain) Account() uint32 {
+	return k.account
+}
+
+// Path returns the BIP32Path of the address with the given change and address index.
+func (k *HDKeychain) Path(change bool, index uint32) BIP32Path {
+	return IOTABIP32Path(k.account, change, index)
+}
+
+// PrivateKey derives the private key of the address with the given change and address index.
+func (k *HDKeychain) PrivateKey(change bool, index uint32) (ed25519.PrivateKey, error) {
+	key, err := k.accountKey.Derive(k.Path(change, index)[3:])
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey(), nil
+}
+
+// Address derives the Ed25519Address with the given change and address index.
+func (k *HDKeychain) Address(change bool, index uint32) (*Ed25519Address, error) {
+	prvKey, err := k.PrivateKey(change, index)
+	if err != nil {
+		return nil, err
+	}
+	addr := AddressFromEd25519PubKey(prvKey.Public().(ed25519.PublicKey))
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.paths[addr.String()] = hdKeychainPath{change: change, index: index}
+	return &addr, nil
+}
+
+// AddressKeys derives the AddressKeys of the address with the given change and address index.
+func (k *HDKeychain) AddressKeys(change bool, index uint32) (AddressKeys, error) {
+	prvKey, err := k.PrivateKey(change, index)
+	if err != nil {
+		return AddressKeys{}, err
+	}
+	addr := AddressFromEd25519PubKey(prvKey.Public().(ed25519.PublicKey))
+	return NewAddressKeysForEd25519Address(&addr, prvKey), nil
+}
+
+func (k *HDKeychain) Sign(addr Address, msg []byte) (signature serializer.Serializable, err error) {
+	edAddr, ok := addr.(*Ed25519Address)
+	if !ok {
+		return nil, fmt.Errorf("%w: type %T", ErrUnknownAddrType, addr)
+	}
+
+	path, err := k.lookup(edAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	prvKey, err := k.PrivateKey(path.change, path.index)
+	if err != nil {
+		return nil, err
+	}
+
+	ed25519Sig := &Ed25519Signature{}
+	copy(ed25519Sig.Signature[:], ed25519.Sign(prvKey, msg))
+	copy(ed25519Sig.PublicKey[:], prvKey.Public().(ed25519.PublicKey))
+	return ed25519Sig, nil
+}
+
+// returns the path of the given address, scanning the first addresses of both chains if it was not derived before.
+func (k *HDKeychain) lookup(addr *Ed25519Address) (hdKeychainPath, error) {
+	k.mu.Lock()
+	path, has := k.paths[addr.String()]
+	k.mu.Unlock()
+	if has {
+		return path, nil
+	}
+
+	for index := uint32(0); index < k.opts.lookahead; index++ {
+		for _, change := range []bool{false, true} {
+			derived, err := k.Address(change, index)
+			if err != nil {
+				return hdKeychainPath{}, err
+			}
+			if *derived == *addr {
+				return hdKeychainPath{change: change, index: index}, nil
+			}
+		}
+	}
+	return hdKeychainPath{}, fmt.Errorf("%w: %s", ErrHDKeychainAddressUnknown, addr)
+}
@@ -0,0 +1,117 @@
+package iotago_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+func TestSlip10ExtendedKey_Derive(t *testing.T) {
+	// test vector 1 for ed25519 of SLIP-0010
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	tests := []struct {
+		path      string
+		chainCode string
+		key       string
+	}{
+		{"m", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+		{"m/0'", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+		{"m/0'/1'", "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := iotago.ParseBIP32Path(tt.path)
+			require.NoError(t, err)
+			require.Equal(t, tt.path, path.String())
+
+			key, err := iotago.NewSlip10MasterKey(seed).Derive(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.chainCode, hex.EncodeToString(key.ChainCode[:]))
+			require.Equal(t, tt.key, hex.EncodeToString(key.Key[:]))
+		})
+	}
+
+	_, err = iotago.NewSlip10MasterKey(seed).Derive(iotago.BIP32Path{1})
+	require.True(t, errors.Is(err, iotago.ErrBIP32PathNotHardened))
+}
+
+func TestParseBIP32Path(t *testing.T) {
+	path, err := iotago.ParseBIP32Path("m/44'/4218'/1'/1h/5H")
+	require.NoError(t, err)
+	require.Equal(t, iotago.IOTABIP32Path(1, true, 5), path)
+	require.Equal(t, "m/44'/4218'/1'/1'/5'", path.String())
+
+	for _, invalid := range []string{"", "44'/0'", "m/x'", "m/1''", "m/2147483648"} {
+		_, err := iotago.ParseBIP32Path(invalid)
+		require.True(t, errors.Is(err, iotago.ErrBIP32PathInvalid), invalid)
+	}
+}
+
+func TestHDKeychain(t *testing.T) {
+	mnemonic, err := iotago.NewMnemonic(iotago.DefaultMnemonicEntropyBitSize)
+	require.NoError(t, err)
+
+	_, err = iotago.NewHDKeychainFromMnemonic("abandon abandon abandon", "", 0)
+	require.True(t, errors.Is(err, iotago.ErrMnemonicInvalid))
+
+	keychain, err := iotago.NewHDKeychainFromMnemonic(mnemonic, "", 0)
+	require.NoError(t, err)
+
+	// the keychain derives the same keys as the full path from the master key
+	seed, err := iotago.SeedFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+	key, err := iotago.NewSlip10MasterKey(seed).Derive(iotago.IOTABIP32Path(0, true, 3))
+	require.NoError(t, err)
+	prvKey, err := keychain.PrivateKey(true, 3)
+	require.NoError(t, err)
+	require.Equal(t, key.PrivateKey(), prvKey)
+
+	addr, err := keychain.Address(false, 0)
+	require.NoError(t, err)
+	msg := tpkg.RandBytes(32)
+	sig, err := keychain.Sign(addr, msg)
+	require.NoError(t, err)
+	edSig := sig.(*iotago.Ed25519Signature)
+	require.NoError(t, edSig.Valid(msg, addr))
+
+	t.Run("ok - addresses not derived before are found within the lookahead", func(t *testing.T) {
+		otherKeychain, err := iotago.NewHDKeychainFromMnemonic(mnemonic, "", 0, iotago.WithHDKeychainLookahead(5))
+		require.NoError(t, err)
+		changeAddr, err := keychain.Address(true, 4)
+		require.NoError(t, err)
+		sig, err := otherKeychain.Sign(changeAddr, msg)
+		require.NoError(t, err)
+		require.NoError(t, sig.(*iotago.Ed25519Signature).Valid(msg, changeAddr))
+	})
+
+	t.Run("err - address of another account", func(t *testing.T) {
+		otherAccount, err := iotago.NewHDKeychainFromMnemonic(mnemonic, "", 1)
+		require.NoError(t, err)
+		otherAddr, err := otherAccount.Address(false, 0)
+		require.NoError(t, err)
+		require.NotEqual(t, addr, otherAddr)
+
+		_, err = keychain.Sign(otherAddr, msg)
+		require.True(t, errors.Is(err, iotago.ErrAddressKeysNotMapped))
+	})
+
+	t.Run("ok - signs transactions", func(t *testing.T) {
+		outputAddr, _ := tpkg.RandEd25519Address()
+		changeAddr, err := keychain.Address(true, 0)
+		require.NoError(t, err)
+
+		_, err = iotago.NewTransactionBuilder().
+			AddInput(&iotago.ToBeSignedUTXOInput{Address: addr, Input: &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray()}}).
+			AddInput(&iotago.ToBeSignedUTXOInput{Address: changeAddr, Input: &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray()}}).
+			AddOutput(&iotago.SigLockedSingleOutput{Address: outputAddr, Amount: 50}).
+			Build(keychain)
+		require.NoError(t, err)
+	})
+}
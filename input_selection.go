@@ -0,0 +1,175 @@
+package iotago
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	// BranchAndBoundMaxTries defines the maximum amount of combinations BranchAndBoundInputSelection
+	// explores before falling back to LargestFirstInputSelection.
+	BranchAndBoundMaxTries = 100_000
+)
+
+var (
+	// ErrInsufficientFunds gets returned when the available inputs can not fund the wanted outputs.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrInputSelectionMaxInputsExceeded gets returned when the wanted outputs can only be funded by using more than the allowed amount of inputs.
+	ErrInputSelectionMaxInputsExceeded = fmt.Errorf("input selection needs more than the max. %d allowed inputs", MaxInputsCount)
+)
+
+// NewInputCandidate creates a new InputCandidate from the given unspent output.
+func NewInputCandidate(addr Address, input *UTXOInput, output Output) (*InputCandidate, error) {
+	deposit, err := output.Deposit()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get deposit of input candidate %s: %w", input.ID().ToHex(), err)
+	}
+	return &InputCandidate{Address: addr, Input: input, Output: output, deposit: deposit}, nil
+}
+
+// InputCandidate is an unspent output which can be selected to be used as an input of a transaction.
+// Use NewInputCandidate to create an InputCandidate.
+type InputCandidate struct {
+	// The address to which the output belongs to.
+	Address Address
+	// The UTXO input referencing the output.
+	Input *UTXOInput
+	// The actual unspent output.
+	Output Output
+	// The deposit of the output.
+	deposit uint64
+}
+
+// Deposit returns the deposit of the candidate's output.
+func (c *InputCandidate) Deposit() uint64 {
+	return c.deposit
+}
+
+// InputCandidates is a slice of InputCandidate.
+type InputCandidates []*InputCandidate
+
+// Sum returns the sum of deposits of the candidates.
+func (ic InputCandidates) Sum() uint64 {
+	var sum uint64
+	for _, c := range ic {
+		sum += c.deposit
+	}
+	return sum
+}
+
+// InputSelectionFunc selects a subset of the given candidates, using at most maxInputs, whose deposit sum is a valid
+// funding for the given target amount (see IsValidInputSelectionSum).
+type InputSelectionFunc func(candidates InputCandidates, target uint64, maxInputs int) (InputCandidates, error)
+
+// IsValidInputSelectionSum tells whether the given input sum can fund the given target amount
+// without producing a remainder which would result in a dust output:
+// the sum must either exactly match the target or leave a remainder of at least OutputSigLockedDustAllowanceOutputMinDeposit.
+func IsValidInputSelectionSum(sum uint64, target uint64) bool {
+	return sum == target || (sum > target && sum-target >= OutputSigLockedDustAllowanceOutputMinDeposit)
+}
+
+// checks whether the given candidates can fund the target at all.
+func checkInputSelectionFundable(candidates InputCandidates, target uint64) error {
+	available := candidates.Sum()
+	if available < target {
+		return fmt.Errorf("%w: needed %d but only %d available", ErrInsufficientFunds, target, available)
+	}
+	return nil
+}
+
+// selects candidates in the given order until the sum of them funds the target.
+func accumulateInputSelection(sorted InputCandidates, target uint64, maxInputs int) (InputCandidates, error) {
+	if err := checkInputSelectionFundable(sorted, target); err != nil {
+		return nil, err
+	}
+
+	var sum uint64
+	for i, c := range sorted {
+		sum += c.deposit
+		if !IsValidInputSelectionSum(sum, target) {
+			continue
+		}
+		if i+1 > maxInputs {
+			return nil, fmt.Errorf("%w: needed %d inputs to fund %d, max %d", ErrInputSelectionMaxInputsExceeded, i+1, target, maxInputs)
+		}
+		return sorted[:i+1], nil
+	}
+
+	return nil, fmt.Errorf("%w: needed %d but the available %d would leave a dust remainder", ErrInsufficientFunds, target, sum)
+}
+
+// LargestFirstInputSelection is an InputSelectionFunc which selects the candidates with the biggest deposits first.
+// It minimizes the amount of inputs used.
+func LargestFirstInputSelection(candidates InputCandidates, target uint64, maxInputs int) (InputCandidates, error) {
+	sorted := make(InputCandidates, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].deposit > sorted[j].deposit
+	})
+	return accumulateInputSelection(sorted, target, maxInputs)
+}
+
+// SmallestFirstInputSelection is an InputSelectionFunc which selects the candidates with the smallest deposits first.
+// It consolidates many small outputs but might exceed maxInputs where LargestFirstInputSelection would not.
+func SmallestFirstInputSelection(candidates InputCandidates, target uint64, maxInputs int) (InputCandidates, error) {
+	sorted := make(InputCandidates, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].deposit < sorted[j].deposit
+	})
+	return accumulateInputSelection(sorted, target, maxInputs)
+}
+
+// BranchAndBoundInputSelection is an InputSelectionFunc which searches for a combination of candidates
+// exactly matching the target, so that no change output is needed. If no such combination is found within
+// BranchAndBoundMaxTries, it falls back to LargestFirstInputSelection.
+func BranchAndBoundInputSelection(candidates InputCandidates, target uint64, maxInputs int) (InputCandidates, error) {
+	if err := checkInputSelectionFundable(candidates, target); err != nil {
+		return nil, err
+	}
+
+	sorted := make(InputCandidates, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].deposit > sorted[j].deposit
+	})
+
+	// remaining[i] holds the sum of all deposits from index i onwards
+	remaining := make([]uint64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + sorted[i].deposit
+	}
+
+	var tries int
+	selection := make(InputCandidates, 0, maxInputs)
+	var search func(index int, sum uint64) bool
+	search = func(index int, sum uint64) bool {
+		tries++
+		switch {
+		case sum == target:
+			return true
+		case sum > target, index == len(sorted), len(selection) == maxInputs, tries > BranchAndBoundMaxTries:
+			return false
+		case sum+remaining[index] < target:
+			// can't reach the target anymore with the remaining candidates
+			return false
+		}
+
+		// branch including the candidate
+		selection = append(selection, sorted[index])
+		if search(index+1, sum+sorted[index].deposit) {
+			return true
+		}
+		selection = selection[:len(selection)-1]
+
+		// branch omitting the candidate
+		return search(index+1, sum)
+	}
+
+	if search(0, 0) {
+		return selection, nil
+	}
+
+	return accumulateInputSelection(sorted, target, maxInputs)
+}
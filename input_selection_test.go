@@ -0,0 +1,124 @@
+package iotago_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randInputCandidates(t *testing.T, deposits ...uint64) iotago.InputCandidates {
+	addr, _ := tpkg.RandEd25519Address()
+	candidates := make(iotago.InputCandidates, len(deposits))
+	for i, deposit := range deposits {
+		utxoInput := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray(), TransactionOutputIndex: 0}
+		candidate, err := iotago.NewInputCandidate(addr, utxoInput, &iotago.SigLockedSingleOutput{Address: addr, Amount: deposit})
+		require.NoError(t, err)
+		candidates[i] = candidate
+	}
+	return candidates
+}
+
+func candidateDeposits(candidates iotago.InputCandidates) []uint64 {
+	deposits := make([]uint64, len(candidates))
+	for i, c := range candidates {
+		deposits[i] = c.Deposit()
+	}
+	return deposits
+}
+
+func TestInputSelection(t *testing.T) {
+	const mi = iotago.OutputSigLockedDustAllowanceOutputMinDeposit
+
+	type test struct {
+		name          string
+		selectionFunc iotago.InputSelectionFunc
+		deposits      []uint64
+		target        uint64
+		maxInputs     int
+		expected      []uint64
+		expectedErr   error
+	}
+
+	tests := []test{
+		{
+			name:          "ok - largest first",
+			selectionFunc: iotago.LargestFirstInputSelection,
+			deposits:      []uint64{1 * mi, 5 * mi, 3 * mi},
+			target:        6 * mi,
+			maxInputs:     iotago.MaxInputsCount,
+			expected:      []uint64{5 * mi, 3 * mi},
+		},
+		{
+			name:          "ok - smallest first",
+			selectionFunc: iotago.SmallestFirstInputSelection,
+			deposits:      []uint64{1 * mi, 5 * mi, 3 * mi},
+			target:        4 * mi,
+			maxInputs:     iotago.MaxInputsCount,
+			expected:      []uint64{1 * mi, 3 * mi},
+		},
+		{
+			name:          "ok - largest first skips dust remainder",
+			selectionFunc: iotago.LargestFirstInputSelection,
+			deposits:      []uint64{5 * mi, 2 * mi, 1 * mi},
+			target:        5*mi - 10,
+			maxInputs:     iotago.MaxInputsCount,
+			expected:      []uint64{5 * mi, 2 * mi},
+		},
+		{
+			name:          "ok - branch and bound exact match",
+			selectionFunc: iotago.BranchAndBoundInputSelection,
+			deposits:      []uint64{10 * mi, 4 * mi, 3 * mi, 2 * mi},
+			target:        5 * mi,
+			maxInputs:     iotago.MaxInputsCount,
+			expected:      []uint64{3 * mi, 2 * mi},
+		},
+		{
+			name:          "ok - branch and bound falls back to largest first",
+			selectionFunc: iotago.BranchAndBoundInputSelection,
+			deposits:      []uint64{10 * mi, 4 * mi},
+			target:        5 * mi,
+			maxInputs:     iotago.MaxInputsCount,
+			expected:      []uint64{10 * mi},
+		},
+		{
+			name:          "err - insufficient funds",
+			selectionFunc: iotago.LargestFirstInputSelection,
+			deposits:      []uint64{1 * mi, 2 * mi},
+			target:        4 * mi,
+			maxInputs:     iotago.MaxInputsCount,
+			expectedErr:   iotago.ErrInsufficientFunds,
+		},
+		{
+			name:          "err - only dust remainder possible",
+			selectionFunc: iotago.LargestFirstInputSelection,
+			deposits:      []uint64{1 * mi, 2 * mi},
+			target:        3*mi - 1,
+			maxInputs:     iotago.MaxInputsCount,
+			expectedErr:   iotago.ErrInsufficientFunds,
+		},
+		{
+			name:          "err - max inputs exceeded",
+			selectionFunc: iotago.SmallestFirstInputSelection,
+			deposits:      []uint64{1 * mi, 1 * mi, 1 * mi},
+			target:        3 * mi,
+			maxInputs:     2,
+			expectedErr:   iotago.ErrInputSelectionMaxInputsExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := test.selectionFunc(randInputCandidates(t, test.deposits...), test.target, test.maxInputs)
+			if test.expectedErr != nil {
+				assert.True(t, errors.Is(err, test.expectedErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.expected, candidateDeposits(selected))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+// Output is an output which is tracked by the Ledger.
+type Output struct {
+	// The ID of the output.
+	ID iotago.UTXOInputID
+	// The ID of the message which created the output.
+	MessageID iotago.MessageID
+	// The actual output.
+	Output iotago.Output
+}
+
+// Address returns the address onto which the output deposits.
+func (o *Output) Address() iotago.Address {
+	target, err := o.Output.Target()
+	if err != nil {
+		return nil
+	}
+	addr, _ := target.(iotago.Address)
+	return addr
+}
+
+// Deposit returns the deposit of the output.
+func (o *Output) Deposit() uint64 {
+	deposit, _ := o.Output.Deposit()
+	return deposit
+}
+
+// Spent is an Output which was consumed by a transaction.
+type Spent struct {
+	// The consumed output.
+	Output *Output
+	// The ID of the transaction which consumed the output.
+	TransactionID iotago.TransactionID
+	// The ID of the message which contained the consuming transaction.
+	MessageID iotago.MessageID
+}
+
+// Treasury is the state of the treasury within the Ledger.
+type Treasury struct {
+	// The ID of the milestone which generated the treasury output.
+	MilestoneID iotago.MilestoneID
+	// The amount of funds residing in the treasury.
+	Amount uint64
+}
+
+// Diff describes the changes of one atomic mutation applied to the Ledger.
+type Diff struct {
+	// The ledger index before the mutation.
+	PreviousIndex uint32
+	// The ledger index after the mutation.
+	Index uint32
+	// The outputs created by the mutation.
+	Created []*Output
+	// The outputs consumed by the mutation.
+	Consumed []*Spent
+	// The treasury before the mutation, nil if the mutation did not change the treasury.
+	PreviousTreasury *Treasury
+	// The treasury after the mutation, nil if the mutation did not change the treasury.
+	Treasury *Treasury
+	// Whether the mutation seeded the Ledger, i.e. via AddOutputs or SetTreasury.
+	Seed bool
+}
+
+// merges the given diff into this diff.
+func (d *Diff) merge(other *Diff) {
+	d.Index = other.Index
+	d.Created = append(d.Created, other.Created...)
+	d.Consumed = append(d.Consumed, other.Consumed...)
+	if other.Treasury != nil {
+		if d.Treasury == nil {
+			d.PreviousTreasury = other.PreviousTreasury
+		}
+		d.Treasury = other.Treasury
+	}
+}
+
+func (o *Output) Deserialize(data []byte, deSeriMode serializer.DeSerializationMode) (int, error) {
+	var outputID []byte
+	return serializer.NewDeserializer(data).
+		ReadBytes(&outputID, iotago.TransactionIDLength+serializer.UInt16ByteSize, func(err error) error {
+			return fmt.Errorf("unable to deserialize ID for ledger output: %w", err)
+		}).
+		Do(func() {
+			copy(o.ID[:], outputID)
+		}).
+		ReadArrayOf32Bytes(&o.MessageID, func(err error) error {
+			return fmt.Errorf("unable to deserialize message ID for ledger output: %w", err)
+		}).
+		ReadObject(func(seri serializer.Serializable) { o.Output = seri.(iotago.Output) }, deSeriMode, serializer.TypeDenotationByte, iotago.OutputSelector, func(err error) error {
+			return fmt.Errorf("unable to deserialize output for ledger output: %w", err)
+		}).
+		Done()
+}
+
+func (o *Output) Serialize(deSeriMode serializer.DeSerializationMode) ([]byte, error) {
+	return serializer.NewSerializer().
+		WriteBytes(o.ID[:], func(err error) error {
+			return fmt.Errorf("unable to serialize ledger output ID: %w", err)
+		}).
+		WriteBytes(o.MessageID[:], func(err error) error {
+			return fmt.Errorf("unable to serialize ledger output message ID: %w", err)
+		}).
+		WriteObject(o.Output, deSeriMode, func(err error) error {
+			return fmt.Errorf("unable to serialize ledger output output: %w", err)
+		}).
+		Serialize()
+}
+
+func (s *Spent) Deserialize(data []byte, deSeriMode serializer.DeSerializationMode) (int, error) {
+	s.Output = &Output{}
+	outputBytesRead, err := s.Output.Deserialize(data, deSeriMode)
+	if err != nil {
+		return 0, fmt.Errorf("unable to deserialize output for spent: %w", err)
+	}
+
+	bytesRead, err := serializer.NewDeserializer(data[outputBytesRead:]).
+		ReadArrayOf32Bytes(&s.TransactionID, func(err error) error {
+			return fmt.Errorf("unable to deserialize transaction ID for spent: %w", err)
+		}).
+		ReadArrayOf32Bytes(&s.MessageID, func(err error) error {
+			return fmt.Errorf("unable to deserialize message ID for spent: %w", err)
+		}).
+		Done()
+	return outputBytesRead + bytesRead, err
+}
+
+func (s *Spent) Serialize(deSeriMode serializer.DeSerializationMode) ([]byte, error) {
+	outputBytes, err := s.Output.Serialize(deSeriMode)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize spent output: %w", err)
+	}
+
+	return serializer.NewSerializer().
+		WriteBytes(outputBytes, func(err error) error {
+			return fmt.Errorf("unable to serialize spent output: %w", err)
+		}).
+		WriteBytes(s.TransactionID[:], func(err error) error {
+			return fmt.Errorf("unable to serialize spent transaction ID: %w", err)
+		}).
+		WriteBytes(s.MessageID[:], func(err error) error {
+			return fmt.Errorf("unable to serialize spent message ID: %w", err)
+		}).
+		Serialize()
+}
+
+func (t *Treasury) Deserialize(data []byte, deSeriMode serializer.DeSerializationMode) (int, error) {
+	return serializer.NewDeserializer(data).
+		ReadArrayOf32Bytes(&t.MilestoneID, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone ID for treasury: %w", err)
+		}).
+		ReadNum(&t.Amount, func(err error) error {
+			return fmt.Errorf("unable to deserialize amount for treasury: %w", err)
+		}).
+		Done()
+}
+
+func (t *Treasury) Serialize(deSeriMode serializer.DeSerializationMode) ([]byte, error) {
+	return serializer.NewSerializer().
+		WriteBytes(t.MilestoneID[:], func(err error) error {
+			return fmt.Errorf("unable to serialize treasury milestone ID: %w", err)
+		}).
+		WriteNum(t.Amount, func(err error) error {
+			return fmt.Errorf("unable to serialize treasury amount: %w", err)
+		}).
+		Serialize()
+}
@@ -0,0 +1,546 @@
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+const (
+	// an unspent output, payload is a serialized Output.
+	logRecordOutput byte = iota
+	// the removal of an unspent output, payload is the UTXOInputID.
+	logRecordOutputRemoved
+	// a spent output, payload is a serialized Spent.
+	logRecordSpent
+	// the removal of a spent output, payload is the UTXOInputID.
+	logRecordSpentRemoved
+	// the treasury, payload is a serialized Treasury or empty if there is no treasury.
+	logRecordTreasury
+	// the end of an atomic batch of records, payload is the ledger index.
+	logRecordCommit
+
+	// the size of the kind and length prefix of a log record.
+	logRecordHeaderSize = serializer.OneByte + serializer.UInt32ByteSize
+)
+
+var (
+	// ErrFileStorageCorrupted gets returned when the log of a FileStorage contains invalid records.
+	ErrFileStorageCorrupted = errors.New("file storage log corrupted")
+	// ErrFileStorageClosed gets returned when a closed FileStorage is used.
+	ErrFileStorageClosed = errors.New("file storage closed")
+	// ErrFileStorageInUse gets returned when a snapshot is imported into a FileStorage whose state was already loaded,
+	// i.e. by a Ledger opened on it, whose in-memory state would go stale.
+	ErrFileStorageInUse = errors.New("file storage state already loaded")
+)
+
+// FileStorageOption is a function setting a FileStorage option.
+type FileStorageOption func(opts *FileStorageOptions)
+
+// FileStorageOptions define options for the FileStorage.
+type FileStorageOptions struct {
+	// Whether to fsync the log after every batch.
+	sync bool
+}
+
+// applies the given FileStorageOption.
+func (fso *FileStorageOptions) apply(opts ...FileStorageOption) {
+	for _, opt := range opts {
+		opt(fso)
+	}
+}
+
+// WithFileStorageSync defines whether the log is synced to disk after every committed batch.
+func WithFileStorageSync(sync bool) FileStorageOption {
+	return func(opts *FileStorageOptions) {
+		opts.sync = sync
+	}
+}
+
+// the default options applied to the FileStorage.
+var defaultFileStorageOptions = []FileStorageOption{
+	WithFileStorageSync(true),
+}
+
+// the position of a record's payload within the log.
+type logPosition struct {
+	offset int64
+	length uint32
+}
+
+// a record which is about to be appended to the log.
+type logRecord struct {
+	kind    byte
+	payload []byte
+}
+
+// NewFileStorage opens or creates the FileStorage with its log at the given path.
+// The log is replayed to build the index; an incomplete batch at the end of the log,
+// i.e. caused by a crash during a write, is discarded.
+func NewFileStorage(path string, opts ...FileStorageOption) (*FileStorage, error) {
+	options := &FileStorageOptions{}
+	options.apply(defaultFileStorageOptions...)
+	options.apply(opts...)
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file storage log: %w", err)
+	}
+
+	s := &FileStorage{opts: options, path: path, file: file}
+	s.reset()
+	if err := s.replay(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// FileStorage is a Storage which persists the Ledger in an append-only log file.
+// An in-memory index maps the IDs of unspent and spent outputs to the position of their records within the log.
+// Records are appended in batches which only take effect once their commit record has been written.
+type FileStorage struct {
+	mu   sync.Mutex
+	opts *FileStorageOptions
+	path string
+	file *os.File
+	// whether the state was loaded, i.e. by a Ledger opened on the FileStorage.
+	loaded   bool
+	size     int64
+	index    uint32
+	treasury *Treasury
+	unspent  map[iotago.UTXOInputID]logPosition
+	spent    map[iotago.UTXOInputID]logPosition
+}
+
+// resets the index of the FileStorage.
+func (s *FileStorage) reset() {
+	s.size = 0
+	s.index = 0
+	s.treasury = nil
+	s.unspent = make(map[iotago.UTXOInputID]logPosition)
+	s.spent = make(map[iotago.UTXOInputID]logPosition)
+}
+
+// replays the log to build the index and truncates an incomplete batch at the end of the log.
+func (s *FileStorage) replay() error {
+	reader := bufio.NewReader(s.file)
+
+	type pendingRecord struct {
+		kind byte
+		pos  logPosition
+		data []byte
+	}
+
+	var pending []pendingRecord
+	var offset int64
+	for {
+		kind, err := reader.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("unable to replay file storage log: %w", err)
+		}
+
+		data, err := readRecord(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				// incomplete record at the end of the log
+				break
+			}
+			return fmt.Errorf("%w: record at offset %d: %s", ErrFileStorageCorrupted, offset, err)
+		}
+
+		pos := logPosition{offset: offset + logRecordHeaderSize, length: uint32(len(data))}
+		offset += logRecordHeaderSize + int64(len(data))
+		pending = append(pending, pendingRecord{kind: kind, pos: pos, data: data})
+
+		if kind != logRecordCommit {
+			continue
+		}
+
+		for _, record := range pending {
+			if err := s.indexRecord(record.kind, record.pos, record.data); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		s.size = offset
+	}
+
+	stat, err := s.file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat file storage log: %w", err)
+	}
+	if stat.Size() > s.size {
+		if err := s.file.Truncate(s.size); err != nil {
+			return fmt.Errorf("unable to truncate incomplete batch of file storage log: %w", err)
+		}
+	}
+	return nil
+}
+
+// applies the given record to the index.
+func (s *FileStorage) indexRecord(kind byte, pos logPosition, data []byte) error {
+	readID := func() (iotago.UTXOInputID, error) {
+		var id iotago.UTXOInputID
+		if len(data) < len(id) {
+			return id, fmt.Errorf("%w: record at offset %d too short", ErrFileStorageCorrupted, pos.offset)
+		}
+		copy(id[:], data)
+		return id, nil
+	}
+
+	switch kind {
+	case logRecordOutput:
+		id, err := readID()
+		if err != nil {
+			return err
+		}
+		s.unspent[id] = pos
+	case logRecordOutputRemoved:
+		id, err := readID()
+		if err != nil {
+			return err
+		}
+		delete(s.unspent, id)
+	case logRecordSpent:
+		// a Spent starts with its Output which starts with the UTXOInputID
+		id, err := readID()
+		if err != nil {
+			return err
+		}
+		delete(s.unspent, id)
+		s.spent[id] = pos
+	case logRecordSpentRemoved:
+		id, err := readID()
+		if err != nil {
+			return err
+		}
+		delete(s.spent, id)
+	case logRecordTreasury:
+		if len(data) == 0 {
+			s.treasury = nil
+			return nil
+		}
+		treasury := &Treasury{}
+		if _, err := treasury.Deserialize(data, serializer.DeSeriModePerformValidation); err != nil {
+			return fmt.Errorf("%w: %s", ErrFileStorageCorrupted, err)
+		}
+		s.treasury = treasury
+	case logRecordCommit:
+		if len(data) != serializer.UInt32ByteSize {
+			return fmt.Errorf("%w: invalid commit record at offset %d", ErrFileStorageCorrupted, pos.offset)
+		}
+		s.index = binary.LittleEndian.Uint32(data)
+	default:
+		return fmt.Errorf("%w: unknown record kind %d at offset %d", ErrFileStorageCorrupted, kind, pos.offset)
+	}
+	return nil
+}
+
+// appends the given records followed by a commit record for the given ledger index to the log.
+func (s *FileStorage) appendBatch(records []logRecord, index uint32) error {
+	if s.file == nil {
+		return ErrFileStorageClosed
+	}
+
+	records, buf, positions, err := encodeBatch(records, index, s.size)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.file.WriteAt(buf.Bytes(), s.size); err != nil {
+		// drop whatever made it into the log
+		_ = s.file.Truncate(s.size)
+		return fmt.Errorf("unable to append to file storage log: %w", err)
+	}
+	if s.opts.sync {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("unable to sync file storage log: %w", err)
+		}
+	}
+
+	for i, record := range records {
+		if err := s.indexRecord(record.kind, positions[i], record.payload); err != nil {
+			return err
+		}
+	}
+	s.size += int64(buf.Len())
+	return nil
+}
+
+// encodes the given records followed by a commit record for the given ledger index as they are laid out
+// in the log starting at the given offset. Returns the records including the commit record and their positions.
+func encodeBatch(records []logRecord, index uint32, offset int64) ([]logRecord, *bytes.Buffer, []logPosition, error) {
+	commitPayload := make([]byte, serializer.UInt32ByteSize)
+	binary.LittleEndian.PutUint32(commitPayload, index)
+	records = append(records, logRecord{kind: logRecordCommit, payload: commitPayload})
+
+	var buf bytes.Buffer
+	positions := make([]logPosition, len(records))
+	for i, record := range records {
+		positions[i] = logPosition{offset: offset + int64(buf.Len()) + logRecordHeaderSize, length: uint32(len(record.payload))}
+		buf.WriteByte(record.kind)
+		if err := writeRecord(&buf, record.payload); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return records, &buf, positions, nil
+}
+
+// reads the payload at the given position.
+func (s *FileStorage) read(pos logPosition) ([]byte, error) {
+	data := make([]byte, pos.length)
+	if _, err := s.file.ReadAt(data, pos.offset); err != nil {
+		return nil, fmt.Errorf("unable to read file storage log at offset %d: %w", pos.offset, err)
+	}
+	return data, nil
+}
+
+// loads all unspent outputs.
+func (s *FileStorage) unspentOutputs() ([]*Output, error) {
+	outputs := make([]*Output, 0, len(s.unspent))
+	for _, pos := range s.unspent {
+		data, err := s.read(pos)
+		if err != nil {
+			return nil, err
+		}
+		output := &Output{}
+		if _, err := output.Deserialize(data, serializer.DeSeriModePerformValidation); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFileStorageCorrupted, err)
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+func (s *FileStorage) Load() (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil, ErrFileStorageClosed
+	}
+
+	unspent, err := s.unspentOutputs()
+	if err != nil {
+		return nil, err
+	}
+
+	s.loaded = true
+
+	spent := make([]*Spent, 0, len(s.spent))
+	for _, pos := range s.spent {
+		data, err := s.read(pos)
+		if err != nil {
+			return nil, err
+		}
+		sp := &Spent{}
+		if _, err := sp.Deserialize(data, serializer.DeSeriModePerformValidation); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFileStorageCorrupted, err)
+		}
+		spent = append(spent, sp)
+	}
+
+	return &State{Index: s.index, Treasury: s.treasury, Unspent: unspent, Spent: spent}, nil
+}
+
+func (s *FileStorage) Commit(diff *Diff) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]logRecord, 0, len(diff.Created)+len(diff.Consumed)+1)
+	for _, output := range diff.Created {
+		record, err := outputRecord(output)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+	for _, spent := range diff.Consumed {
+		spentBytes, err := spent.Serialize(serializer.DeSeriModePerformValidation)
+		if err != nil {
+			return err
+		}
+		records = append(records, logRecord{kind: logRecordSpent, payload: spentBytes})
+	}
+	if diff.Treasury != nil {
+		record, err := treasuryRecord(diff.Treasury)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	return s.appendBatch(records, diff.Index)
+}
+
+func (s *FileStorage) Revert(diff *Diff) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]logRecord, 0, 2*len(diff.Consumed)+len(diff.Created)+1)
+	for i := len(diff.Consumed) - 1; i >= 0; i-- {
+		spent := diff.Consumed[i]
+		record, err := outputRecord(spent.Output)
+		if err != nil {
+			return err
+		}
+		records = append(records, logRecord{kind: logRecordSpentRemoved, payload: spent.Output.ID[:]}, record)
+	}
+	for i := len(diff.Created) - 1; i >= 0; i-- {
+		records = append(records, logRecord{kind: logRecordOutputRemoved, payload: diff.Created[i].ID[:]})
+	}
+	if diff.Treasury != nil {
+		record, err := treasuryRecord(diff.PreviousTreasury)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	return s.appendBatch(records, diff.PreviousIndex)
+}
+
+func (s *FileStorage) ExportSnapshot(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrFileStorageClosed
+	}
+
+	outputs, err := s.unspentOutputs()
+	if err != nil {
+		return err
+	}
+	return WriteSnapshot(w, &Snapshot{Index: s.index, Treasury: s.treasury, Outputs: outputs})
+}
+
+// ImportSnapshot replaces the log with one holding the state of the Snapshot read from the given reader.
+// The new log is written to a temporary file which atomically replaces the log once it is complete,
+// so that the previous state is kept if the import fails.
+// Fails with ErrFileStorageInUse once the state was loaded, i.e. by Open, as the Ledger wouldn't reflect the import.
+func (s *FileStorage) ImportSnapshot(r io.Reader) error {
+	snapshot, err := ReadSnapshot(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrFileStorageClosed
+	}
+	if s.loaded {
+		return ErrFileStorageInUse
+	}
+
+	records := make([]logRecord, 0, len(snapshot.Outputs)+1)
+	for _, output := range snapshot.Outputs {
+		record, err := outputRecord(output)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+	record, err := treasuryRecord(snapshot.Treasury)
+	if err != nil {
+		return err
+	}
+	records = append(records, record)
+
+	_, buf, _, err := encodeBatch(records, snapshot.Index, 0)
+	if err != nil {
+		return err
+	}
+	if err := replaceFile(s.path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to reopen file storage log: %w", err)
+	}
+	_ = s.file.Close()
+	s.file = file
+	s.reset()
+	return s.replay()
+}
+
+// atomically replaces the file at the given path with one holding the given data.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".import-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file storage log: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write temporary file storage log: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to sync temporary file storage log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close temporary file storage log: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("unable to replace file storage log: %w", err)
+	}
+
+	// persist the rename itself
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+	return nil
+}
+
+func (s *FileStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrFileStorageClosed
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// creates the log record for the given unspent output.
+func outputRecord(output *Output) (logRecord, error) {
+	outputBytes, err := output.Serialize(serializer.DeSeriModePerformValidation)
+	if err != nil {
+		return logRecord{}, err
+	}
+	return logRecord{kind: logRecordOutput, payload: outputBytes}, nil
+}
+
+// creates the log record for the given treasury which might be nil.
+func treasuryRecord(treasury *Treasury) (logRecord, error) {
+	if treasury == nil {
+		return logRecord{kind: logRecordTreasury}, nil
+	}
+	treasuryBytes, err := treasury.Serialize(serializer.DeSeriModePerformValidation)
+	if err != nil {
+		return logRecord{}, err
+	}
+	return logRecord{kind: logRecordTreasury, payload: treasuryBytes}, nil
+}
@@ -0,0 +1,123 @@
+package ledger_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ledger"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+func TestFileStorage(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+	logPath := filepath.Join(t.TempDir(), "ledger.log")
+
+	storage, err := ledger.NewFileStorage(logPath)
+	require.NoError(t, err)
+
+	l, err := ledger.Open(storage)
+	require.NoError(t, err)
+
+	genesis := genesisOutput(alice.addr, 10*mi)
+	_, err = l.AddOutputs(genesis)
+	require.NoError(t, err)
+	_, err = l.SetTreasury(&ledger.Treasury{MilestoneID: tpkg.Rand32ByteArray(), Amount: 100 * mi})
+	require.NoError(t, err)
+
+	msg := transferMessage(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 4 * mi},
+		&iotago.SigLockedSingleOutput{Address: alice.addr, Amount: 6 * mi},
+	)
+	_, err = l.ApplyMessage(msg)
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	// reopen and check that the state survived
+	storage, err = ledger.NewFileStorage(logPath)
+	require.NoError(t, err)
+	reopened, err := ledger.Open(storage)
+	require.NoError(t, err)
+	require.EqualValues(t, 6*mi, reopened.Balance(alice.addr))
+	require.EqualValues(t, 4*mi, reopened.Balance(bob.addr))
+	require.True(t, reopened.IsSpent(genesis.ID))
+	require.Equal(t, l.Treasury(), reopened.Treasury())
+
+	// a torn write at the end of the log is discarded
+	require.NoError(t, storage.Close())
+	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = logFile.Write([]byte{0, 100, 0, 0, 0, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, logFile.Close())
+
+	storage, err = ledger.NewFileStorage(logPath)
+	require.NoError(t, err)
+	reopened, err = ledger.Open(storage)
+	require.NoError(t, err)
+	require.EqualValues(t, 6*mi, reopened.Balance(alice.addr))
+
+	// rollbacks are persisted too
+	_, err = reopened.AddOutputs(genesisOutput(bob.addr, 1*mi))
+	require.NoError(t, err)
+	require.EqualValues(t, 5*mi, reopened.Balance(bob.addr))
+	_, err = reopened.Rollback()
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	storage, err = ledger.NewFileStorage(logPath)
+	require.NoError(t, err)
+	defer storage.Close()
+	reopened, err = ledger.Open(storage)
+	require.NoError(t, err)
+	require.EqualValues(t, 4*mi, reopened.Balance(bob.addr))
+}
+
+func TestFileStorage_Snapshot(t *testing.T) {
+	alice := randIdentity()
+
+	l := ledger.New()
+	_, err := l.AddOutputs(genesisOutput(alice.addr, 10*mi), genesisOutput(alice.addr, 5*mi))
+	require.NoError(t, err)
+	_, err = l.SetTreasury(&ledger.Treasury{MilestoneID: tpkg.Rand32ByteArray(), Amount: 100 * mi})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, ledger.WriteSnapshot(&buf, l.Snapshot()))
+
+	dir := t.TempDir()
+	storage, err := ledger.NewFileStorage(filepath.Join(dir, "ledger.log"))
+	require.NoError(t, err)
+	defer storage.Close()
+
+	// the import replaces the previous state of the log
+	require.NoError(t, storage.Commit(&ledger.Diff{Index: 1, Created: []*ledger.Output{genesisOutput(randIdentity().addr, 1*mi)}}))
+	require.NoError(t, storage.ImportSnapshot(bytes.NewReader(buf.Bytes())))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	imported, err := ledger.Open(storage)
+	require.NoError(t, err)
+	require.EqualValues(t, 15*mi, imported.Balance(alice.addr))
+	require.Equal(t, l.Treasury(), imported.Treasury())
+	require.Len(t, imported.Snapshot().Outputs, 2)
+
+	// the opened Ledger would go stale
+	require.True(t, errors.Is(storage.ImportSnapshot(bytes.NewReader(buf.Bytes())), ledger.ErrFileStorageInUse))
+
+	var exported bytes.Buffer
+	require.NoError(t, storage.ExportSnapshot(&exported))
+	snapshot, err := ledger.ReadSnapshot(&exported)
+	require.NoError(t, err)
+	require.ElementsMatch(t, l.Snapshot().Outputs, snapshot.Outputs)
+
+	fromSnapshot, err := ledger.NewFromSnapshot(snapshot)
+	require.NoError(t, err)
+	require.EqualValues(t, 15*mi, fromSnapshot.Balance(alice.addr))
+}
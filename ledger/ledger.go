@@ -0,0 +1,611 @@
+// Package ledger provides an in-memory UTXO ledger which applies Transactions, Receipts and TreasuryTransactions
+// the same way a node does. It can be used for simulations, tests and offline wallets.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+var (
+	// ErrOutputNotFound gets returned when an output is not known to the Ledger.
+	ErrOutputNotFound = errors.New("output not found")
+	// ErrOutputAlreadySpent gets returned when a transaction tries to consume an already spent output.
+	// It is the same error as iotago.ErrUTXOAlreadySpent, which corresponds to iotago.ConflictInputUTXOAlreadySpent.
+	ErrOutputAlreadySpent = iotago.ErrUTXOAlreadySpent
+	// ErrOutputAlreadyExists gets returned when an output with the same ID is already part of the Ledger.
+	ErrOutputAlreadyExists = errors.New("output already exists")
+	// ErrMilestoneIndexNotAscending gets returned when a milestone is applied with an index not higher than the ledger index.
+	ErrMilestoneIndexNotAscending = errors.New("milestone index must be higher than the ledger index")
+	// ErrTreasuryMismatch gets returned when a TreasuryTransaction does not match the current treasury.
+	ErrTreasuryMismatch = errors.New("treasury transaction does not match the current treasury")
+	// ErrNothingToRollback gets returned when a rollback is requested but no diffs are left.
+	ErrNothingToRollback = errors.New("nothing to rollback")
+)
+
+// Option is a function setting a Ledger option.
+type Option func(opts *Options)
+
+// Options define options for the Ledger.
+type Options struct {
+	// The max. amount of diffs kept for rollbacks.
+	rollbackDepth int
+	// The semantic validation functions run against every transaction in addition to the dust validation.
+	semValFuncs []iotago.SemanticValidationFunc
+}
+
+// applies the given Option.
+func (lo *Options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(lo)
+	}
+}
+
+// WithRollbackDepth sets the max. amount of diffs which are kept for rollbacks. 0 means unlimited.
+func WithRollbackDepth(depth int) Option {
+	return func(opts *Options) {
+		opts.rollbackDepth = depth
+	}
+}
+
+// WithSemanticValidationFuncs sets additional semantic validation functions
+// which are run against every transaction applied to the Ledger.
+func WithSemanticValidationFuncs(semValFuncs ...iotago.SemanticValidationFunc) Option {
+	return func(opts *Options) {
+		opts.semValFuncs = semValFuncs
+	}
+}
+
+// the default options applied to the Ledger.
+var defaultOptions = []Option{
+	WithRollbackDepth(0),
+}
+
+// AddressState holds the balance and dust related state of an address.
+type AddressState struct {
+	// The sum of deposits of all unspent outputs on the address.
+	Balance uint64
+	// The sum of deposits of all unspent SigLockedDustAllowanceOutput(s) on the address.
+	DustAllowanceSum uint64
+	// The amount of unspent dust outputs on the address.
+	DustOutputs int64
+}
+
+// Open creates a new Ledger from the state persisted in the given Storage.
+// All subsequent mutations of the Ledger are committed to the Storage.
+// Diffs which were applied before the Ledger was opened can not be rolled back.
+func Open(storage Storage, opts ...Option) (*Ledger, error) {
+	state, err := storage.Load()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load ledger state: %w", err)
+	}
+
+	l := New(opts...)
+	if err := l.load(state); err != nil {
+		return nil, err
+	}
+	l.storage = storage
+	return l, nil
+}
+
+// NewFromSnapshot creates a new Ledger from the given Snapshot.
+func NewFromSnapshot(snapshot *Snapshot, opts ...Option) (*Ledger, error) {
+	l := New(opts...)
+	if err := l.load(&State{Index: snapshot.Index, Treasury: snapshot.Treasury, Unspent: snapshot.Outputs}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// New creates a new empty Ledger.
+func New(opts ...Option) *Ledger {
+	options := &Options{}
+	options.apply(defaultOptions...)
+	options.apply(opts...)
+
+	return &Ledger{
+		opts:      options,
+		unspent:   make(map[iotago.UTXOInputID]*Output),
+		spent:     make(map[iotago.UTXOInputID]*Spent),
+		addresses: make(map[string]*AddressState),
+	}
+}
+
+// Ledger is an in-memory UTXO ledger. Mutations are applied atomically: either all changes
+// of a mutation are applied or none. Every applied mutation produces a Diff which can be rolled back.
+type Ledger struct {
+	mu        sync.RWMutex
+	opts      *Options
+	index     uint32
+	unspent   map[iotago.UTXOInputID]*Output
+	spent     map[iotago.UTXOInputID]*Spent
+	treasury  *Treasury
+	addresses map[string]*AddressState
+	diffs     []*Diff
+	storage   Storage
+}
+
+// loads the given state into the empty Ledger.
+func (l *Ledger) load(state *State) error {
+	diff := &Diff{}
+	for _, output := range state.Unspent {
+		if err := l.create(diff, output); err != nil {
+			return err
+		}
+	}
+	for _, spent := range state.Spent {
+		l.spent[spent.Output.ID] = spent
+	}
+	l.index = state.Index
+	l.treasury = state.Treasury
+	return nil
+}
+
+// Snapshot returns a Snapshot of the current state of the Ledger.
+func (l *Ledger) Snapshot() *Snapshot {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	snapshot := &Snapshot{Index: l.index, Outputs: make([]*Output, 0, len(l.unspent))}
+	if l.treasury != nil {
+		treasury := *l.treasury
+		snapshot.Treasury = &treasury
+	}
+	for _, output := range l.unspent {
+		snapshot.Outputs = append(snapshot.Outputs, output)
+	}
+	return snapshot
+}
+
+// Index returns the ledger index, the index of the last applied milestone.
+func (l *Ledger) Index() uint32 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.index
+}
+
+// Treasury returns the current treasury or nil if the Ledger has no treasury.
+func (l *Ledger) Treasury() *Treasury {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.treasury == nil {
+		return nil
+	}
+	treasury := *l.treasury
+	return &treasury
+}
+
+// Output returns the unspent output with the given ID.
+func (l *Ledger) Output(id iotago.UTXOInputID) (*Output, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	output, has := l.unspent[id]
+	if !has {
+		return nil, fmt.Errorf("%w: %s", ErrOutputNotFound, id.ToHex())
+	}
+	return output, nil
+}
+
+// Spent returns the spent output with the given ID.
+func (l *Ledger) Spent(id iotago.UTXOInputID) (*Spent, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	spent, has := l.spent[id]
+	if !has {
+		return nil, fmt.Errorf("%w: %s", ErrOutputNotFound, id.ToHex())
+	}
+	return spent, nil
+}
+
+// IsSpent tells whether the output with the given ID is spent.
+func (l *Ledger) IsSpent(id iotago.UTXOInputID) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, has := l.spent[id]
+	return has
+}
+
+// UnspentOutputs returns all unspent outputs.
+func (l *Ledger) UnspentOutputs() []*Output {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	outputs := make([]*Output, 0, len(l.unspent))
+	for _, output := range l.unspent {
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+// UnspentOutputsByAddress returns all unspent outputs residing on the given address.
+func (l *Ledger) UnspentOutputsByAddress(addr iotago.Address) []*Output {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var outputs []*Output
+	for _, output := range l.unspent {
+		if outputAddr := output.Address(); outputAddr != nil && outputAddr.String() == addr.String() {
+			outputs = append(outputs, output)
+		}
+	}
+	return outputs
+}
+
+// AddressState returns the balance and dust related state of the given address.
+func (l *Ledger) AddressState(addr iotago.Address) AddressState {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	state, has := l.addresses[addr.String()]
+	if !has {
+		return AddressState{}
+	}
+	return *state
+}
+
+// Balance returns the balance of the given address.
+func (l *Ledger) Balance(addr iotago.Address) uint64 {
+	return l.AddressState(addr).Balance
+}
+
+// returns an iotago.DustAllowanceFunc operating on the current state of the Ledger.
+// The caller must hold the lock of the Ledger while the returned function is used.
+func (l *Ledger) dustAllowanceFunc() iotago.DustAllowanceFunc {
+	return func(addr iotago.Address) (uint64, int64, error) {
+		state, has := l.addresses[addr.String()]
+		if !has {
+			return 0, 0, nil
+		}
+		return state.DustAllowanceSum, state.DustOutputs, nil
+	}
+}
+
+// AddOutputs adds the given outputs to the Ledger as unspent outputs without any validation.
+// This is used to seed the Ledger, i.e. with a genesis state.
+func (l *Ledger) AddOutputs(outputs ...*Output) (*Diff, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	diff := &Diff{PreviousIndex: l.index, Index: l.index, Seed: true}
+	for _, output := range outputs {
+		if err := l.create(diff, output); err != nil {
+			l.revert(diff)
+			return nil, err
+		}
+	}
+	if err := l.commit(diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// SetTreasury sets the treasury of the Ledger without any validation.
+// This is used to seed the Ledger, i.e. with a genesis state.
+func (l *Ledger) SetTreasury(treasury *Treasury) (*Diff, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	diff := &Diff{PreviousIndex: l.index, Index: l.index, Seed: true}
+	l.setTreasury(diff, treasury)
+	if err := l.commit(diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// ApplyMessages applies the given messages atomically in the given order:
+// if any message can't be applied, none of them are.
+// Messages with a Transaction payload are semantically validated (including the dust rules) and booked.
+// Messages with a Milestone payload advance the ledger index and book the migrated funds of an embedded Receipt.
+// Messages with any other payload don't change the Ledger.
+func (l *Ledger) ApplyMessages(msgs ...*iotago.Message) (*Diff, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	diff := &Diff{PreviousIndex: l.index, Index: l.index}
+	for i, msg := range msgs {
+		msgDiff, err := l.applyMessage(msg)
+		if err != nil {
+			l.revert(diff)
+			return nil, fmt.Errorf("unable to apply message at pos %d: %w", i, err)
+		}
+		diff.merge(msgDiff)
+	}
+	if err := l.commit(diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// ApplyMessage applies the given message atomically. See ApplyMessages.
+func (l *Ledger) ApplyMessage(msg *iotago.Message) (*Diff, error) {
+	return l.ApplyMessages(msg)
+}
+
+// applies the given message, leaves the Ledger untouched if an error is returned.
+func (l *Ledger) applyMessage(msg *iotago.Message) (*Diff, error) {
+	msgID, err := msg.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	switch payload := msg.Payload.(type) {
+	case *iotago.Transaction:
+		return l.applyTransaction(*msgID, payload)
+	case *iotago.Milestone:
+		return l.applyMilestone(*msgID, payload)
+	default:
+		return &Diff{PreviousIndex: l.index, Index: l.index}, nil
+	}
+}
+
+// applies the given transaction, leaves the Ledger untouched if an error is returned.
+func (l *Ledger) applyTransaction(msgID iotago.MessageID, tx *iotago.Transaction) (*Diff, error) {
+	if err := tx.SyntacticallyValidate(); err != nil {
+		return nil, err
+	}
+
+	txID, err := tx.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	essence := tx.Essence.(*iotago.TransactionEssence)
+	utxos := make(iotago.InputToOutputMapping)
+	for i, input := range essence.Inputs {
+		utxoID := input.(*iotago.UTXOInput).ID()
+		if _, spent := l.spent[utxoID]; spent {
+			return nil, fmt.Errorf("%w: %s (input at index %d)", ErrOutputAlreadySpent, utxoID.ToHex(), i)
+		}
+		if output, has := l.unspent[utxoID]; has {
+			utxos[utxoID] = output.Output
+		}
+	}
+
+	semValFuncs := append([]iotago.SemanticValidationFunc{
+		iotago.NewDustSemanticValidation(iotago.DustAllowanceDivisor, iotago.MaxDustOutputsOnAddress, l.dustAllowanceFunc()),
+	}, l.opts.semValFuncs...)
+
+	if err := tx.SemanticallyValidate(utxos, semValFuncs...); err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{PreviousIndex: l.index, Index: l.index}
+	for _, input := range essence.Inputs {
+		l.consume(diff, input.(*iotago.UTXOInput).ID(), *txID, msgID)
+	}
+
+	for i, output := range essence.Outputs {
+		utxoInput := &iotago.UTXOInput{TransactionID: *txID, TransactionOutputIndex: uint16(i)}
+		if err := l.create(diff, &Output{ID: utxoInput.ID(), MessageID: msgID, Output: output.(iotago.Output)}); err != nil {
+			l.revert(diff)
+			return nil, err
+		}
+	}
+
+	return diff, nil
+}
+
+// applies the given milestone, leaves the Ledger untouched if an error is returned.
+func (l *Ledger) applyMilestone(msgID iotago.MessageID, ms *iotago.Milestone) (*Diff, error) {
+	if ms.Index <= l.index {
+		return nil, fmt.Errorf("%w: milestone index %d, ledger index %d", ErrMilestoneIndexNotAscending, ms.Index, l.index)
+	}
+
+	diff := &Diff{PreviousIndex: l.index, Index: ms.Index}
+	if ms.Receipt != nil {
+		msID, err := ms.ID()
+		if err != nil {
+			return nil, err
+		}
+		receiptDiff, err := l.applyReceipt(*msID, msgID, ms.Receipt.(*iotago.Receipt))
+		if err != nil {
+			return nil, err
+		}
+		diff.merge(receiptDiff)
+	}
+
+	diff.Index = ms.Index
+	l.index = ms.Index
+	return diff, nil
+}
+
+// applies the given receipt, leaves the Ledger untouched if an error is returned.
+func (l *Ledger) applyReceipt(msID iotago.MilestoneID, msgID iotago.MessageID, receipt *iotago.Receipt) (*Diff, error) {
+	treasuryTx := receipt.Treasury()
+	if treasuryTx == nil {
+		return nil, iotago.ErrReceiptMustContainATreasuryTransaction
+	}
+
+	if l.treasury == nil {
+		return nil, fmt.Errorf("%w: ledger has no treasury", ErrTreasuryMismatch)
+	}
+
+	treasuryInput := treasuryTx.Input.(*iotago.TreasuryInput)
+	if *treasuryInput != l.treasury.MilestoneID {
+		return nil, fmt.Errorf("%w: treasury input references milestone %x but treasury was generated by %x", ErrTreasuryMismatch, treasuryInput[:], l.treasury.MilestoneID[:])
+	}
+
+	treasuryOutput := treasuryTx.Output.(*iotago.TreasuryOutput)
+	if receipt.Sum() > l.treasury.Amount || l.treasury.Amount-receipt.Sum() != treasuryOutput.Amount {
+		return nil, fmt.Errorf("%w: treasury %d, migrated %d, new treasury %d", ErrTreasuryMismatch, l.treasury.Amount, receipt.Sum(), treasuryOutput.Amount)
+	}
+
+	diff := &Diff{PreviousIndex: l.index, Index: l.index}
+	for i, fund := range receipt.Funds {
+		entry := fund.(*iotago.MigratedFundsEntry)
+		// migrated funds use the milestone ID as their transaction ID
+		utxoInput := &iotago.UTXOInput{TransactionID: msID, TransactionOutputIndex: uint16(i)}
+		output := &iotago.SigLockedSingleOutput{Address: entry.Address, Amount: entry.Deposit}
+		if err := l.create(diff, &Output{ID: utxoInput.ID(), MessageID: msgID, Output: output}); err != nil {
+			l.revert(diff)
+			return nil, err
+		}
+	}
+
+	l.setTreasury(diff, &Treasury{MilestoneID: msID, Amount: treasuryOutput.Amount})
+	return diff, nil
+}
+
+// Rollback reverts the last applied Diff.
+func (l *Ledger) Rollback() (*Diff, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.diffs) == 0 {
+		return nil, ErrNothingToRollback
+	}
+	diff := l.diffs[len(l.diffs)-1]
+	if err := l.rollback(diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// RollbackTo reverts all diffs applied after the ledger reached the given index, including the ones
+// applied at the given index after the milestone with that index or after the state the Ledger was
+// loaded with. Diffs seeding the Ledger at the given index are kept.
+func (l *Ledger) RollbackTo(index uint32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.diffs) > 0 {
+		last := l.diffs[len(l.diffs)-1]
+		if last.Index < index || (last.Index == index && (last.PreviousIndex < index || last.Seed)) {
+			break
+		}
+		if err := l.rollback(last); err != nil {
+			return err
+		}
+	}
+
+	if l.index > index {
+		return fmt.Errorf("%w: can't rollback from ledger index %d to %d", ErrNothingToRollback, l.index, index)
+	}
+	return nil
+}
+
+// commits the given applied diff to the Storage and keeps it for rollbacks.
+// The diff is reverted if it can't be committed.
+func (l *Ledger) commit(diff *Diff) error {
+	if l.storage != nil {
+		if err := l.storage.Commit(diff); err != nil {
+			l.revert(diff)
+			return fmt.Errorf("unable to commit diff to storage: %w", err)
+		}
+	}
+	l.pushDiff(diff)
+	return nil
+}
+
+// reverts the given diff, which must be the last one kept for rollbacks, and removes it.
+func (l *Ledger) rollback(diff *Diff) error {
+	if l.storage != nil {
+		if err := l.storage.Revert(diff); err != nil {
+			return fmt.Errorf("unable to revert diff in storage: %w", err)
+		}
+	}
+	l.diffs = l.diffs[:len(l.diffs)-1]
+	l.revert(diff)
+	return nil
+}
+
+// keeps the given diff for rollbacks. Only the rollback history is pruned to the rollback depth,
+// the outputs consumed by pruned diffs stay spent.
+func (l *Ledger) pushDiff(diff *Diff) {
+	l.diffs = append(l.diffs, diff)
+	if l.opts.rollbackDepth > 0 && len(l.diffs) > l.opts.rollbackDepth {
+		l.diffs = l.diffs[len(l.diffs)-l.opts.rollbackDepth:]
+	}
+}
+
+// reverts the changes of the given diff.
+func (l *Ledger) revert(diff *Diff) {
+	// consumed outputs must be restored first as they might have been created within the same diff
+	for i := len(diff.Consumed) - 1; i >= 0; i-- {
+		spent := diff.Consumed[i]
+		delete(l.spent, spent.Output.ID)
+		l.unspent[spent.Output.ID] = spent.Output
+		l.updateAddressState(spent.Output, true)
+	}
+	for i := len(diff.Created) - 1; i >= 0; i-- {
+		output := diff.Created[i]
+		delete(l.unspent, output.ID)
+		l.updateAddressState(output, false)
+	}
+	if diff.Treasury != nil {
+		l.treasury = diff.PreviousTreasury
+	}
+	l.index = diff.PreviousIndex
+}
+
+// books the given output as unspent.
+func (l *Ledger) create(diff *Diff, output *Output) error {
+	if _, has := l.unspent[output.ID]; has {
+		return fmt.Errorf("%w: %s", ErrOutputAlreadyExists, output.ID.ToHex())
+	}
+	if _, has := l.spent[output.ID]; has {
+		return fmt.Errorf("%w: %s", ErrOutputAlreadyExists, output.ID.ToHex())
+	}
+	l.unspent[output.ID] = output
+	l.updateAddressState(output, true)
+	diff.Created = append(diff.Created, output)
+	return nil
+}
+
+// books the given unspent output as spent.
+func (l *Ledger) consume(diff *Diff, id iotago.UTXOInputID, txID iotago.TransactionID, msgID iotago.MessageID) {
+	output := l.unspent[id]
+	spent := &Spent{Output: output, TransactionID: txID, MessageID: msgID}
+	delete(l.unspent, id)
+	l.spent[id] = spent
+	l.updateAddressState(output, false)
+	diff.Consumed = append(diff.Consumed, spent)
+}
+
+// sets the treasury and records the change in the diff.
+func (l *Ledger) setTreasury(diff *Diff, treasury *Treasury) {
+	if diff.Treasury == nil {
+		diff.PreviousTreasury = l.treasury
+	}
+	diff.Treasury = treasury
+	l.treasury = treasury
+}
+
+// adds or removes the given output to/from the state of its address.
+func (l *Ledger) updateAddressState(output *Output, add bool) {
+	addr := output.Address()
+	if addr == nil {
+		return
+	}
+
+	state, has := l.addresses[addr.String()]
+	if !has {
+		state = &AddressState{}
+		l.addresses[addr.String()] = state
+	}
+
+	deposit := output.Deposit()
+	var dustOutputs int64
+	var dustAllowance uint64
+	switch {
+	case output.Output.Type() == iotago.OutputSigLockedDustAllowanceOutput:
+		dustAllowance = deposit
+	case deposit < iotago.OutputSigLockedDustAllowanceOutputMinDeposit:
+		dustOutputs = 1
+	}
+
+	if add {
+		state.Balance += deposit
+		state.DustAllowanceSum += dustAllowance
+		state.DustOutputs += dustOutputs
+		return
+	}
+
+	state.Balance -= deposit
+	state.DustAllowanceSum -= dustAllowance
+	state.DustOutputs -= dustOutputs
+	if state.Balance == 0 {
+		delete(l.addresses, addr.String())
+	}
+}
@@ -0,0 +1,243 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/stretchr/testify/require"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/ledger"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+const mi = iotago.OutputSigLockedDustAllowanceOutputMinDeposit
+
+type identity struct {
+	prvKey ed25519.PrivateKey
+	addr   *iotago.Ed25519Address
+	signer iotago.AddressSigner
+}
+
+func randIdentity() *identity {
+	prvKey := tpkg.RandEd25519PrivateKey()
+	addr := iotago.AddressFromEd25519PubKey(prvKey.Public().(ed25519.PublicKey))
+	return &identity{prvKey: prvKey, addr: &addr, signer: iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(&addr, prvKey))}
+}
+
+func genesisOutput(addr iotago.Address, amount uint64) *ledger.Output {
+	utxoInput := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray()}
+	return &ledger.Output{ID: utxoInput.ID(), Output: &iotago.SigLockedSingleOutput{Address: addr, Amount: amount}}
+}
+
+func utxoInputOf(output *ledger.Output) *iotago.UTXOInput {
+	utxoInput := &iotago.UTXOInput{}
+	copy(utxoInput.TransactionID[:], output.ID[:iotago.TransactionIDLength])
+	utxoInput.TransactionOutputIndex = uint16(output.ID[iotago.TransactionIDLength]) | uint16(output.ID[iotago.TransactionIDLength+1])<<8
+	return utxoInput
+}
+
+func transferMessage(t *testing.T, from *identity, input *ledger.Output, outputs ...iotago.Output) *iotago.Message {
+	builder := iotago.NewTransactionBuilder().AddInput(&iotago.ToBeSignedUTXOInput{Address: from.addr, Input: utxoInputOf(input)})
+	for _, output := range outputs {
+		builder.AddOutput(output)
+	}
+	msg, err := builder.BuildAndSwapToMessageBuilder(from.signer, nil).
+		ParentsMessageIDs(iotago.MessageIDs{tpkg.Rand32ByteArray()}).
+		Build()
+	require.NoError(t, err)
+	return msg
+}
+
+func TestLedger_ApplyTransaction(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+
+	l := ledger.New()
+	genesis := genesisOutput(alice.addr, 10*mi)
+	_, err := l.AddOutputs(genesis)
+	require.NoError(t, err)
+	require.EqualValues(t, 10*mi, l.Balance(alice.addr))
+
+	msg := transferMessage(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 4 * mi},
+		&iotago.SigLockedSingleOutput{Address: alice.addr, Amount: 6 * mi},
+	)
+
+	diff, err := l.ApplyMessage(msg)
+	require.NoError(t, err)
+	require.Len(t, diff.Created, 2)
+	require.Len(t, diff.Consumed, 1)
+	require.EqualValues(t, 6*mi, l.Balance(alice.addr))
+	require.EqualValues(t, 4*mi, l.Balance(bob.addr))
+	require.True(t, l.IsSpent(genesis.ID))
+
+	// double spend
+	_, err = l.ApplyMessage(msg)
+	require.True(t, errors.Is(err, ledger.ErrOutputAlreadySpent))
+
+	// rollback restores the genesis state
+	_, err = l.Rollback()
+	require.NoError(t, err)
+	require.EqualValues(t, 10*mi, l.Balance(alice.addr))
+	require.Zero(t, l.Balance(bob.addr))
+	require.False(t, l.IsSpent(genesis.ID))
+	_, err = l.Output(genesis.ID)
+	require.NoError(t, err)
+}
+
+func TestLedger_ApplyMessagesAtomic(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+
+	l := ledger.New()
+	genesis := genesisOutput(alice.addr, 10*mi)
+	_, err := l.AddOutputs(genesis)
+	require.NoError(t, err)
+
+	valid := transferMessage(t, alice, genesis, &iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10 * mi})
+	// creates a dust output on an address without dust allowance
+	invalid := transferMessage(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10*mi - 1},
+		&iotago.SigLockedSingleOutput{Address: alice.addr, Amount: 1},
+	)
+
+	_, err = l.ApplyMessages(valid, invalid)
+	require.Error(t, err)
+	require.EqualValues(t, 10*mi, l.Balance(alice.addr))
+	require.Zero(t, l.Balance(bob.addr))
+
+	_, err = l.ApplyMessages(invalid)
+	require.True(t, errors.Is(err, iotago.ErrInvalidDustAllowance))
+}
+
+func TestLedger_ApplyMilestoneWithReceipt(t *testing.T) {
+	alice := randIdentity()
+
+	l := ledger.New()
+	prevMsID := tpkg.Rand32ByteArray()
+	l.SetTreasury(&ledger.Treasury{MilestoneID: prevMsID, Amount: 100 * mi})
+
+	treasuryInput := iotago.TreasuryInput(prevMsID)
+	ms := &iotago.Milestone{
+		Index:   5,
+		Parents: iotago.MilestoneParentMessageIDs{tpkg.Rand32ByteArray()},
+		Receipt: &iotago.Receipt{
+			MigratedAt: 1000,
+			Final:      true,
+			Funds: serializer.Serializables{
+				&iotago.MigratedFundsEntry{TailTransactionHash: tpkg.Rand49ByteArray(), Address: alice.addr, Deposit: 10 * mi},
+			},
+			Transaction: &iotago.TreasuryTransaction{
+				Input:  &treasuryInput,
+				Output: &iotago.TreasuryOutput{Amount: 90 * mi},
+			},
+		},
+	}
+	msg := &iotago.Message{Parents: iotago.MessageIDs{tpkg.Rand32ByteArray()}, Payload: ms}
+
+	diff, err := l.ApplyMessage(msg)
+	require.NoError(t, err)
+	require.Len(t, diff.Created, 1)
+	require.EqualValues(t, 5, l.Index())
+	require.EqualValues(t, 10*mi, l.Balance(alice.addr))
+
+	msID, err := ms.ID()
+	require.NoError(t, err)
+	require.Equal(t, &ledger.Treasury{MilestoneID: *msID, Amount: 90 * mi}, l.Treasury())
+
+	// the same milestone can't be applied twice
+	_, err = l.ApplyMessage(msg)
+	require.True(t, errors.Is(err, ledger.ErrMilestoneIndexNotAscending))
+
+	require.NoError(t, l.RollbackTo(0))
+	require.Zero(t, l.Balance(alice.addr))
+	require.Equal(t, &ledger.Treasury{MilestoneID: prevMsID, Amount: 100 * mi}, l.Treasury())
+}
+
+func milestoneMessage(index uint32) *iotago.Message {
+	ms := &iotago.Milestone{Index: index, Parents: iotago.MilestoneParentMessageIDs{tpkg.Rand32ByteArray()}}
+	return &iotago.Message{Parents: iotago.MessageIDs{tpkg.Rand32ByteArray()}, Payload: ms}
+}
+
+func TestLedger_RollbackTo(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+
+	l := ledger.New()
+	genesis := genesisOutput(alice.addr, 10*mi)
+	_, err := l.AddOutputs(genesis)
+	require.NoError(t, err)
+
+	_, err = l.ApplyMessage(milestoneMessage(1))
+	require.NoError(t, err)
+	// applied at index 1 after milestone 1
+	_, err = l.ApplyMessage(transferMessage(t, alice, genesis, &iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10 * mi}))
+	require.NoError(t, err)
+	_, err = l.ApplyMessage(milestoneMessage(2))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, l.Index())
+
+	// everything applied after milestone 1 is rolled back
+	require.NoError(t, l.RollbackTo(1))
+	require.EqualValues(t, 1, l.Index())
+	require.EqualValues(t, 10*mi, l.Balance(alice.addr))
+	require.Zero(t, l.Balance(bob.addr))
+	require.False(t, l.IsSpent(genesis.ID))
+
+	// the seeding diffs are kept
+	require.NoError(t, l.RollbackTo(0))
+	require.EqualValues(t, 0, l.Index())
+	_, err = l.Output(genesis.ID)
+	require.NoError(t, err)
+}
+
+func TestLedger_RollbackToSnapshotIndex(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+
+	genesis := genesisOutput(alice.addr, 10*mi)
+	l, err := ledger.NewFromSnapshot(&ledger.Snapshot{Index: 5, Outputs: []*ledger.Output{genesis}})
+	require.NoError(t, err)
+
+	// seeding at the snapshot index is kept, the transaction applied afterwards is not
+	seed := genesisOutput(bob.addr, 1*mi)
+	_, err = l.AddOutputs(seed)
+	require.NoError(t, err)
+	_, err = l.ApplyMessage(transferMessage(t, alice, genesis, &iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10 * mi}))
+	require.NoError(t, err)
+	require.EqualValues(t, 11*mi, l.Balance(bob.addr))
+
+	require.NoError(t, l.RollbackTo(5))
+	require.EqualValues(t, 5, l.Index())
+	require.EqualValues(t, 10*mi, l.Balance(alice.addr))
+	require.EqualValues(t, 1*mi, l.Balance(bob.addr))
+	require.False(t, l.IsSpent(genesis.ID))
+}
+
+func TestLedger_RollbackDepth(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+
+	l := ledger.New(ledger.WithRollbackDepth(1))
+	genesis := genesisOutput(alice.addr, 10*mi)
+	_, err := l.AddOutputs(genesis)
+	require.NoError(t, err)
+
+	transfer := transferMessage(t, alice, genesis, &iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10 * mi})
+	_, err = l.ApplyMessage(transfer)
+	require.NoError(t, err)
+	require.True(t, l.IsSpent(genesis.ID))
+
+	// the diff consuming the genesis output is pruned from the rollback history but the output stays spent
+	_, err = l.ApplyMessage(milestoneMessage(1))
+	require.NoError(t, err)
+	require.True(t, l.IsSpent(genesis.ID))
+	_, err = l.Spent(genesis.ID)
+	require.NoError(t, err)
+	_, err = l.ApplyMessage(transfer)
+	require.True(t, errors.Is(err, ledger.ErrOutputAlreadySpent))
+
+	// only the milestone can be rolled back
+	require.NoError(t, l.RollbackTo(0))
+	require.EqualValues(t, 10*mi, l.Balance(bob.addr))
+	_, err = l.Rollback()
+	require.True(t, errors.Is(err, ledger.ErrNothingToRollback))
+}
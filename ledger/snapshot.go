@@ -0,0 +1,170 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+const (
+	// SnapshotVersion defines the version of the snapshot format.
+	SnapshotVersion byte = 1
+	// TreasuryBytesSize defines the binary serialized size of a Treasury.
+	TreasuryBytesSize = iotago.MilestoneIDLength + serializer.UInt64ByteSize
+	// the max. size of a single serialized record within a snapshot or storage log.
+	maxRecordSize = 1 << 20
+)
+
+var (
+	// ErrUnsupportedSnapshotVersion gets returned when a snapshot with an unknown version is read.
+	ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot version")
+	// ErrRecordTooLarge gets returned when a record exceeds the max. record size.
+	ErrRecordTooLarge = errors.New("record too large")
+)
+
+// Snapshot is the state of the Ledger at a given ledger index.
+// It contains only the unspent outputs, spent outputs are not part of a snapshot.
+// In its binary form, a snapshot consists out of the version byte, the ledger index, a bool denoting whether
+// a treasury follows, the optional treasury, the outputs count and the outputs, each prefixed by its length as uint32.
+type Snapshot struct {
+	// The ledger index of the snapshot.
+	Index uint32
+	// The treasury, nil if the Ledger has no treasury.
+	Treasury *Treasury
+	// The unspent outputs.
+	Outputs []*Output
+}
+
+// WriteSnapshot writes the given Snapshot in its binary form to the given writer.
+func WriteSnapshot(w io.Writer, snapshot *Snapshot) error {
+	seri := serializer.NewSerializer().
+		WriteByte(SnapshotVersion, func(err error) error {
+			return fmt.Errorf("unable to serialize snapshot version: %w", err)
+		}).
+		WriteNum(snapshot.Index, func(err error) error {
+			return fmt.Errorf("unable to serialize snapshot ledger index: %w", err)
+		}).
+		WriteBool(snapshot.Treasury != nil, func(err error) error {
+			return fmt.Errorf("unable to serialize snapshot treasury flag: %w", err)
+		})
+	if snapshot.Treasury != nil {
+		treasuryBytes, err := snapshot.Treasury.Serialize(serializer.DeSeriModePerformValidation)
+		if err != nil {
+			return err
+		}
+		seri.WriteBytes(treasuryBytes, func(err error) error {
+			return fmt.Errorf("unable to serialize snapshot treasury: %w", err)
+		})
+	}
+	header, err := seri.
+		WriteNum(uint64(len(snapshot.Outputs)), func(err error) error {
+			return fmt.Errorf("unable to serialize snapshot outputs count: %w", err)
+		}).
+		Serialize()
+	if err != nil {
+		return err
+	}
+
+	bufWriter := bufio.NewWriter(w)
+	if _, err := bufWriter.Write(header); err != nil {
+		return fmt.Errorf("unable to write snapshot header: %w", err)
+	}
+
+	for _, output := range snapshot.Outputs {
+		outputBytes, err := output.Serialize(serializer.DeSeriModePerformValidation)
+		if err != nil {
+			return err
+		}
+		if err := writeRecord(bufWriter, outputBytes); err != nil {
+			return fmt.Errorf("unable to write snapshot output %s: %w", output.ID.ToHex(), err)
+		}
+	}
+
+	return bufWriter.Flush()
+}
+
+// ReadSnapshot reads a Snapshot in its binary form from the given reader.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	bufReader := bufio.NewReader(r)
+
+	version, err := bufReader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read snapshot version: %w", err)
+	}
+	if version != SnapshotVersion {
+		return nil, fmt.Errorf("%w: version %d, supported %d", ErrUnsupportedSnapshotVersion, version, SnapshotVersion)
+	}
+
+	snapshot := &Snapshot{}
+	if err := binary.Read(bufReader, binary.LittleEndian, &snapshot.Index); err != nil {
+		return nil, fmt.Errorf("unable to read snapshot ledger index: %w", err)
+	}
+
+	var hasTreasury bool
+	if err := binary.Read(bufReader, binary.LittleEndian, &hasTreasury); err != nil {
+		return nil, fmt.Errorf("unable to read snapshot treasury flag: %w", err)
+	}
+	if hasTreasury {
+		treasuryBytes := make([]byte, TreasuryBytesSize)
+		if _, err := io.ReadFull(bufReader, treasuryBytes); err != nil {
+			return nil, fmt.Errorf("unable to read snapshot treasury: %w", err)
+		}
+		snapshot.Treasury = &Treasury{}
+		if _, err := snapshot.Treasury.Deserialize(treasuryBytes, serializer.DeSeriModePerformValidation); err != nil {
+			return nil, err
+		}
+	}
+
+	var outputsCount uint64
+	if err := binary.Read(bufReader, binary.LittleEndian, &outputsCount); err != nil {
+		return nil, fmt.Errorf("unable to read snapshot outputs count: %w", err)
+	}
+
+	for i := uint64(0); i < outputsCount; i++ {
+		outputBytes, err := readRecord(bufReader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read snapshot output at pos %d: %w", i, err)
+		}
+		output := &Output{}
+		if _, err := output.Deserialize(outputBytes, serializer.DeSeriModePerformValidation); err != nil {
+			return nil, fmt.Errorf("unable to deserialize snapshot output at pos %d: %w", i, err)
+		}
+		snapshot.Outputs = append(snapshot.Outputs, output)
+	}
+
+	return snapshot, nil
+}
+
+// writes the given data prefixed by its length to the given writer.
+func writeRecord(w io.Writer, data []byte) error {
+	if len(data) > maxRecordSize {
+		return fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, len(data))
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// reads data prefixed by its length from the given reader.
+func readRecord(r io.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return nil, err
+	}
+	if length > maxRecordSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
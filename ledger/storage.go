@@ -0,0 +1,35 @@
+package ledger
+
+import (
+	"io"
+)
+
+// State is the persisted state of the Ledger.
+type State struct {
+	// The ledger index.
+	Index uint32
+	// The treasury, nil if the Ledger has no treasury.
+	Treasury *Treasury
+	// The unspent outputs.
+	Unspent []*Output
+	// The spent outputs.
+	Spent []*Spent
+}
+
+// Storage persists the state of a Ledger.
+// A Ledger backed by a Storage (see Open) commits every Diff to it before the mutation becomes visible to callers.
+type Storage interface {
+	// Load loads the persisted state.
+	Load() (*State, error)
+	// Commit atomically persists the changes of the given Diff.
+	Commit(diff *Diff) error
+	// Revert atomically persists the reversal of the given Diff, which must be the last committed one.
+	Revert(diff *Diff) error
+	// ExportSnapshot writes a Snapshot of the persisted state to the given writer.
+	ExportSnapshot(w io.Writer) error
+	// ImportSnapshot replaces the persisted state with the Snapshot read from the given reader.
+	// It must not be called on a Storage backing an open Ledger, whose in-memory state would go stale.
+	ImportSnapshot(r io.Reader) error
+	// Close closes the Storage.
+	Close() error
+}
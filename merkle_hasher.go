@@ -0,0 +1,143 @@
+package iotago
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// MerkleLeafHashPrefix is the domain separation prefix for leaf hashes.
+	MerkleLeafHashPrefix = 0x00
+	// MerkleNodeHashPrefix is the domain separation prefix for inner node hashes.
+	MerkleNodeHashPrefix = 0x01
+	// MerkleHashLength defines the length of a hash within the Merkle tree.
+	MerkleHashLength = blake2b.Size256
+)
+
+var (
+	// ErrMerkleLeafIndexOutOfRange gets returned when an audit path is requested for a leaf which is not part of the tree.
+	ErrMerkleLeafIndexOutOfRange = errors.New("merkle leaf index out of range")
+	// ErrMerkleAuditPathInvalid gets returned when an audit path does not prove the inclusion of a leaf.
+	ErrMerkleAuditPathInvalid = errors.New("invalid merkle audit path")
+)
+
+// MerkleHash is a hash within the Merkle tree.
+type MerkleHash = [MerkleHashLength]byte
+
+// MerkleTreeHash computes the Merkle tree hash of the given ordered message IDs as defined in RFC 6962,
+// using BLAKE2b-256 as the hash function. This is the value a milestone holds as its InclusionMerkleProof:
+// the tree over the IDs of the messages containing transactions which were included by the milestone,
+// in the order they were applied to the ledger.
+func MerkleTreeHash(ids MessageIDs) MerkleHash {
+	if len(ids) == 0 {
+		return blake2b.Sum256(nil)
+	}
+	if len(ids) == 1 {
+		return merkleLeafHash(ids[0])
+	}
+	k := merkleSplit(len(ids))
+	return merkleNodeHash(MerkleTreeHash(ids[:k]), MerkleTreeHash(ids[k:]))
+}
+
+// MerkleAuditPath proves the inclusion of a leaf within a Merkle tree of a given size.
+type MerkleAuditPath struct {
+	// The index of the leaf within the tree.
+	LeafIndex int
+	// The amount of leaves within the tree.
+	TreeSize int
+	// The sibling hashes from the leaf up to the root.
+	Hashes []MerkleHash
+}
+
+// NewMerkleAuditPath computes the audit path of the leaf at the given index within the Merkle tree of the given message IDs.
+func NewMerkleAuditPath(ids MessageIDs, leafIndex int) (*MerkleAuditPath, error) {
+	if leafIndex < 0 || leafIndex >= len(ids) {
+		return nil, fmt.Errorf("%w: index %d, tree size %d", ErrMerkleLeafIndexOutOfRange, leafIndex, len(ids))
+	}
+	return &MerkleAuditPath{LeafIndex: leafIndex, TreeSize: len(ids), Hashes: merkleAuditPath(ids, leafIndex)}, nil
+}
+
+// computes the audit path of the leaf at index m, ordered from the leaf up to the root.
+func merkleAuditPath(ids MessageIDs, m int) []MerkleHash {
+	if len(ids) <= 1 {
+		return nil
+	}
+	k := merkleSplit(len(ids))
+	if m < k {
+		return append(merkleAuditPath(ids[:k], m), MerkleTreeHash(ids[k:]))
+	}
+	return append(merkleAuditPath(ids[k:], m-k), MerkleTreeHash(ids[:k]))
+}
+
+// Root computes the Merkle tree hash the audit path leads to for the given leaf.
+func (p *MerkleAuditPath) Root(id MessageID) (MerkleHash, error) {
+	if p.LeafIndex < 0 || p.LeafIndex >= p.TreeSize {
+		return MerkleHash{}, fmt.Errorf("%w: index %d, tree size %d", ErrMerkleLeafIndexOutOfRange, p.LeafIndex, p.TreeSize)
+	}
+
+	fn, sn := p.LeafIndex, p.TreeSize-1
+	r := merkleLeafHash(id)
+	for _, h := range p.Hashes {
+		if sn == 0 {
+			return MerkleHash{}, fmt.Errorf("%w: path is too long", ErrMerkleAuditPathInvalid)
+		}
+		if fn&1 == 1 || fn == sn {
+			r = merkleNodeHash(h, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNodeHash(r, h)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return MerkleHash{}, fmt.Errorf("%w: path is too short", ErrMerkleAuditPathInvalid)
+	}
+	return r, nil
+}
+
+// Verify verifies that the audit path proves the inclusion of the given leaf in the tree with the given root.
+func (p *MerkleAuditPath) Verify(id MessageID, root MerkleHash) error {
+	computed, err := p.Root(id)
+	if err != nil {
+		return err
+	}
+	if computed != root {
+		return fmt.Errorf("%w: computed root %x, expected %x", ErrMerkleAuditPathInvalid, computed, root)
+	}
+	return nil
+}
+
+// VerifyInclusion verifies that the given message ID is part of the inclusion Merkle tree of the Milestone.
+// The caller must verify the Milestone's signatures separately in order to trust its InclusionMerkleProof.
+func (m *Milestone) VerifyInclusion(id MessageID, path *MerkleAuditPath) error {
+	return path.Verify(id, m.InclusionMerkleProof)
+}
+
+// returns the largest power of two less than n.
+func merkleSplit(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// computes the hash of the given leaf.
+func merkleLeafHash(id MessageID) MerkleHash {
+	var data [1 + MessageIDLength]byte
+	data[0] = MerkleLeafHashPrefix
+	copy(data[1:], id[:])
+	return blake2b.Sum256(data[:])
+}
+
+// computes the hash of an inner node with the given children.
+func merkleNodeHash(left MerkleHash, right MerkleHash) MerkleHash {
+	var data [1 + 2*MerkleHashLength]byte
+	data[0] = MerkleNodeHashPrefix
+	copy(data[1:], left[:])
+	copy(data[1+MerkleHashLength:], right[:])
+	return blake2b.Sum256(data[:])
+}
@@ -0,0 +1,74 @@
+package iotago_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+func randMessageIDs(count int) iotago.MessageIDs {
+	ids := make(iotago.MessageIDs, count)
+	for i := range ids {
+		ids[i] = tpkg.Rand32ByteArray()
+	}
+	return ids
+}
+
+func TestMerkleTreeHash(t *testing.T) {
+	leaf := func(id iotago.MessageID) iotago.MerkleHash {
+		return blake2b.Sum256(append([]byte{iotago.MerkleLeafHashPrefix}, id[:]...))
+	}
+	node := func(l, r iotago.MerkleHash) iotago.MerkleHash {
+		return blake2b.Sum256(append(append([]byte{iotago.MerkleNodeHashPrefix}, l[:]...), r[:]...))
+	}
+
+	ids := randMessageIDs(5)
+	tests := []struct {
+		name string
+		ids  iotago.MessageIDs
+		root iotago.MerkleHash
+	}{
+		{"empty", nil, blake2b.Sum256(nil)},
+		{"one", ids[:1], leaf(ids[0])},
+		{"two", ids[:2], node(leaf(ids[0]), leaf(ids[1]))},
+		{"three", ids[:3], node(node(leaf(ids[0]), leaf(ids[1])), leaf(ids[2]))},
+		{"five", ids, node(node(node(leaf(ids[0]), leaf(ids[1])), node(leaf(ids[2]), leaf(ids[3]))), leaf(ids[4]))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.root, iotago.MerkleTreeHash(tt.ids))
+		})
+	}
+}
+
+func TestMerkleAuditPath(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 7, 8, 13} {
+		ids := randMessageIDs(size)
+		ms := &iotago.Milestone{InclusionMerkleProof: iotago.MerkleTreeHash(ids)}
+
+		for i := range ids {
+			path, err := iotago.NewMerkleAuditPath(ids, i)
+			require.NoError(t, err)
+			require.NoError(t, ms.VerifyInclusion(ids[i], path))
+
+			// a different leaf must not verify against the same path
+			err = ms.VerifyInclusion(tpkg.Rand32ByteArray(), path)
+			require.True(t, errors.Is(err, iotago.ErrMerkleAuditPathInvalid))
+
+			if len(path.Hashes) > 0 {
+				tampered := *path
+				tampered.Hashes = append([]iotago.MerkleHash{}, path.Hashes[:len(path.Hashes)-1]...)
+				require.True(t, errors.Is(ms.VerifyInclusion(ids[i], &tampered), iotago.ErrMerkleAuditPathInvalid))
+			}
+		}
+	}
+
+	_, err := iotago.NewMerkleAuditPath(randMessageIDs(3), 3)
+	require.True(t, errors.Is(err, iotago.ErrMerkleLeafIndexOutOfRange))
+}
@@ -66,7 +66,7 @@ func (mb *MessageBuilder) Payload(seri serializer.Serializable) *MessageBuilder
 }
 
 // Tips uses the given NodeHTTPAPIClient to query for parents to use.
-func (mb *MessageBuilder) Tips(ctx context.Context, nodeAPI *NodeHTTPAPIClient) *MessageBuilder {
+func (mb *MessageBuilder) Tips(ctx context.Context, nodeAPI NodeAPI) *MessageBuilder {
 	if mb.err != nil {
 		return mb
 	}
@@ -163,7 +163,17 @@ func (m *Milestone) ID() (*MilestoneID, error) {
 
 // Essence returns the essence bytes (the bytes to be signed) of the Milestone.
 func (m *Milestone) Essence() ([]byte, error) {
-	essenceBytes, err := serializer.NewSerializer().
+	essenceBytes, err := m.EssenceBytes()
+	if err != nil {
+		return nil, err
+	}
+	essenceHash := blake2b.Sum256(essenceBytes)
+	return essenceHash[:], nil
+}
+
+// EssenceBytes returns the serialized essence of the Milestone, the data which is hashed to produce Essence.
+func (m *Milestone) EssenceBytes() ([]byte, error) {
+	return serializer.NewSerializer().
 		AbortIf(func(err error) error {
 			if len(m.PublicKeys) < MinPublicKeysInAMilestone {
 				return fmt.Errorf("unable to serialize milestone as essence: %w", ErrMilestoneTooFewPublicKeys)
@@ -195,11 +205,48 @@ func (m *Milestone) Essence() ([]byte, error) {
 			return fmt.Errorf("unable to serialize milestone receipt for essence: %w", err)
 		}).
 		Serialize()
-	if err != nil {
-		return nil, err
-	}
-	essenceHash := blake2b.Sum256(essenceBytes)
-	return essenceHash[:], nil
+}
+
+// DeserializeEssence deserializes the given essence bytes (as produced by EssenceBytes) into the Milestone.
+// The signatures of the Milestone are left untouched.
+func (m *Milestone) DeserializeEssence(data []byte, deSeriMode serializer.DeSerializationMode) (int, error) {
+	return serializer.NewDeserializer(data).
+		ReadNum(&m.Index, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone index from essence: %w", err)
+		}).
+		ReadNum(&m.Timestamp, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone timestamp from essence: %w", err)
+		}).
+		ReadSliceOfArraysOf32Bytes(&m.Parents, deSeriMode, serializer.SeriLengthPrefixTypeAsByte, &milestoneParentArrayRules, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone parents from essence: %w", err)
+		}).
+		ReadArrayOf32Bytes(&m.InclusionMerkleProof, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone inclusion merkle proof from essence: %w", err)
+		}).
+		ReadNum(&m.NextPoWScore, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone next pow score from essence: %w", err)
+		}).
+		ReadNum(&m.NextPoWScoreMilestoneIndex, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone next pow score milestone index from essence: %w", err)
+		}).
+		AbortIf(func(err error) error {
+			if m.NextPoWScore != 0 && m.NextPoWScoreMilestoneIndex == 0 {
+				return fmt.Errorf("%w: next-pow-score-milestone-index is zero but next-pow-score is not", ErrMilestoneInvalidMinPoWScoreValues)
+			}
+			return nil
+		}).
+		ReadSliceOfArraysOf32Bytes(&m.PublicKeys, deSeriMode, serializer.SeriLengthPrefixTypeAsByte, &milestonePublicKeyArrayRules, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone public keys from essence: %w", err)
+		}).
+		ReadPayload(func(seri serializer.Serializable) { m.Receipt = seri }, deSeriMode, func(ty uint32) (serializer.Serializable, error) {
+			if ty != ReceiptPayloadTypeID {
+				return nil, fmt.Errorf("a milestone can only contain a receipt payload but got type ID %d:  %w", ty, ErrUnknownPayloadType)
+			}
+			return PayloadSelector(ty)
+		}, func(err error) error {
+			return fmt.Errorf("unable to deserialize milestone receipt from essence: %w", err)
+		}).
+		Done()
 }
 
 // VerifySignatures verifies that min. minSigThreshold signatures occur in the Milestone and that all
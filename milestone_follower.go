@@ -0,0 +1,256 @@
+package iotago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMilestoneFollowerNotAMilestone gets returned when the message referenced by a milestone response does not hold the expected Milestone.
+	ErrMilestoneFollowerNotAMilestone = errors.New("message does not hold the expected milestone")
+	// ErrMilestoneFollowerMessageIDMismatch gets returned when the node serves a message which does not match the requested message ID.
+	ErrMilestoneFollowerMessageIDMismatch = errors.New("message ID mismatch")
+	// ErrMilestoneFollowerIndexNotContiguous gets returned when a milestone does not directly follow the latest trusted milestone.
+	ErrMilestoneFollowerIndexNotContiguous = errors.New("milestone index is not contiguous")
+	// ErrMilestoneFollowerTimestampNotMonotonic gets returned when a milestone's timestamp is older than the one of the latest trusted milestone.
+	ErrMilestoneFollowerTimestampNotMonotonic = errors.New("milestone timestamp is not monotonic")
+	// ErrMilestoneFollowerInvalidPoWScoreTransition gets returned when a milestone announces an invalid PoW score transition
+	// or alters a pending one.
+	ErrMilestoneFollowerInvalidPoWScoreTransition = errors.New("invalid pow score transition")
+)
+
+// TrustedMilestone is a Milestone which passed the verification of a MilestoneFollower.
+type TrustedMilestone struct {
+	// The ID of the message holding the milestone.
+	MessageID MessageID
+	// The milestone.
+	Milestone *Milestone
+}
+
+// MilestoneFollowerOption is a function setting a MilestoneFollower option.
+type MilestoneFollowerOption func(opts *MilestoneFollowerOptions)
+
+// MilestoneFollowerOptions define options for the MilestoneFollower.
+type MilestoneFollowerOptions struct {
+	// The interval in which Run polls the node for new milestones.
+	pollInterval time.Duration
+	// Called for every milestone which passed the verification.
+	onTrusted func(trusted *TrustedMilestone)
+	// The PoW score in effect at the start index.
+	initialPoWScore uint32
+}
+
+// applies the given MilestoneFollowerOption.
+func (mfo *MilestoneFollowerOptions) apply(opts ...MilestoneFollowerOption) {
+	for _, opt := range opts {
+		opt(mfo)
+	}
+}
+
+// WithMilestoneFollowerPollInterval sets the interval in which Run polls the node for new milestones.
+func WithMilestoneFollowerPollInterval(interval time.Duration) MilestoneFollowerOption {
+	return func(opts *MilestoneFollowerOptions) {
+		opts.pollInterval = interval
+	}
+}
+
+// WithMilestoneFollowerOnTrusted sets a function which is called for every milestone which passed the verification.
+func WithMilestoneFollowerOnTrusted(onTrusted func(trusted *TrustedMilestone)) MilestoneFollowerOption {
+	return func(opts *MilestoneFollowerOptions) {
+		opts.onTrusted = onTrusted
+	}
+}
+
+// WithMilestoneFollowerInitialPoWScore sets the min. PoW score which is in effect at the start index.
+func WithMilestoneFollowerInitialPoWScore(powScore uint32) MilestoneFollowerOption {
+	return func(opts *MilestoneFollowerOptions) {
+		opts.initialPoWScore = powScore
+	}
+}
+
+// the default options applied to the MilestoneFollower.
+var defaultMilestoneFollowerOptions = []MilestoneFollowerOption{
+	WithMilestoneFollowerPollInterval(5 * time.Second),
+	WithMilestoneFollowerInitialPoWScore(4000),
+}
+
+// NewMilestoneFollower creates a new MilestoneFollower which starts walking the milestone chain at the given trusted start index.
+// The milestone at the start index is only checked against the MilestoneKeyManager as there is no predecessor to compare it with.
+func NewMilestoneFollower(nodeAPI NodeAPI, keyManager *MilestoneKeyManager, startIndex uint32, opts ...MilestoneFollowerOption) *MilestoneFollower {
+	options := &MilestoneFollowerOptions{}
+	options.apply(defaultMilestoneFollowerOptions...)
+	options.apply(opts...)
+
+	return &MilestoneFollower{
+		opts:       options,
+		nodeAPI:    nodeAPI,
+		keyManager: keyManager,
+		startIndex: startIndex,
+		powScore:   options.initialPoWScore,
+	}
+}
+
+// MilestoneFollower is a light client which walks the milestone chain of a node and verifies every milestone
+// against a MilestoneKeyManager. It additionally checks that milestone indices are contiguous, that timestamps
+// are monotonic and that PoW score transitions are announced consistently.
+// The latest trusted milestone can be used instead of blindly trusting the info of the node.
+type MilestoneFollower struct {
+	// serializes Next so that the state only changes in the order milestones are verified.
+	nextMu sync.Mutex
+	// guards latest, powScore and pending, which are only written by Next.
+	mu         sync.RWMutex
+	opts       *MilestoneFollowerOptions
+	nodeAPI    NodeAPI
+	keyManager *MilestoneKeyManager
+	startIndex uint32
+	latest     *TrustedMilestone
+	powScore   uint32
+	pending    *Milestone
+}
+
+// Latest returns the latest trusted milestone or nil if no milestone has been verified yet.
+func (mf *MilestoneFollower) Latest() *TrustedMilestone {
+	mf.mu.RLock()
+	defer mf.mu.RUnlock()
+	return mf.latest
+}
+
+// PoWScore returns the min. PoW score in effect as of the latest trusted milestone.
+func (mf *MilestoneFollower) PoWScore() uint32 {
+	mf.mu.RLock()
+	defer mf.mu.RUnlock()
+	return mf.powScore
+}
+
+// Next fetches and verifies the milestone following the latest trusted milestone.
+// Returns an error wrapping ErrHTTPNotFound if the node doesn't know the next milestone yet.
+// The node is queried without holding the lock guarding the state, so Latest and PoWScore don't block meanwhile.
+func (mf *MilestoneFollower) Next(ctx context.Context) (*TrustedMilestone, error) {
+	mf.nextMu.Lock()
+	defer mf.nextMu.Unlock()
+
+	// only Next writes the state, which is serialized by nextMu
+	index := mf.startIndex
+	if mf.latest != nil {
+		index = mf.latest.Milestone.Index + 1
+	}
+
+	msRes, err := mf.nodeAPI.MilestoneByIndex(ctx, index)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query milestone %d: %w", index, err)
+	}
+
+	msgID, err := MessageIDFromHexString(msRes.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode message ID of milestone %d: %w", index, err)
+	}
+
+	msg, err := mf.nodeAPI.MessageByMessageID(ctx, msgID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query message of milestone %d: %w", index, err)
+	}
+
+	computedMsgID, err := msg.ID()
+	if err != nil {
+		return nil, err
+	}
+	if *computedMsgID != msgID {
+		return nil, fmt.Errorf("%w: requested %s, got %s", ErrMilestoneFollowerMessageIDMismatch, MessageIDToHexString(msgID), MessageIDToHexString(*computedMsgID))
+	}
+
+	ms, ok := msg.Payload.(*Milestone)
+	if !ok || ms.Index != index {
+		return nil, fmt.Errorf("%w: message %s, milestone %d", ErrMilestoneFollowerNotAMilestone, msRes.MessageID, index)
+	}
+
+	powScore, pending, err := mf.verify(ms)
+	if err != nil {
+		return nil, err
+	}
+
+	trusted := &TrustedMilestone{MessageID: msgID, Milestone: ms}
+	mf.mu.Lock()
+	mf.latest, mf.powScore, mf.pending = trusted, powScore, pending
+	mf.mu.Unlock()
+
+	if mf.opts.onTrusted != nil {
+		mf.opts.onTrusted(trusted)
+	}
+	return trusted, nil
+}
+
+// verifies the given Milestone against the latest trusted milestone and returns the resulting PoW score state.
+// Must only be called by Next.
+func (mf *MilestoneFollower) verify(ms *Milestone) (uint32, *Milestone, error) {
+	if err := mf.keyManager.VerifyMilestone(ms); err != nil {
+		return 0, nil, fmt.Errorf("unable to verify milestone %d: %w", ms.Index, err)
+	}
+
+	if mf.latest != nil {
+		prev := mf.latest.Milestone
+		if ms.Index != prev.Index+1 {
+			return 0, nil, fmt.Errorf("%w: latest trusted %d, got %d", ErrMilestoneFollowerIndexNotContiguous, prev.Index, ms.Index)
+		}
+		if ms.Timestamp < prev.Timestamp {
+			return 0, nil, fmt.Errorf("%w: milestone %d has timestamp %d, latest trusted milestone %d has %d", ErrMilestoneFollowerTimestampNotMonotonic, ms.Index, ms.Timestamp, prev.Index, prev.Timestamp)
+		}
+	}
+
+	// a pending transition takes effect once its milestone index is hit
+	powScore, pending := mf.powScore, mf.pending
+	if pending != nil && ms.Index >= pending.NextPoWScoreMilestoneIndex {
+		powScore, pending = pending.NextPoWScore, nil
+	}
+
+	if ms.NextPoWScoreMilestoneIndex != 0 || ms.NextPoWScore != 0 {
+		if ms.NextPoWScoreMilestoneIndex <= ms.Index {
+			return 0, nil, fmt.Errorf("%w: milestone %d announces a transition at index %d", ErrMilestoneFollowerInvalidPoWScoreTransition, ms.Index, ms.NextPoWScoreMilestoneIndex)
+		}
+		if pending != nil && (pending.NextPoWScore != ms.NextPoWScore || pending.NextPoWScoreMilestoneIndex != ms.NextPoWScoreMilestoneIndex) {
+			return 0, nil, fmt.Errorf("%w: milestone %d alters the pending transition to %d at %d", ErrMilestoneFollowerInvalidPoWScoreTransition, ms.Index, pending.NextPoWScore, pending.NextPoWScoreMilestoneIndex)
+		}
+		pending = ms
+	} else if pending != nil {
+		return 0, nil, fmt.Errorf("%w: milestone %d drops the pending transition to %d at %d", ErrMilestoneFollowerInvalidPoWScoreTransition, ms.Index, pending.NextPoWScore, pending.NextPoWScoreMilestoneIndex)
+	}
+
+	return powScore, pending, nil
+}
+
+// Sync verifies all milestones following the latest trusted milestone until the node doesn't know any newer milestone.
+// Returns the latest trusted milestone, which might be nil if not even the milestone at the start index is known.
+func (mf *MilestoneFollower) Sync(ctx context.Context) (*TrustedMilestone, error) {
+	for {
+		if _, err := mf.Next(ctx); err != nil {
+			if errors.Is(err, ErrHTTPNotFound) {
+				return mf.Latest(), nil
+			}
+			return mf.Latest(), err
+		}
+	}
+}
+
+// Run syncs the MilestoneFollower in the configured poll interval until the given context is done
+// or an error occurs, i.e. a milestone fails the verification.
+func (mf *MilestoneFollower) Run(ctx context.Context) error {
+	ticker := time.NewTicker(mf.opts.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := mf.Sync(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package iotago_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+// mocks the milestone and message routes for the given milestone signed by the given keys.
+func mockMilestone(t *testing.T, ms *iotago.Milestone, prvKeys iotago.MilestonePublicKeyMapping) {
+	require.NoError(t, ms.Sign(iotago.InMemoryEd25519MilestoneSigner(prvKeys)))
+
+	msg := &iotago.Message{Parents: tpkg.SortedRand32BytArray(1), Payload: ms}
+	data, err := msg.Serialize(serializer.DeSeriModePerformValidation)
+	require.NoError(t, err)
+	msgID, err := msg.ID()
+	require.NoError(t, err)
+
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMilestone, strconv.Itoa(int(ms.Index)))).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MilestoneResponse{
+			Index:     ms.Index,
+			MessageID: iotago.MessageIDToHexString(*msgID),
+			Time:      int64(ms.Timestamp),
+		}})
+
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMessageBytes, iotago.MessageIDToHexString(*msgID))).
+		Reply(200).
+		Body(bytes.NewReader(data))
+}
+
+// mocks the milestone route to not know the milestone with the given index.
+func mockMilestoneNotFound(index uint32) {
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMilestone, strconv.Itoa(int(index)))).
+		Reply(404).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+}
+
+func TestMilestoneFollower_Sync(t *testing.T) {
+	pubKey, prvKey := randMilestoneKey()
+	prvKeys := iotago.MilestonePublicKeyMapping{pubKey: prvKey}
+	km := iotago.NewMilestoneKeyManager(1)
+	require.NoError(t, km.AddKeyRange(pubKey, 0, 0))
+
+	newMilestone := func(index uint32, timestamp uint64) *iotago.Milestone {
+		ms, err := iotago.NewMilestone(index, timestamp, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), []iotago.MilestonePublicKey{pubKey})
+		require.NoError(t, err)
+		return ms
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		defer gock.Off()
+
+		ms10 := newMilestone(10, 1000)
+		ms10.NextPoWScore, ms10.NextPoWScoreMilestoneIndex = 2000, 12
+		ms11 := newMilestone(11, 1000)
+		ms11.NextPoWScore, ms11.NextPoWScoreMilestoneIndex = 2000, 12
+		ms12 := newMilestone(12, 1010)
+
+		mockMilestone(t, ms10, prvKeys)
+		mockMilestone(t, ms11, prvKeys)
+		mockMilestone(t, ms12, prvKeys)
+		mockMilestoneNotFound(13)
+
+		var trustedCount int
+		var follower *iotago.MilestoneFollower
+		follower = iotago.NewMilestoneFollower(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), km, 10,
+			iotago.WithMilestoneFollowerOnTrusted(func(trusted *iotago.TrustedMilestone) {
+				trustedCount++
+				// the state is already updated and accessible from within the callback
+				require.Equal(t, trusted, follower.Latest())
+				_ = follower.PoWScore()
+			}),
+		)
+		latest, err := follower.Sync(context.Background())
+		require.NoError(t, err)
+		require.EqualValues(t, 12, latest.Milestone.Index)
+		require.Equal(t, 3, trustedCount)
+		require.EqualValues(t, 2000, follower.PoWScore())
+	})
+
+	t.Run("err - timestamp not monotonic", func(t *testing.T) {
+		defer gock.Off()
+
+		mockMilestone(t, newMilestone(10, 1000), prvKeys)
+		mockMilestone(t, newMilestone(11, 999), prvKeys)
+
+		follower := iotago.NewMilestoneFollower(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), km, 10)
+		latest, err := follower.Sync(context.Background())
+		require.True(t, errors.Is(err, iotago.ErrMilestoneFollowerTimestampNotMonotonic))
+		require.EqualValues(t, 10, latest.Milestone.Index)
+	})
+
+	t.Run("err - pending pow score transition dropped", func(t *testing.T) {
+		defer gock.Off()
+
+		ms10 := newMilestone(10, 1000)
+		ms10.NextPoWScore, ms10.NextPoWScoreMilestoneIndex = 2000, 15
+		mockMilestone(t, ms10, prvKeys)
+		mockMilestone(t, newMilestone(11, 1000), prvKeys)
+
+		follower := iotago.NewMilestoneFollower(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), km, 10)
+		_, err := follower.Sync(context.Background())
+		require.True(t, errors.Is(err, iotago.ErrMilestoneFollowerInvalidPoWScoreTransition))
+	})
+
+	t.Run("err - signed by unknown key", func(t *testing.T) {
+		defer gock.Off()
+
+		otherPubKey, otherPrvKey := randMilestoneKey()
+		ms, err := iotago.NewMilestone(10, 1000, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), []iotago.MilestonePublicKey{otherPubKey})
+		require.NoError(t, err)
+		mockMilestone(t, ms, iotago.MilestonePublicKeyMapping{otherPubKey: otherPrvKey})
+
+		follower := iotago.NewMilestoneFollower(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), km, 10)
+		latest, err := follower.Sync(context.Background())
+		require.True(t, errors.Is(err, iotago.ErrMilestoneNonApplicablePublicKey))
+		require.Nil(t, latest)
+	})
+}
@@ -0,0 +1,170 @@
+package iotago
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrMilestoneKeyRangeInvalid gets returned when a key range's end index is lower than its start index.
+	ErrMilestoneKeyRangeInvalid = errors.New("invalid milestone key range")
+	// ErrMilestoneKeyManagerNoApplicableKeys gets returned when no key range covers a given milestone index.
+	ErrMilestoneKeyManagerNoApplicableKeys = errors.New("no applicable milestone public keys")
+)
+
+// MilestoneKeyRange defines the milestone index range in which a public key is applicable.
+type MilestoneKeyRange struct {
+	// The public key.
+	PublicKey MilestonePublicKey
+	// The first milestone index at which the public key is applicable.
+	StartIndex uint32
+	// The last milestone index at which the public key is applicable. 0 means the key never expires.
+	EndIndex uint32
+}
+
+// IsApplicable tells whether the public key of the MilestoneKeyRange is applicable for the given milestone index.
+func (r *MilestoneKeyRange) IsApplicable(index uint32) bool {
+	return index >= r.StartIndex && (r.EndIndex == 0 || index <= r.EndIndex)
+}
+
+// MilestoneKeyManagerConfig is the JSON/YAML representation of a MilestoneKeyManager.
+type MilestoneKeyManagerConfig struct {
+	// The min. amount of valid signatures a milestone must hold.
+	Threshold int `json:"threshold" yaml:"threshold"`
+	// The key ranges.
+	KeyRanges []MilestoneKeyRangeConfig `json:"keyRanges" yaml:"keyRanges"`
+}
+
+// MilestoneKeyRangeConfig is the JSON/YAML representation of a MilestoneKeyRange.
+type MilestoneKeyRangeConfig struct {
+	// The hex encoded public key.
+	Key string `json:"key" yaml:"key"`
+	// The first milestone index at which the public key is applicable.
+	StartIndex uint32 `json:"start" yaml:"start"`
+	// The last milestone index at which the public key is applicable. 0 means the key never expires.
+	EndIndex uint32 `json:"end" yaml:"end"`
+}
+
+// NewMilestoneKeyManagerFromJSON creates a new MilestoneKeyManager from the given JSON encoded MilestoneKeyManagerConfig.
+func NewMilestoneKeyManagerFromJSON(data []byte) (*MilestoneKeyManager, error) {
+	config := &MilestoneKeyManagerConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("unable to parse milestone key manager JSON config: %w", err)
+	}
+	return NewMilestoneKeyManagerFromConfig(config)
+}
+
+// NewMilestoneKeyManagerFromYAML creates a new MilestoneKeyManager from the given YAML encoded MilestoneKeyManagerConfig.
+func NewMilestoneKeyManagerFromYAML(data []byte) (*MilestoneKeyManager, error) {
+	config := &MilestoneKeyManagerConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("unable to parse milestone key manager YAML config: %w", err)
+	}
+	return NewMilestoneKeyManagerFromConfig(config)
+}
+
+// NewMilestoneKeyManagerFromConfig creates a new MilestoneKeyManager from the given MilestoneKeyManagerConfig.
+func NewMilestoneKeyManagerFromConfig(config *MilestoneKeyManagerConfig) (*MilestoneKeyManager, error) {
+	km := NewMilestoneKeyManager(config.Threshold)
+	for i, keyRange := range config.KeyRanges {
+		pubKeyBytes, err := hex.DecodeString(keyRange.Key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode public key of key range at pos %d: %w", i, err)
+		}
+		if len(pubKeyBytes) != MilestonePublicKeyLength {
+			return nil, fmt.Errorf("%w: public key of key range at pos %d has length %d instead of %d", ErrMilestoneKeyRangeInvalid, i, len(pubKeyBytes), MilestonePublicKeyLength)
+		}
+		var pubKey MilestonePublicKey
+		copy(pubKey[:], pubKeyBytes)
+		if err := km.AddKeyRange(pubKey, keyRange.StartIndex, keyRange.EndIndex); err != nil {
+			return nil, fmt.Errorf("unable to add key range at pos %d: %w", i, err)
+		}
+	}
+	return km, nil
+}
+
+// NewMilestoneKeyManager creates a new empty MilestoneKeyManager requiring the given min. amount of signatures per milestone.
+func NewMilestoneKeyManager(threshold int) *MilestoneKeyManager {
+	return &MilestoneKeyManager{threshold: threshold}
+}
+
+// MilestoneKeyManager resolves the public keys applicable for a given milestone index
+// and verifies milestones against them.
+type MilestoneKeyManager struct {
+	mu        sync.RWMutex
+	threshold int
+	keyRanges []*MilestoneKeyRange
+}
+
+// AddKeyRange adds the given public key as applicable from startIndex up to (including) endIndex.
+// An endIndex of 0 means the key never expires.
+func (km *MilestoneKeyManager) AddKeyRange(pubKey MilestonePublicKey, startIndex uint32, endIndex uint32) error {
+	if endIndex != 0 && endIndex < startIndex {
+		return fmt.Errorf("%w: end index %d is lower than start index %d", ErrMilestoneKeyRangeInvalid, endIndex, startIndex)
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	km.keyRanges = append(km.keyRanges, &MilestoneKeyRange{PublicKey: pubKey, StartIndex: startIndex, EndIndex: endIndex})
+	sort.SliceStable(km.keyRanges, func(i, j int) bool {
+		return km.keyRanges[i].StartIndex < km.keyRanges[j].StartIndex
+	})
+	return nil
+}
+
+// KeyRanges returns a copy of the key ranges of the MilestoneKeyManager ordered by their start index.
+func (km *MilestoneKeyManager) KeyRanges() []MilestoneKeyRange {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	keyRanges := make([]MilestoneKeyRange, len(km.keyRanges))
+	for i, keyRange := range km.keyRanges {
+		keyRanges[i] = *keyRange
+	}
+	return keyRanges
+}
+
+// Threshold returns the min. amount of valid signatures a milestone must hold.
+func (km *MilestoneKeyManager) Threshold() int {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return km.threshold
+}
+
+// ApplicablePublicKeys returns the set of public keys applicable for the given milestone index
+// and the min. amount of signatures a milestone with that index must hold.
+func (km *MilestoneKeyManager) ApplicablePublicKeys(index uint32) (MilestonePublicKeySet, int, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	pubKeySet := make(MilestonePublicKeySet)
+	for _, keyRange := range km.keyRanges {
+		if keyRange.StartIndex > index {
+			break
+		}
+		if keyRange.IsApplicable(index) {
+			pubKeySet[keyRange.PublicKey] = struct{}{}
+		}
+	}
+
+	if len(pubKeySet) == 0 {
+		return nil, 0, fmt.Errorf("%w: milestone index %d", ErrMilestoneKeyManagerNoApplicableKeys, index)
+	}
+	return pubKeySet, km.threshold, nil
+}
+
+// VerifyMilestone verifies the signatures of the given Milestone against the public keys applicable for its index.
+func (km *MilestoneKeyManager) VerifyMilestone(ms *Milestone) error {
+	pubKeySet, threshold, err := km.ApplicablePublicKeys(ms.Index)
+	if err != nil {
+		return err
+	}
+	return ms.VerifySignatures(threshold, pubKeySet)
+}
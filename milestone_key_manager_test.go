@@ -0,0 +1,102 @@
+package iotago_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+func randMilestoneKey() (iotago.MilestonePublicKey, ed25519.PrivateKey) {
+	prvKey := tpkg.RandEd25519PrivateKey()
+	var pubKey iotago.MilestonePublicKey
+	copy(pubKey[:], prvKey.Public().(ed25519.PublicKey))
+	return pubKey, prvKey
+}
+
+func signedMilestone(t *testing.T, index uint32, prvKeys iotago.MilestonePublicKeyMapping) *iotago.Milestone {
+	pubKeys := make([]iotago.MilestonePublicKey, 0, len(prvKeys))
+	for pubKey := range prvKeys {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	ms, err := iotago.NewMilestone(index, 1337, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), pubKeys)
+	require.NoError(t, err)
+	require.NoError(t, ms.Sign(iotago.InMemoryEd25519MilestoneSigner(prvKeys)))
+	return ms
+}
+
+func TestMilestoneKeyManager(t *testing.T) {
+	pubKey1, prvKey1 := randMilestoneKey()
+	pubKey2, prvKey2 := randMilestoneKey()
+	pubKey3, prvKey3 := randMilestoneKey()
+
+	jsonConfig := fmt.Sprintf(`{"threshold": 2, "keyRanges": [
+		{"key": "%s", "start": 0, "end": 100},
+		{"key": "%s", "start": 0, "end": 0},
+		{"key": "%s", "start": 90, "end": 0}
+	]}`, hex.EncodeToString(pubKey1[:]), hex.EncodeToString(pubKey2[:]), hex.EncodeToString(pubKey3[:]))
+
+	yamlConfig := fmt.Sprintf(`threshold: 2
+keyRanges:
+  - key: "%s"
+    start: 0
+    end: 100
+  - key: "%s"
+    start: 0
+  - key: "%s"
+    start: 90
+`, hex.EncodeToString(pubKey1[:]), hex.EncodeToString(pubKey2[:]), hex.EncodeToString(pubKey3[:]))
+
+	fromJSON, err := iotago.NewMilestoneKeyManagerFromJSON([]byte(jsonConfig))
+	require.NoError(t, err)
+	fromYAML, err := iotago.NewMilestoneKeyManagerFromYAML([]byte(yamlConfig))
+	require.NoError(t, err)
+	require.Equal(t, fromJSON.KeyRanges(), fromYAML.KeyRanges())
+
+	km := fromJSON
+
+	pubKeys, threshold, err := km.ApplicablePublicKeys(50)
+	require.NoError(t, err)
+	assert.Equal(t, 2, threshold)
+	assert.Equal(t, iotago.MilestonePublicKeySet{pubKey1: {}, pubKey2: {}}, pubKeys)
+
+	pubKeys, _, err = km.ApplicablePublicKeys(95)
+	require.NoError(t, err)
+	assert.Equal(t, iotago.MilestonePublicKeySet{pubKey1: {}, pubKey2: {}, pubKey3: {}}, pubKeys)
+
+	pubKeys, _, err = km.ApplicablePublicKeys(101)
+	require.NoError(t, err)
+	assert.Equal(t, iotago.MilestonePublicKeySet{pubKey2: {}, pubKey3: {}}, pubKeys)
+
+	require.NoError(t, km.VerifyMilestone(signedMilestone(t, 50, iotago.MilestonePublicKeyMapping{pubKey1: prvKey1, pubKey2: prvKey2})))
+	require.NoError(t, km.VerifyMilestone(signedMilestone(t, 200, iotago.MilestonePublicKeyMapping{pubKey2: prvKey2, pubKey3: prvKey3})))
+
+	// pubKey1 expired at 100
+	err = km.VerifyMilestone(signedMilestone(t, 200, iotago.MilestonePublicKeyMapping{pubKey1: prvKey1, pubKey2: prvKey2}))
+	assert.True(t, errors.Is(err, iotago.ErrMilestoneNonApplicablePublicKey))
+
+	// below the threshold
+	err = km.VerifyMilestone(signedMilestone(t, 50, iotago.MilestonePublicKeyMapping{pubKey1: prvKey1}))
+	assert.True(t, errors.Is(err, iotago.ErrMilestoneTooFewSignaturesForVerificationThreshold))
+}
+
+func TestMilestoneKeyManager_Errors(t *testing.T) {
+	pubKey, _ := randMilestoneKey()
+
+	km := iotago.NewMilestoneKeyManager(1)
+	assert.True(t, errors.Is(km.AddKeyRange(pubKey, 10, 5), iotago.ErrMilestoneKeyRangeInvalid))
+
+	require.NoError(t, km.AddKeyRange(pubKey, 10, 0))
+	_, _, err := km.ApplicablePublicKeys(9)
+	assert.True(t, errors.Is(err, iotago.ErrMilestoneKeyManagerNoApplicableKeys))
+
+	_, err = iotago.NewMilestoneKeyManagerFromJSON([]byte(`{"threshold": 1, "keyRanges": [{"key": "abcd"}]}`))
+	assert.True(t, errors.Is(err, iotago.ErrMilestoneKeyRangeInvalid))
+}
@@ -0,0 +1,378 @@
+package iotago
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/remotesigner"
+)
+
+var (
+	// ErrMilestoneSigningThresholdNotReached gets returned when less than the threshold of public keys produced a valid signature.
+	ErrMilestoneSigningThresholdNotReached = errors.New("milestone signing threshold not reached")
+	// ErrMilestoneSigningCoordinatorInvalidThreshold gets returned when the threshold of a MilestoneSigningCoordinator is invalid.
+	ErrMilestoneSigningCoordinatorInvalidThreshold = errors.New("invalid milestone signing threshold")
+	// ErrMilestoneSigningCoordinatorNoTransportSecurity gets returned when a MilestoneSigningCoordinator is created
+	// without a TLS config and without custom dial options.
+	ErrMilestoneSigningCoordinatorNoTransportSecurity = errors.New("no transport security configured")
+)
+
+// MilestoneSignerEndpoint is a remote SignatureDispatcher holding the private keys of the given public keys.
+type MilestoneSignerEndpoint struct {
+	// The address of the remote SignatureDispatcher.
+	Address string
+	// The public keys the remote SignatureDispatcher can sign for.
+	PublicKeys []MilestonePublicKey
+}
+
+// MilestoneSigningCoordinatorOption is a function setting a MilestoneSigningCoordinator option.
+type MilestoneSigningCoordinatorOption func(opts *MilestoneSigningCoordinatorOptions)
+
+// MilestoneSigningCoordinatorOptions define options for the MilestoneSigningCoordinator.
+type MilestoneSigningCoordinatorOptions struct {
+	// The TLS config used to connect to the endpoints.
+	tlsConfig *tls.Config
+	// Additional dial options used to connect to the endpoints.
+	dialOpts []grpc.DialOption
+	// The deadline of a single call to an endpoint.
+	callTimeout time.Duration
+	// The max. amount of retries per endpoint.
+	maxRetries int
+	// The time to wait between retries.
+	retryBackoff time.Duration
+}
+
+// applies the given MilestoneSigningCoordinatorOption.
+func (mco *MilestoneSigningCoordinatorOptions) apply(opts ...MilestoneSigningCoordinatorOption) {
+	for _, opt := range opts {
+		opt(mco)
+	}
+}
+
+// WithMilestoneSigningCoordinatorTLSConfig sets the TLS config used to connect to the endpoints.
+// The config should contain a client certificate as the endpoints are expected to employ mutual TLS.
+func WithMilestoneSigningCoordinatorTLSConfig(tlsConfig *tls.Config) MilestoneSigningCoordinatorOption {
+	return func(opts *MilestoneSigningCoordinatorOptions) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// WithMilestoneSigningCoordinatorDialOptions sets additional dial options used to connect to the endpoints.
+// If no TLS config is set, the dial options must define the transport credentials.
+func WithMilestoneSigningCoordinatorDialOptions(dialOpts ...grpc.DialOption) MilestoneSigningCoordinatorOption {
+	return func(opts *MilestoneSigningCoordinatorOptions) {
+		opts.dialOpts = dialOpts
+	}
+}
+
+// WithMilestoneSigningCoordinatorCallTimeout sets the deadline of a single call to an endpoint.
+func WithMilestoneSigningCoordinatorCallTimeout(timeout time.Duration) MilestoneSigningCoordinatorOption {
+	return func(opts *MilestoneSigningCoordinatorOptions) {
+		opts.callTimeout = timeout
+	}
+}
+
+// WithMilestoneSigningCoordinatorRetries sets the max. amount of retries per endpoint and the time to wait between them.
+func WithMilestoneSigningCoordinatorRetries(maxRetries int, backoff time.Duration) MilestoneSigningCoordinatorOption {
+	return func(opts *MilestoneSigningCoordinatorOptions) {
+		opts.maxRetries = maxRetries
+		opts.retryBackoff = backoff
+	}
+}
+
+// the default options applied to the MilestoneSigningCoordinator.
+var defaultMilestoneSigningCoordinatorOptions = []MilestoneSigningCoordinatorOption{
+	WithMilestoneSigningCoordinatorCallTimeout(10 * time.Second),
+	WithMilestoneSigningCoordinatorRetries(3, 500*time.Millisecond),
+}
+
+// LoadMilestoneSigningMutualTLSConfig loads a TLS config for mutual authentication from the given
+// PEM encoded CA certificate, client certificate and client key files.
+func LoadMilestoneSigningMutualTLSConfig(caCertFile string, certFile string, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load client certificate: %w", err)
+	}
+
+	caCert, err := ioutil.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA certificate: %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("unable to parse CA certificate %s", caCertFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caPool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// NewMilestoneSigningCoordinator creates a new MilestoneSigningCoordinator which requires valid signatures
+// of at least threshold public keys. Connections to the endpoints are established lazily.
+func NewMilestoneSigningCoordinator(endpoints []*MilestoneSignerEndpoint, threshold int, opts ...MilestoneSigningCoordinatorOption) (*MilestoneSigningCoordinator, error) {
+	options := &MilestoneSigningCoordinatorOptions{}
+	options.apply(defaultMilestoneSigningCoordinatorOptions...)
+	options.apply(opts...)
+
+	if threshold < MinSignaturesInAMilestone {
+		return nil, fmt.Errorf("%w: %d", ErrMilestoneSigningCoordinatorInvalidThreshold, threshold)
+	}
+
+	dialOpts := options.dialOpts
+	switch {
+	case options.tlsConfig != nil:
+		dialOpts = append([]grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(options.tlsConfig))}, dialOpts...)
+	case len(dialOpts) == 0:
+		return nil, ErrMilestoneSigningCoordinatorNoTransportSecurity
+	}
+
+	c := &MilestoneSigningCoordinator{opts: options, threshold: threshold}
+	for _, endpoint := range endpoints {
+		conn, err := grpc.Dial(endpoint.Address, dialOpts...)
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("unable to dial signer endpoint %s: %w", endpoint.Address, err)
+		}
+		c.conns = append(c.conns, conn)
+		c.endpoints = append(c.endpoints, &coordinatedEndpoint{
+			MilestoneSignerEndpoint: endpoint,
+			client:                  remotesigner.NewSignatureDispatcherClient(conn),
+		})
+	}
+	return c, nil
+}
+
+// an endpoint together with its client.
+type coordinatedEndpoint struct {
+	*MilestoneSignerEndpoint
+	client remotesigner.SignatureDispatcherClient
+}
+
+// MilestoneSigningCoordinator fans out signing requests to several independent SignatureDispatcher endpoints
+// and collects the signatures per public key. Every returned signature is verified against the milestone essence
+// before it is accepted.
+type MilestoneSigningCoordinator struct {
+	opts      *MilestoneSigningCoordinatorOptions
+	threshold int
+	endpoints []*coordinatedEndpoint
+	conns     []*grpc.ClientConn
+}
+
+// Close closes the connections to all endpoints.
+func (c *MilestoneSigningCoordinator) Close() error {
+	var firstErr error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SignMilestone signs the given Milestone with the public keys it holds. If not all public keys produce a valid
+// signature but the threshold is still met, the public keys without a signature are removed from the Milestone
+// and the changed essence is signed again by the remaining ones.
+func (c *MilestoneSigningCoordinator) SignMilestone(ctx context.Context, ms *Milestone) error {
+	for {
+		msEssenceBytes, err := ms.EssenceBytes()
+		if err != nil {
+			return fmt.Errorf("unable to compute milestone essence for signing: %w", err)
+		}
+		msEssence := blake2b.Sum256(msEssenceBytes)
+
+		sigs, err := c.collect(ctx, ms.PublicKeys, msEssence[:], msEssenceBytes)
+		if err != nil {
+			return err
+		}
+
+		if len(sigs) == len(ms.PublicKeys) {
+			ms.Signatures = make([]MilestoneSignature, len(ms.PublicKeys))
+			for i, pubKey := range ms.PublicKeys {
+				ms.Signatures[i] = sigs[pubKey]
+			}
+			return nil
+		}
+
+		signedPubKeys := make([]MilestonePublicKey, 0, len(sigs))
+		for _, pubKey := range ms.PublicKeys {
+			if _, has := sigs[pubKey]; has {
+				signedPubKeys = append(signedPubKeys, pubKey)
+			}
+		}
+		ms.PublicKeys = signedPubKeys
+		ms.Signatures = nil
+	}
+}
+
+// SigningFunc returns a MilestoneSigningFunc which collects the signatures using the given context.
+// As a Milestone must hold a signature for each of its public keys, the returned function fails
+// if not all public keys produce a valid signature. Since a MilestoneSigningFunc only receives the hash
+// of the essence, endpoints which need to inspect the milestone before signing must be used via SignMilestone.
+func (c *MilestoneSigningCoordinator) SigningFunc(ctx context.Context) MilestoneSigningFunc {
+	return func(pubKeys []MilestonePublicKey, msEssence []byte) ([]MilestoneSignature, error) {
+		sigs, err := c.collect(ctx, pubKeys, msEssence, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(sigs) != len(pubKeys) {
+			return nil, fmt.Errorf("%w: wanted %d signatures but only collected %d", ErrMilestoneProducedSignaturesCountMismatch, len(pubKeys), len(sigs))
+		}
+		ordered := make([]MilestoneSignature, len(pubKeys))
+		for i, pubKey := range pubKeys {
+			ordered[i] = sigs[pubKey]
+		}
+		return ordered, nil
+	}
+}
+
+// Collect requests signatures for the given public keys over the given essence from all endpoints concurrently
+// and returns the valid signatures per public key. It returns early once every public key has a valid signature
+// and fails if less than the threshold of public keys produced a valid signature.
+func (c *MilestoneSigningCoordinator) Collect(ctx context.Context, pubKeys []MilestonePublicKey, msEssence []byte) (map[MilestonePublicKey]MilestoneSignature, error) {
+	return c.collect(ctx, pubKeys, msEssence, nil)
+}
+
+// collects the signatures, passing the given serialized essence as metadata to the endpoints if it isn't nil.
+func (c *MilestoneSigningCoordinator) collect(ctx context.Context, pubKeys []MilestonePublicKey, msEssence []byte, msEssenceBytes []byte) (map[MilestonePublicKey]MilestoneSignature, error) {
+	if msEssenceBytes != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx, remotesigner.MilestoneEssenceMetadataKey, string(msEssenceBytes))
+	}
+
+	wanted := make(map[MilestonePublicKey]struct{}, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		wanted[pubKey] = struct{}{}
+	}
+
+	collectCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sigs = make(map[MilestonePublicKey]MilestoneSignature)
+		errs []error
+	)
+
+	for _, endpoint := range c.endpoints {
+		var endpointPubKeys []MilestonePublicKey
+		for _, pubKey := range endpoint.PublicKeys {
+			if _, has := wanted[pubKey]; has {
+				endpointPubKeys = append(endpointPubKeys, pubKey)
+			}
+		}
+		if len(endpointPubKeys) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(endpoint *coordinatedEndpoint, endpointPubKeys []MilestonePublicKey) {
+			defer wg.Done()
+
+			endpointSigs, err := c.requestSignatures(collectCtx, endpoint, endpointPubKeys, msEssence)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for pubKey, sig := range endpointSigs {
+				sigs[pubKey] = sig
+			}
+			if len(sigs) == len(wanted) {
+				cancel()
+			}
+		}(endpoint, endpointPubKeys)
+	}
+	wg.Wait()
+
+	if len(sigs) < c.threshold {
+		return nil, fmt.Errorf("%w: collected %d of min. %d signatures, errors: %v", ErrMilestoneSigningThresholdNotReached, len(sigs), c.threshold, errs)
+	}
+	return sigs, nil
+}
+
+// requests signatures from the given endpoint, retrying on transient errors, and returns the valid ones.
+func (c *MilestoneSigningCoordinator) requestSignatures(ctx context.Context, endpoint *coordinatedEndpoint, pubKeys []MilestonePublicKey, msEssence []byte) (map[MilestonePublicKey]MilestoneSignature, error) {
+	// the public keys must be in lexical order as they are within a milestone
+	sort.Slice(pubKeys, func(i, j int) bool {
+		return bytes.Compare(pubKeys[i][:], pubKeys[j][:]) < 0
+	})
+
+	req := &remotesigner.SignMilestoneRequest{PubKeys: make([][]byte, len(pubKeys)), MsEssence: msEssence}
+	for i := range pubKeys {
+		req.PubKeys[i] = append([]byte{}, pubKeys[i][:]...)
+	}
+
+	var res *remotesigner.SignMilestoneResponse
+	var err error
+	for attempt := 0; attempt <= c.opts.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("signer endpoint %s: %w", endpoint.Address, ctx.Err())
+			case <-time.After(c.opts.retryBackoff):
+			}
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, c.opts.callTimeout)
+		res, err = endpoint.client.SignMilestone(callCtx, req)
+		cancel()
+		if err == nil || !isRetriableSigningError(err) || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signer endpoint %s: %w", endpoint.Address, err)
+	}
+
+	resSigs := res.GetSignatures()
+	if len(resSigs) != len(pubKeys) {
+		return nil, fmt.Errorf("%w: signer endpoint %s returned %d signatures for %d public keys", ErrMilestoneProducedSignaturesCountMismatch, endpoint.Address, len(resSigs), len(pubKeys))
+	}
+
+	sigs := make(map[MilestonePublicKey]MilestoneSignature, len(pubKeys))
+	var invalid []string
+	for i, pubKey := range pubKeys {
+		if len(resSigs[i]) != MilestoneSignatureLength || !ed25519.Verify(pubKey[:], msEssence, resSigs[i]) {
+			invalid = append(invalid, hex.EncodeToString(pubKey[:]))
+			continue
+		}
+		var sig MilestoneSignature
+		copy(sig[:], resSigs[i])
+		sigs[pubKey] = sig
+	}
+	if len(invalid) > 0 {
+		return sigs, fmt.Errorf("%w: signer endpoint %s returned invalid signatures for public keys %v", ErrMilestoneInvalidSignature, endpoint.Address, invalid)
+	}
+	return sigs, nil
+}
+
+// tells whether the given gRPC error is transient.
+func isRetriableSigningError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,167 @@
+package iotago_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/remotesigner"
+	"github.com/iotaledger/iota.go/v2/remotesigner/dispatcher"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+// a SignatureDispatcherServer signing with in-memory keys which can be told to misbehave.
+type testSignatureDispatcher struct {
+	remotesigner.UnimplementedSignatureDispatcherServer
+	prvKeys      iotago.MilestonePublicKeyMapping
+	failFirst    int32
+	invalidSigs  bool
+	requestCount int32
+}
+
+func (s *testSignatureDispatcher) SignMilestone(_ context.Context, req *remotesigner.SignMilestoneRequest) (*remotesigner.SignMilestoneResponse, error) {
+	if atomic.AddInt32(&s.requestCount, 1) <= s.failFirst {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+	res := &remotesigner.SignMilestoneResponse{}
+	for _, pubKeyBytes := range req.GetPubKeys() {
+		var pubKey iotago.MilestonePublicKey
+		copy(pubKey[:], pubKeyBytes)
+		prvKey, ok := s.prvKeys[pubKey]
+		if !ok {
+			return nil, status.Error(codes.NotFound, "unknown public key")
+		}
+		sig := ed25519.Sign(prvKey, req.GetMsEssence())
+		if s.invalidSigs {
+			sig[0] ^= 0xff
+		}
+		res.Signatures = append(res.Signatures, sig)
+	}
+	return res, nil
+}
+
+// starts the given SignatureDispatcherServer on an in-memory listener.
+func startTestSignatureDispatcher(t *testing.T, srv remotesigner.SignatureDispatcherServer) *bufconn.Listener {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	remotesigner.RegisterSignatureDispatcherServer(grpcServer, srv)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+	return lis
+}
+
+// returns a dial option which connects to the in-memory listener registered for the dialed address.
+func bufconnDialer(listeners map[string]*bufconn.Listener) grpc.DialOption {
+	return grpc.WithContextDialer(func(_ context.Context, addr string) (net.Conn, error) {
+		return listeners[addr].Dial()
+	})
+}
+
+func TestMilestoneSigningCoordinator_SignMilestone(t *testing.T) {
+	pubKey1, prvKey1 := randMilestoneKey()
+	pubKey2, prvKey2 := randMilestoneKey()
+
+	newCoordinator := func(t *testing.T, dispatcher1 *testSignatureDispatcher, dispatcher2 *testSignatureDispatcher, threshold int) *iotago.MilestoneSigningCoordinator {
+		listeners := map[string]*bufconn.Listener{
+			"signer1": startTestSignatureDispatcher(t, dispatcher1),
+			"signer2": startTestSignatureDispatcher(t, dispatcher2),
+		}
+
+		c, err := iotago.NewMilestoneSigningCoordinator([]*iotago.MilestoneSignerEndpoint{
+			{Address: "signer1", PublicKeys: []iotago.MilestonePublicKey{pubKey1}},
+			{Address: "signer2", PublicKeys: []iotago.MilestonePublicKey{pubKey2}},
+		}, threshold,
+			iotago.WithMilestoneSigningCoordinatorDialOptions(grpc.WithInsecure(), bufconnDialer(listeners)),
+			iotago.WithMilestoneSigningCoordinatorRetries(2, time.Millisecond),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	}
+
+	newMilestone := func(t *testing.T) *iotago.Milestone {
+		ms, err := iotago.NewMilestone(1000, 1337, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), []iotago.MilestonePublicKey{pubKey1, pubKey2})
+		require.NoError(t, err)
+		return ms
+	}
+
+	t.Run("ok - all keys sign, with retries", func(t *testing.T) {
+		c := newCoordinator(t,
+			&testSignatureDispatcher{prvKeys: iotago.MilestonePublicKeyMapping{pubKey1: prvKey1}, failFirst: 2},
+			&testSignatureDispatcher{prvKeys: iotago.MilestonePublicKeyMapping{pubKey2: prvKey2}},
+			2,
+		)
+		ms := newMilestone(t)
+		require.NoError(t, c.SignMilestone(context.Background(), ms))
+		require.NoError(t, ms.VerifySignatures(2, iotago.MilestonePublicKeySet{pubKey1: {}, pubKey2: {}}))
+	})
+
+	t.Run("ok - invalid signatures are dropped while the threshold is met", func(t *testing.T) {
+		c := newCoordinator(t,
+			&testSignatureDispatcher{prvKeys: iotago.MilestonePublicKeyMapping{pubKey1: prvKey1}},
+			&testSignatureDispatcher{prvKeys: iotago.MilestonePublicKeyMapping{pubKey2: prvKey2}, invalidSigs: true},
+			1,
+		)
+		ms := newMilestone(t)
+		require.NoError(t, c.SignMilestone(context.Background(), ms))
+		require.Equal(t, []iotago.MilestonePublicKey{pubKey1}, ms.PublicKeys)
+		require.NoError(t, ms.VerifySignatures(1, iotago.MilestonePublicKeySet{pubKey1: {}, pubKey2: {}}))
+	})
+
+	t.Run("err - threshold not reached", func(t *testing.T) {
+		c := newCoordinator(t,
+			&testSignatureDispatcher{prvKeys: iotago.MilestonePublicKeyMapping{pubKey1: prvKey1}, failFirst: 10},
+			&testSignatureDispatcher{prvKeys: iotago.MilestonePublicKeyMapping{pubKey2: prvKey2}},
+			2,
+		)
+		err := c.SignMilestone(context.Background(), newMilestone(t))
+		require.True(t, errors.Is(err, iotago.ErrMilestoneSigningThresholdNotReached))
+	})
+}
+
+func TestMilestoneSigningCoordinator_ReferenceDispatcher(t *testing.T) {
+	pubKey1, prvKey1 := randMilestoneKey()
+	pubKey2, prvKey2 := randMilestoneKey()
+
+	srv1, err := dispatcher.NewServer(dispatcher.NewInMemoryKeyStore(prvKey1))
+	require.NoError(t, err)
+	srv2, err := dispatcher.NewServer(dispatcher.NewInMemoryKeyStore(prvKey2))
+	require.NoError(t, err)
+	listeners := map[string]*bufconn.Listener{
+		"signer1": startTestSignatureDispatcher(t, srv1),
+		"signer2": startTestSignatureDispatcher(t, srv2),
+	}
+
+	c, err := iotago.NewMilestoneSigningCoordinator([]*iotago.MilestoneSignerEndpoint{
+		{Address: "signer1", PublicKeys: []iotago.MilestonePublicKey{pubKey1}},
+		{Address: "signer2", PublicKeys: []iotago.MilestonePublicKey{pubKey2}},
+	}, 2, iotago.WithMilestoneSigningCoordinatorDialOptions(grpc.WithInsecure(), bufconnDialer(listeners)))
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	ms, err := iotago.NewMilestone(1000, 1337, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), []iotago.MilestonePublicKey{pubKey1, pubKey2})
+	require.NoError(t, err)
+	require.NoError(t, c.SignMilestone(context.Background(), ms))
+	require.NoError(t, ms.VerifySignatures(2, iotago.MilestonePublicKeySet{pubKey1: {}, pubKey2: {}}))
+
+	// a conflicting milestone with the same index is refused
+	conflicting, err := iotago.NewMilestone(1000, 1337, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), []iotago.MilestonePublicKey{pubKey1, pubKey2})
+	require.NoError(t, err)
+	require.True(t, errors.Is(c.SignMilestone(context.Background(), conflicting), iotago.ErrMilestoneSigningThresholdNotReached))
+}
+
+func TestNewMilestoneSigningCoordinator_NoTransportSecurity(t *testing.T) {
+	_, err := iotago.NewMilestoneSigningCoordinator(nil, 1)
+	require.True(t, errors.Is(err, iotago.ErrMilestoneSigningCoordinatorNoTransportSecurity))
+}
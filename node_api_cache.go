@@ -0,0 +1,290 @@
+package iotago
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultNodeAPICacheSize is the default max. amount of responses a NodeAPICache holds.
+	DefaultNodeAPICacheSize = 10000
+	// DefaultNodeAPICacheMutableTTL is the default duration a NodeAPICache holds responses which might still change.
+	DefaultNodeAPICacheMutableTTL = 5 * time.Second
+)
+
+// NodeAPICacheOption is a function setting a NodeAPICache option.
+type NodeAPICacheOption func(opts *NodeAPICacheOptions)
+
+// NodeAPICacheOptions define options for the NodeAPICache.
+type NodeAPICacheOptions struct {
+	// The max. amount of cached responses.
+	size int
+	// The duration responses which might still change are cached for.
+	mutableTTL time.Duration
+}
+
+// applies the given NodeAPICacheOption.
+func (co *NodeAPICacheOptions) apply(opts ...NodeAPICacheOption) {
+	for _, opt := range opts {
+		opt(co)
+	}
+}
+
+// WithNodeAPICacheSize defines the max. amount of responses the cache holds before evicting the least recently used ones.
+func WithNodeAPICacheSize(size int) NodeAPICacheOption {
+	return func(opts *NodeAPICacheOptions) {
+		opts.size = size
+	}
+}
+
+// WithNodeAPICacheMutableTTL defines the duration for which responses which might still change are cached,
+// i.e. the metadata of not yet referenced messages and unspent outputs. A TTL of 0 disables caching them.
+func WithNodeAPICacheMutableTTL(ttl time.Duration) NodeAPICacheOption {
+	return func(opts *NodeAPICacheOptions) {
+		opts.mutableTTL = ttl
+	}
+}
+
+// the default options applied to the NodeAPICache.
+var defaultNodeAPICacheOptions = []NodeAPICacheOption{
+	WithNodeAPICacheSize(DefaultNodeAPICacheSize),
+	WithNodeAPICacheMutableTTL(DefaultNodeAPICacheMutableTTL),
+}
+
+// the keys of the cached responses.
+type (
+	messageCacheKey              MessageID
+	messageMetadataCacheKey      MessageID
+	milestoneCacheKey            uint32
+	milestoneUTXOChangesCacheKey uint32
+	outputCacheKey               UTXOInputID
+)
+
+// an entry of the cache.
+type nodeAPICacheEntry struct {
+	key   interface{}
+	value interface{}
+	// the time after which the entry is stale, zero if it never is.
+	expiresAt time.Time
+}
+
+// NodeAPICacheStats are the statistics of a NodeAPICache.
+type NodeAPICacheStats struct {
+	// The amount of responses served from the cache.
+	Hits uint64
+	// The amount of responses which had to be queried.
+	Misses uint64
+	// The amount of responses currently held.
+	Size int
+}
+
+// NewNodeAPICache creates a new NodeAPICache caching the responses of the given NodeAPI.
+func NewNodeAPICache(nodeAPI NodeAPI, opts ...NodeAPICacheOption) *NodeAPICache {
+	options := &NodeAPICacheOptions{}
+	options.apply(defaultNodeAPICacheOptions...)
+	options.apply(opts...)
+
+	return &NodeAPICache{
+		NodeAPI: nodeAPI,
+		opts:    options,
+		lru:     list.New(),
+		entries: make(map[interface{}]*list.Element),
+	}
+}
+
+// NodeAPICache is a NodeAPI which caches responses of the wrapped NodeAPI in a size-bounded LRU cache.
+// Immutable data, being messages, milestones, their UTXO changes, the metadata of referenced messages and spent outputs,
+// is cached until evicted, while the metadata of not yet referenced messages and unspent outputs is only cached
+// for the configured mutable TTL. All other calls are passed through to the wrapped NodeAPI.
+// The returned responses are shared between callers and must not be modified.
+type NodeAPICache struct {
+	NodeAPI
+	opts *NodeAPICacheOptions
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[interface{}]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+var _ NodeAPI = &NodeAPICache{}
+
+// returns the cached value for the given key.
+func (c *NodeAPICache) get(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, has := c.entries[key]
+	if !has {
+		c.misses++
+		return nil, false
+	}
+
+	entry := elem.Value.(*nodeAPICacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits++
+	return entry.value, true
+}
+
+// caches the given value, immutable values never expire while mutable ones expire after the mutable TTL.
+func (c *NodeAPICache) put(key interface{}, value interface{}, immutable bool) {
+	if c.opts.size <= 0 || (!immutable && c.opts.mutableTTL <= 0) {
+		return
+	}
+
+	entry := &nodeAPICacheEntry{key: key, value: value}
+	if !immutable {
+		entry.expiresAt = time.Now().Add(c.opts.mutableTTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, has := c.entries[key]; has {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.opts.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*nodeAPICacheEntry).key)
+	}
+}
+
+// Stats returns the statistics of the cache.
+func (c *NodeAPICache) Stats() NodeAPICacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return NodeAPICacheStats{Hits: c.hits, Misses: c.misses, Size: c.lru.Len()}
+}
+
+// Purge removes all cached responses.
+func (c *NodeAPICache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[interface{}]*list.Element)
+}
+
+// MessageByMessageID gets a message by its ID, the message is shared with MessageJSONByMessageID.
+func (c *NodeAPICache) MessageByMessageID(ctx context.Context, msgID MessageID) (*Message, error) {
+	if msg, has := c.get(messageCacheKey(msgID)); has {
+		return msg.(*Message), nil
+	}
+	msg, err := c.NodeAPI.MessageByMessageID(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+	c.put(messageCacheKey(msgID), msg, true)
+	return msg, nil
+}
+
+// MessageJSONByMessageID gets a message by its ID, the message is shared with MessageByMessageID.
+func (c *NodeAPICache) MessageJSONByMessageID(ctx context.Context, msgID MessageID) (*Message, error) {
+	if msg, has := c.get(messageCacheKey(msgID)); has {
+		return msg.(*Message), nil
+	}
+	msg, err := c.NodeAPI.MessageJSONByMessageID(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+	c.put(messageCacheKey(msgID), msg, true)
+	return msg, nil
+}
+
+// MessageMetadataByMessageID gets the metadata of a message by its ID.
+// The metadata is immutable once the message got referenced by a milestone.
+func (c *NodeAPICache) MessageMetadataByMessageID(ctx context.Context, msgID MessageID) (*MessageMetadataResponse, error) {
+	if metadata, has := c.get(messageMetadataCacheKey(msgID)); has {
+		return metadata.(*MessageMetadataResponse), nil
+	}
+	metadata, err := c.NodeAPI.MessageMetadataByMessageID(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+	c.put(messageMetadataCacheKey(msgID), metadata, metadata.ReferencedByMilestoneIndex != nil)
+	return metadata, nil
+}
+
+// MilestoneByIndex gets a milestone by its index.
+func (c *NodeAPICache) MilestoneByIndex(ctx context.Context, index uint32) (*MilestoneResponse, error) {
+	if milestone, has := c.get(milestoneCacheKey(index)); has {
+		return milestone.(*MilestoneResponse), nil
+	}
+	milestone, err := c.NodeAPI.MilestoneByIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	c.put(milestoneCacheKey(index), milestone, true)
+	return milestone, nil
+}
+
+// MilestoneUTXOChangesByIndex returns all UTXO changes of a milestone by its index.
+func (c *NodeAPICache) MilestoneUTXOChangesByIndex(ctx context.Context, index uint32) (*MilestoneUTXOChangesResponse, error) {
+	if changes, has := c.get(milestoneUTXOChangesCacheKey(index)); has {
+		return changes.(*MilestoneUTXOChangesResponse), nil
+	}
+	changes, err := c.NodeAPI.MilestoneUTXOChangesByIndex(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	c.put(milestoneUTXOChangesCacheKey(index), changes, true)
+	return changes, nil
+}
+
+// OutputByID gets an output by its ID. The output is immutable once it is spent.
+func (c *NodeAPICache) OutputByID(ctx context.Context, utxoID UTXOInputID) (*NodeOutputResponse, error) {
+	if output, has := c.get(outputCacheKey(utxoID)); has {
+		return output.(*NodeOutputResponse), nil
+	}
+	output, err := c.NodeAPI.OutputByID(ctx, utxoID)
+	if err != nil {
+		return nil, err
+	}
+	c.put(outputCacheKey(utxoID), output, output.Spent)
+	return output, nil
+}
+
+// OutputsByIDs gets the outputs with the given IDs, serving the cached ones from the cache.
+func (c *NodeAPICache) OutputsByIDs(ctx context.Context, outputIDs []OutputIDHex) (map[*UTXOInput]Output, error) {
+	return fetchOutputs(ctx, c, outputIDs, DefaultOutputFetchConcurrency)
+}
+
+// OutputsByBech32Address gets the outputs residing on the given Bech32 address, serving the cached ones from the cache.
+func (c *NodeAPICache) OutputsByBech32Address(ctx context.Context, bech32Addr string, includeSpentOutputs bool) (*AddressOutputsResponse, map[*UTXOInput]Output, error) {
+	res, err := c.NodeAPI.OutputIDsByBech32Address(ctx, bech32Addr, includeSpentOutputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs, err := c.OutputsByIDs(ctx, res.OutputIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, outputs, nil
+}
+
+// OutputsByEd25519Address gets the outputs residing on the given Ed25519Address, serving the cached ones from the cache.
+func (c *NodeAPICache) OutputsByEd25519Address(ctx context.Context, addr *Ed25519Address, includeSpentOutputs bool) (*AddressOutputsResponse, map[*UTXOInput]Output, error) {
+	res, err := c.NodeAPI.OutputIDsByEd25519Address(ctx, addr, includeSpentOutputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs, err := c.OutputsByIDs(ctx, res.OutputIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, outputs, nil
+}
@@ -0,0 +1,135 @@
+package iotago_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/iotaledger/iota.go/v2/tpkg"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+func mockMilestoneResponse(index uint32, times int) {
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMilestone, fmt.Sprint(index))).
+		Times(times).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MilestoneResponse{Index: index}})
+}
+
+func TestNodeAPICache_Immutable(t *testing.T) {
+	defer gock.Off()
+
+	mockMilestoneResponse(1, 1)
+	mockMilestoneResponse(2, 1)
+	mockMilestoneResponse(3, 1)
+	mockMilestoneResponse(1, 1)
+
+	cache := iotago.NewNodeAPICache(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), iotago.WithNodeAPICacheSize(2))
+
+	for i := 0; i < 3; i++ {
+		res, err := cache.MilestoneByIndex(context.Background(), 1)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, res.Index)
+	}
+	require.Equal(t, iotago.NodeAPICacheStats{Hits: 2, Misses: 1, Size: 1}, cache.Stats())
+
+	// milestone 1 is the least recently used one and gets evicted
+	_, err := cache.MilestoneByIndex(context.Background(), 2)
+	require.NoError(t, err)
+	_, err = cache.MilestoneByIndex(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, 2, cache.Stats().Size)
+
+	_, err = cache.MilestoneByIndex(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, gock.IsDone())
+
+	cache.Purge()
+	require.Zero(t, cache.Stats().Size)
+}
+
+func TestNodeAPICache_MessageMetadata(t *testing.T) {
+	defer gock.Off()
+
+	msgID := tpkg.Rand32ByteArray()
+	msgIDHex := hex.EncodeToString(msgID[:])
+	route := fmt.Sprintf(iotago.NodeAPIRouteMessageMetadata, msgIDHex)
+	referencedIndex := uint32(1337)
+
+	gock.New(nodeAPIUrl).
+		Get(route).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MessageMetadataResponse{MessageID: msgIDHex}})
+	gock.New(nodeAPIUrl).
+		Get(route).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MessageMetadataResponse{MessageID: msgIDHex, ReferencedByMilestoneIndex: &referencedIndex}})
+
+	cache := iotago.NewNodeAPICache(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), iotago.WithNodeAPICacheMutableTTL(0))
+
+	// the metadata of an unreferenced message is not cached
+	metadata, err := cache.MessageMetadataByMessageID(context.Background(), msgID)
+	require.NoError(t, err)
+	require.Nil(t, metadata.ReferencedByMilestoneIndex)
+
+	for i := 0; i < 2; i++ {
+		metadata, err = cache.MessageMetadataByMessageID(context.Background(), msgID)
+		require.NoError(t, err)
+		require.EqualValues(t, referencedIndex, *metadata.ReferencedByMilestoneIndex)
+	}
+	require.True(t, gock.IsDone())
+	require.EqualValues(t, 1, cache.Stats().Hits)
+}
+
+func TestNodeAPICache_OutputMutableTTL(t *testing.T) {
+	defer gock.Off()
+
+	utxoInput := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray(), TransactionOutputIndex: 1}
+	output, _ := tpkg.RandSigLockedSingleOutput(iotago.AddressEd25519)
+	outputJSON, err := output.MarshalJSON()
+	require.NoError(t, err)
+	rawOutput := json.RawMessage(outputJSON)
+
+	route := fmt.Sprintf(iotago.NodeAPIRouteOutput, utxoInput.ID().ToHex())
+	gock.New(nodeAPIUrl).
+		Get(route).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeOutputResponse{RawOutput: &rawOutput}})
+	gock.New(nodeAPIUrl).
+		Get(route).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeOutputResponse{Spent: true, RawOutput: &rawOutput}})
+
+	const ttl = 50 * time.Millisecond
+	cache := iotago.NewNodeAPICache(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), iotago.WithNodeAPICacheMutableTTL(ttl))
+
+	// the unspent output is served from the cache until the TTL passed
+	res, err := cache.OutputByID(context.Background(), utxoInput.ID())
+	require.NoError(t, err)
+	require.False(t, res.Spent)
+	res, err = cache.OutputByID(context.Background(), utxoInput.ID())
+	require.NoError(t, err)
+	require.False(t, res.Spent)
+	require.True(t, gock.IsPending())
+
+	time.Sleep(2 * ttl)
+
+	// while the spent output never expires
+	res, err = cache.OutputByID(context.Background(), utxoInput.ID())
+	require.NoError(t, err)
+	require.True(t, res.Spent)
+	require.True(t, gock.IsDone())
+
+	time.Sleep(2 * ttl)
+	res, err = cache.OutputByID(context.Background(), utxoInput.ID())
+	require.NoError(t, err)
+	require.True(t, res.Spent)
+}
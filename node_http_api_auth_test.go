@@ -0,0 +1,92 @@
+package iotago_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+const authTestPeerID = "12D3KooWFJ8Nq6gHLLvigTpPSbyMmLk35k1TcpJof8Y4y8yFAB32"
+
+func TestNodeHTTPAPIClient_BearerToken(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(nodeAPIUrl).
+		Delete(fmt.Sprintf(iotago.NodeAPIRoutePeer, authTestPeerID)).
+		MatchHeader("Authorization", "^Bearer static-token$").
+		MatchHeader("X-Custom", "^value$").
+		Reply(200)
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl,
+		iotago.WithNodeHTTPAPIClientBearerToken("static-token"),
+		iotago.WithNodeHTTPAPIClientHeader("X-Custom", "value"),
+	)
+	require.NoError(t, nodeAPI.RemovePeerByID(context.Background(), authTestPeerID))
+	require.True(t, gock.IsDone())
+}
+
+func TestNodeHTTPAPIClient_TokenProvider(t *testing.T) {
+	defer gock.Off()
+
+	route := fmt.Sprintf(iotago.NodeAPIRoutePeer, authTestPeerID)
+
+	gock.New(nodeAPIUrl).
+		Delete(route).
+		MatchHeader("Authorization", "^Bearer token-1$").
+		Reply(200)
+	gock.New(nodeAPIUrl).
+		Delete(route).
+		MatchHeader("Authorization", "^Bearer token-1$").
+		Reply(401).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+	gock.New(nodeAPIUrl).
+		Delete(route).
+		MatchHeader("Authorization", "^Bearer token-2$").
+		Reply(200)
+
+	var calls int
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl,
+		iotago.WithNodeHTTPAPIClientBearerToken("static-token"),
+		iotago.WithNodeHTTPAPIClientTokenProvider(func(ctx context.Context) (string, error) {
+			calls++
+			return fmt.Sprintf("token-%d", calls), nil
+		}),
+	)
+
+	// the token is cached
+	require.NoError(t, nodeAPI.RemovePeerByID(context.Background(), authTestPeerID))
+	require.Equal(t, 1, calls)
+
+	// and refreshed once rejected
+	require.NoError(t, nodeAPI.RemovePeerByID(context.Background(), authTestPeerID))
+	require.Equal(t, 2, calls)
+	require.True(t, gock.IsDone())
+
+	// a fresh token which also gets rejected is not refreshed again
+	gock.New(nodeAPIUrl).
+		Delete(route).
+		Times(2).
+		Reply(401).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+
+	err := nodeAPI.RemovePeerByID(context.Background(), authTestPeerID)
+	require.True(t, errors.Is(err, iotago.ErrHTTPUnauthorized))
+	require.Equal(t, 3, calls)
+	require.True(t, gock.IsDone())
+
+	// errors of the token provider are returned
+	errProvider := errors.New("provider failure")
+	nodeAPI = iotago.NewNodeHTTPAPIClient(nodeAPIUrl,
+		iotago.WithNodeHTTPAPIClientTokenProvider(func(ctx context.Context) (string, error) {
+			return "", errProvider
+		}),
+	)
+	_, err = nodeAPI.Health(context.Background())
+	require.True(t, errors.Is(err, errProvider))
+}
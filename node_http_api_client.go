@@ -12,7 +12,8 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/finderAUT/hive.go/v2/serializer"
 )
@@ -30,6 +31,10 @@ var (
 	ErrHTTPUnknownError = errors.New("unknown error")
 	// ErrHTTPNotImplemented gets returned for 501 not implemented error HTTP responses.
 	ErrHTTPNotImplemented = errors.New("operation not implemented/supported/available")
+	// ErrHTTPTooManyRequests gets returned for 429 too many requests error HTTP responses.
+	ErrHTTPTooManyRequests = errors.New("too many requests")
+	// ErrHTTPServiceUnavailable gets returned for 503 service unavailable error HTTP responses.
+	ErrHTTPServiceUnavailable = errors.New("service unavailable")
 
 	httpCodeToErr = map[int]error{
 		http.StatusBadRequest:          ErrHTTPBadRequest,
@@ -37,6 +42,8 @@ var (
 		http.StatusNotFound:            ErrHTTPNotFound,
 		http.StatusUnauthorized:        ErrHTTPUnauthorized,
 		http.StatusNotImplemented:      ErrHTTPNotImplemented,
+		http.StatusTooManyRequests:     ErrHTTPTooManyRequests,
+		http.StatusServiceUnavailable:  ErrHTTPServiceUnavailable,
 	}
 )
 
@@ -135,11 +142,20 @@ const (
 // RequestURLHook is a function to modify the URL before sending a request.
 type RequestURLHook func(url string) string
 
+// TokenProvider is a function returning a token, i.e. a JWT, used to authenticate against a node.
+// A NodeHTTPAPIClient calls it once and again whenever the node rejects the previously returned token.
+type TokenProvider func(ctx context.Context) (string, error)
+
 // the default options applied to the NodeHTTPAPIClient.
 var defaultNodeAPIOptions = []NodeHTTPAPIClientOption{
 	WithNodeHTTPAPIClientHTTPClient(http.DefaultClient),
 	WithNodeHTTPAPIClientUserInfo(nil),
 	WithNodeHTTPAPIClientRequestURLHook(nil),
+	WithNodeHTTPAPIClientRetryPolicy(nil),
+	WithNodeHTTPAPIClientMaxConcurrentRequests(0),
+	WithNodeHTTPAPIClientTokenProvider(nil),
+	WithNodeHTTPAPIClientInterceptors(),
+	WithNodeHTTPAPIClientOutputFetchConcurrency(DefaultOutputFetchConcurrency),
 }
 
 // NodeHTTPAPIClientOptions define options for the NodeHTTPAPIClient.
@@ -150,6 +166,20 @@ type NodeHTTPAPIClientOptions struct {
 	userInfo *url.Userinfo
 	// The hook to modify the URL before sending a request.
 	requestURLHook RequestURLHook
+	// The policy used to retry failed idempotent requests.
+	retryPolicy *RetryPolicy
+	// The max. amount of concurrent requests, 0 for no limit.
+	maxConcurrentRequests int
+	// The static bearer token added to the requests.
+	bearerToken string
+	// The provider of the bearer token added to the requests.
+	tokenProvider TokenProvider
+	// Additional headers added to the requests.
+	headers http.Header
+	// The interceptors wrapping the calls.
+	interceptors []NodeHTTPAPIInterceptor
+	// The amount of outputs fetched concurrently.
+	outputFetchConcurrency int
 }
 
 // applies the given NodeHTTPAPIClientOption.
@@ -180,6 +210,65 @@ func WithNodeHTTPAPIClientRequestURLHook(requestURLHook RequestURLHook) NodeHTTP
 	}
 }
 
+// WithNodeHTTPAPIClientRetryPolicy sets the RetryPolicy used to retry failed idempotent (GET and HEAD) requests.
+// A nil policy disables retries.
+func WithNodeHTTPAPIClientRetryPolicy(retryPolicy *RetryPolicy) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		opts.retryPolicy = retryPolicy
+	}
+}
+
+// WithNodeHTTPAPIClientMaxConcurrentRequests limits the amount of requests the NodeHTTPAPIClient has in flight at once.
+// Requests exceeding the limit wait until a slot is free or their context is done. 0 disables the limit.
+func WithNodeHTTPAPIClientMaxConcurrentRequests(maxConcurrentRequests int) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		opts.maxConcurrentRequests = maxConcurrentRequests
+	}
+}
+
+// WithNodeHTTPAPIClientBearerToken sets a static token used to add bearer "Authorization" headers to the requests.
+func WithNodeHTTPAPIClientBearerToken(token string) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		opts.bearerToken = token
+	}
+}
+
+// WithNodeHTTPAPIClientTokenProvider sets the TokenProvider used to add bearer "Authorization" headers to the requests.
+// The token is cached and requests are repeated once with a freshly provided token if the node responds with 401.
+// The TokenProvider takes precedence over a static bearer token.
+func WithNodeHTTPAPIClientTokenProvider(tokenProvider TokenProvider) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		opts.tokenProvider = tokenProvider
+	}
+}
+
+// WithNodeHTTPAPIClientHeader adds the given header to the requests. Calling it multiple times with the same key adds multiple values.
+func WithNodeHTTPAPIClientHeader(key string, value string) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		if opts.headers == nil {
+			opts.headers = make(http.Header)
+		}
+		opts.headers.Add(key, value)
+	}
+}
+
+// WithNodeHTTPAPIClientInterceptors adds the given interceptors wrapping every call going through NodeHTTPAPIClient.Do.
+// Interceptors are invoked in the order they were added, the first one being the outermost.
+// Retries of a call happen within the innermost interceptor.
+func WithNodeHTTPAPIClientInterceptors(interceptors ...NodeHTTPAPIInterceptor) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		opts.interceptors = append(opts.interceptors, interceptors...)
+	}
+}
+
+// WithNodeHTTPAPIClientOutputFetchConcurrency sets the amount of outputs fetched concurrently
+// by OutputsByIDs, OutputsByBech32Address and OutputsByEd25519Address.
+func WithNodeHTTPAPIClientOutputFetchConcurrency(concurrency int) NodeHTTPAPIClientOption {
+	return func(opts *NodeHTTPAPIClientOptions) {
+		opts.outputFetchConcurrency = concurrency
+	}
+}
+
 // NodeHTTPAPIClientOption is a function setting a NodeHTTPAPIClient option.
 type NodeHTTPAPIClientOption func(opts *NodeHTTPAPIClientOptions)
 
@@ -190,10 +279,15 @@ func NewNodeHTTPAPIClient(baseURL string, opts ...NodeHTTPAPIClientOption) *Node
 	options.apply(defaultNodeAPIOptions...)
 	options.apply(opts...)
 
-	return &NodeHTTPAPIClient{
+	api := &NodeHTTPAPIClient{
 		BaseURL: baseURL,
 		opts:    options,
 	}
+	if options.maxConcurrentRequests > 0 {
+		api.limiter = make(chan struct{}, options.maxConcurrentRequests)
+	}
+	api.invoker = chainNodeHTTPAPIInterceptors(api.do, options.interceptors)
+	return api
 }
 
 // NodeHTTPAPIClient is a client for node HTTP REST API endpoints.
@@ -202,6 +296,13 @@ type NodeHTTPAPIClient struct {
 	BaseURL string
 	// holds the NodeHTTPAPIClient options.
 	opts *NodeHTTPAPIClientOptions
+	// executes the calls through the chain of interceptors.
+	invoker NodeHTTPAPIInvoker
+	// limits the amount of concurrent requests, nil if unlimited.
+	limiter chan struct{}
+	// the token of the TokenProvider used for the requests.
+	tokenMu sync.Mutex
+	token   string
 }
 
 // HTTPErrorResponseEnvelope defines the error response schema for node API responses.
@@ -255,10 +356,6 @@ func interpretBody(res *http.Response, decodeTo interface{}) error {
 		return json.Unmarshal(resBody, okRes)
 	}
 
-	if res.StatusCode == http.StatusServiceUnavailable {
-		return nil
-	}
-
 	resBody, err := readBody(res)
 	if err != nil {
 		return err
@@ -266,7 +363,11 @@ func interpretBody(res *http.Response, decodeTo interface{}) error {
 
 	errRes := &HTTPErrorResponseEnvelope{}
 	if err := json.Unmarshal(resBody, errRes); err != nil {
-		return fmt.Errorf("unable to read error from response body: %w", err)
+		// rate limiting and unavailable nodes or proxies in front of them don't necessarily respond with an error envelope
+		if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable {
+			return fmt.Errorf("unable to read error from response body: %w", err)
+		}
+		errRes.Error.Message = http.StatusText(res.StatusCode)
 	}
 
 	err, ok := httpCodeToErr[res.StatusCode]
@@ -277,7 +378,14 @@ func interpretBody(res *http.Response, decodeTo interface{}) error {
 	return fmt.Errorf("%w: url %s, error message: %s", err, res.Request.URL.String(), errRes.Error.Message)
 }
 
+// Do executes a request against the node's HTTP REST API and decodes the response into resObj.
+// The call passes the configured interceptors and failed idempotent requests are retried according to the configured RetryPolicy.
 func (api *NodeHTTPAPIClient) Do(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error) {
+	return api.invoker(ctx, method, route, reqObj, resObj)
+}
+
+// executes a call including its retries.
+func (api *NodeHTTPAPIClient) do(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error) {
 	// marshal request object
 	var data []byte
 	var raw bool
@@ -296,12 +404,89 @@ func (api *NodeHTTPAPIClient) Do(ctx context.Context, method string, route strin
 		}
 	}
 
-	// construct request URL
+	url := api.requestURL(route)
+	retryPolicy := api.opts.retryPolicy
+	if method != http.MethodGet && method != http.MethodHead {
+		retryPolicy = nil
+	}
+	if route == NodeAPIRouteHealth {
+		// an unhealthy node responds with 503, which must not be retried
+		retryPolicy = nil
+	}
+
+	var tokenRefreshed bool
+	for attempt := 1; ; attempt++ {
+		token, err := api.authToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := api.attempt(ctx, method, url, data, raw, token, resObj)
+		if err == nil {
+			return res, nil
+		}
+
+		// the node rejected the provided token, the request is repeated once with a fresh one
+		if errors.Is(err, ErrHTTPUnauthorized) && api.opts.tokenProvider != nil && !tokenRefreshed {
+			tokenRefreshed = true
+			api.invalidateToken(token)
+			attempt--
+			continue
+		}
+
+		backoff, retry := retryPolicy.backoff(attempt, res, err)
+		if !retry || ctx.Err() != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// constructs the request URL for the given route.
+func (api *NodeHTTPAPIClient) requestURL(route string) string {
 	url := fmt.Sprintf("%s%s", api.BaseURL, route)
 	if api.opts.requestURLHook != nil {
 		url = api.opts.requestURLHook(url)
 	}
+	return url
+}
+
+// returns the bearer token to add to the requests or an empty string if none is configured.
+func (api *NodeHTTPAPIClient) authToken(ctx context.Context) (string, error) {
+	if api.opts.tokenProvider == nil {
+		return api.opts.bearerToken, nil
+	}
+
+	api.tokenMu.Lock()
+	defer api.tokenMu.Unlock()
+	if api.token == "" {
+		token, err := api.opts.tokenProvider(ctx)
+		if err != nil {
+			return "", fmt.Errorf("unable to get token from token provider: %w", err)
+		}
+		api.token = token
+	}
+	return api.token, nil
+}
+
+// drops the given cached token of the TokenProvider, unless it was already replaced by a concurrent request.
+func (api *NodeHTTPAPIClient) invalidateToken(token string) {
+	api.tokenMu.Lock()
+	defer api.tokenMu.Unlock()
+	if api.token == token {
+		api.token = ""
+	}
+}
 
+// executes a single request attempt.
+func (api *NodeHTTPAPIClient) attempt(ctx context.Context, method string, url string, data []byte, raw bool, token string, resObj interface{}) (*http.Response, error) {
 	// construct request
 	req, err := http.NewRequestWithContext(ctx, method, url, func() io.Reader {
 		if data == nil {
@@ -313,11 +498,21 @@ func (api *NodeHTTPAPIClient) Do(ctx context.Context, method string, route strin
 		return nil, fmt.Errorf("unable to build http request: %w", err)
 	}
 
+	for key, values := range api.opts.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
 	if api.opts.userInfo != nil {
 		// set the userInfo for basic auth
 		req.URL.User = api.opts.userInfo
 	}
 
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
 	if data != nil {
 		if !raw {
 			req.Header.Set("Content-Type", contentTypeJSON)
@@ -326,6 +521,15 @@ func (api *NodeHTTPAPIClient) Do(ctx context.Context, method string, route strin
 		}
 	}
 
+	if api.limiter != nil {
+		select {
+		case api.limiter <- struct{}{}:
+			defer func() { <-api.limiter }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	// make the request
 	res, err := api.opts.httpClient.Do(req)
 	if err != nil {
@@ -334,20 +538,19 @@ func (api *NodeHTTPAPIClient) Do(ctx context.Context, method string, route strin
 
 	// write response into response object
 	if err := interpretBody(res, resObj); err != nil {
-		return nil, err
+		return res, err
 	}
 	return res, nil
 }
 
 // Health returns whether the given node is healthy.
 func (api *NodeHTTPAPIClient) Health(ctx context.Context) (bool, error) {
-	res, err := api.Do(ctx, http.MethodGet, NodeAPIRouteHealth, nil, nil)
-	if err != nil {
+	if _, err := api.Do(ctx, http.MethodGet, NodeAPIRouteHealth, nil, nil); err != nil {
+		if errors.Is(err, ErrHTTPServiceUnavailable) {
+			return false, nil
+		}
 		return false, err
 	}
-	if res.StatusCode == http.StatusServiceUnavailable {
-		return false, nil
-	}
 	return true, nil
 }
 
@@ -429,28 +632,33 @@ func (api *NodeHTTPAPIClient) SubmitMessage(ctx context.Context, m *Message) (*M
 	// Do not check the message because the validation would fail if
 	// no parents were given. The node will first add this missing information and
 	// validate the message afterwards.
-	data, err := m.Serialize(serializer.DeSeriModeNoValidation)
+	messageID, err := api.submitMessage(ctx, m)
 	if err != nil {
 		return nil, err
 	}
 
-	req := &RawDataEnvelope{Data: data}
-	res, err := api.Do(ctx, http.MethodPost, NodeAPIRouteMessages, req, nil)
+	msg, err := api.MessageByMessageID(ctx, messageID)
 	if err != nil {
 		return nil, err
 	}
 
-	messageID, err := MessageIDFromHexString(res.Header.Get(locationHeader))
+	return msg, nil
+}
+
+// submits the given message and returns the ID of the message the node created from it.
+func (api *NodeHTTPAPIClient) submitMessage(ctx context.Context, m *Message) (MessageID, error) {
+	data, err := m.Serialize(serializer.DeSeriModeNoValidation)
 	if err != nil {
-		return nil, err
+		return MessageID{}, err
 	}
 
-	msg, err := api.MessageByMessageID(ctx, messageID)
+	req := &RawDataEnvelope{Data: data}
+	res, err := api.Do(ctx, http.MethodPost, NodeAPIRouteMessages, req, nil)
 	if err != nil {
-		return nil, err
+		return MessageID{}, err
 	}
 
-	return msg, nil
+	return MessageIDFromHexString(res.Header.Get(locationHeader))
 }
 
 // MessageIDsByIndexResponse defines the response of a GET messages REST API call.
@@ -463,22 +671,19 @@ type MessageIDsByIndexResponse struct {
 	Count uint32 `json:"count"`
 	// The hex encoded message IDs of the found messages with this index.
 	MessageIDs []string `json:"messageIds"`
+	// The cursor to request the next page of results, nil if there are none or the node doesn't support paging.
+	Cursor *string `json:"cursor,omitempty"`
 }
 
 // MessageIDsByIndex gets message IDs filtered by index from the node.
+// All pages of results are fetched, ErrResultsTruncated is returned if the node truncated them without providing a cursor.
 func (api *NodeHTTPAPIClient) MessageIDsByIndex(ctx context.Context, index []byte) (*MessageIDsByIndexResponse, error) {
-	var query strings.Builder
-	query.WriteString(NodeAPIRouteMessages)
-	query.WriteString("?index=")
-	query.WriteString(hex.EncodeToString(index))
-
-	res := &MessageIDsByIndexResponse{}
-	_, err := api.Do(ctx, http.MethodGet, query.String(), nil, res)
-	if err != nil {
-		return nil, err
-	}
+	return api.MessageIDsByIndexIterator(index).all(ctx)
+}
 
-	return res, nil
+// MessageIDsByIndexIterator returns a MessageIDsByIndexIterator paging through the message IDs filtered by index.
+func (api *NodeHTTPAPIClient) MessageIDsByIndexIterator(index []byte) *MessageIDsByIndexIterator {
+	return newMessageIDsByIndexIterator(api.Do, index)
 }
 
 // MessageMetadataResponse defines the response of a GET message metadata REST API call.
@@ -494,13 +699,13 @@ type MessageMetadataResponse struct {
 	// If this message represents a milestone this is the milestone index
 	MilestoneIndex *uint32 `json:"milestoneIndex,omitempty"`
 	// The ledger inclusion state of the transaction payload.
-	LedgerInclusionState *string `json:"ledgerInclusionState,omitempty"`
+	LedgerInclusionState *LedgerInclusionState `json:"ledgerInclusionState,omitempty"`
 	// Whether the message should be promoted.
 	ShouldPromote *bool `json:"shouldPromote,omitempty"`
 	// Whether the message should be reattached.
 	ShouldReattach *bool `json:"shouldReattach,omitempty"`
 	// The reason why this message is marked as conflicting.
-	ConflictReason uint8 `json:"conflictReason,omitempty"`
+	ConflictReason ConflictReason `json:"conflictReason,omitempty"`
 }
 
 // MessageMetadataByMessageID gets the metadata of a message by its message ID from the node.
@@ -680,23 +885,20 @@ type AddressOutputsResponse struct {
 	OutputIDs []OutputIDHex `json:"outputIDs"`
 	// The ledger index at which these outputs where available at.
 	LedgerIndex uint64 `json:"ledgerIndex"`
+	// The cursor to request the next page of results, nil if there are none or the node doesn't support paging.
+	Cursor *string `json:"cursor,omitempty"`
 }
 
 // OutputIDsByBech32Address gets output IDs of outputs residing on the given Bech32 address.
 // Per default only unspent outputs IDs are returned. Set includeSpentOutputs to true to also return spent output IDs.
+// All pages of results are fetched, ErrResultsTruncated is returned if the node truncated them without providing a cursor.
 func (api *NodeHTTPAPIClient) OutputIDsByBech32Address(ctx context.Context, bech32Addr string, includeSpentOutputs bool) (*AddressOutputsResponse, error) {
-	query := fmt.Sprintf(NodeAPIRouteAddressBech32Outputs, bech32Addr)
-	if includeSpentOutputs {
-		query += "?include-spent=true"
-	}
-
-	res := &AddressOutputsResponse{}
-	_, err := api.Do(ctx, http.MethodGet, query, nil, res)
-	if err != nil {
-		return nil, err
-	}
+	return api.OutputIDsByBech32AddressIterator(bech32Addr, includeSpentOutputs).all(ctx)
+}
 
-	return res, nil
+// OutputIDsByBech32AddressIterator returns an AddressOutputsIterator paging through the output IDs of outputs residing on the given Bech32 address.
+func (api *NodeHTTPAPIClient) OutputIDsByBech32AddressIterator(bech32Addr string, includeSpentOutputs bool) *AddressOutputsIterator {
+	return newAddressOutputsIterator(api.Do, addressOutputsRoute(NodeAPIRouteAddressBech32Outputs, bech32Addr, includeSpentOutputs))
 }
 
 // OutputsByBech32Address gets the outputs residing on the given Bech32 address.
@@ -712,19 +914,23 @@ func (api *NodeHTTPAPIClient) OutputsByBech32Address(ctx context.Context, bech32
 
 // OutputIDsByEd25519Address gets output IDs of outputs residing on the given Ed25519Address.
 // Per default only unspent output IDs are returned. Set includeSpentOutputs to true to also return spent output IDs.
+// All pages of results are fetched, ErrResultsTruncated is returned if the node truncated them without providing a cursor.
 func (api *NodeHTTPAPIClient) OutputIDsByEd25519Address(ctx context.Context, addr *Ed25519Address, includeSpentOutputs bool) (*AddressOutputsResponse, error) {
-	query := fmt.Sprintf(NodeAPIRouteAddressEd25519Outputs, addr.String())
+	return api.OutputIDsByEd25519AddressIterator(addr, includeSpentOutputs).all(ctx)
+}
+
+// OutputIDsByEd25519AddressIterator returns an AddressOutputsIterator paging through the output IDs of outputs residing on the given Ed25519Address.
+func (api *NodeHTTPAPIClient) OutputIDsByEd25519AddressIterator(addr *Ed25519Address, includeSpentOutputs bool) *AddressOutputsIterator {
+	return newAddressOutputsIterator(api.Do, addressOutputsRoute(NodeAPIRouteAddressEd25519Outputs, addr.String(), includeSpentOutputs))
+}
+
+// builds the route to query the output IDs of the given address.
+func addressOutputsRoute(route string, addr string, includeSpentOutputs bool) string {
+	query := fmt.Sprintf(route, addr)
 	if includeSpentOutputs {
 		query += "?include-spent=true"
 	}
-
-	res := &AddressOutputsResponse{}
-	_, err := api.Do(ctx, http.MethodGet, query, nil, res)
-	if err != nil {
-		return nil, err
-	}
-
-	return res, nil
+	return query
 }
 
 // OutputsByEd25519Address gets the outputs residing on the given Ed25519Address.
@@ -740,28 +946,19 @@ func (api *NodeHTTPAPIClient) OutputsByEd25519Address(ctx context.Context, addr
 
 // queries the actual outputs given an AddressOutputsResponse.
 func (api *NodeHTTPAPIClient) outputIDsToOutputs(ctx context.Context, res *AddressOutputsResponse) (*AddressOutputsResponse, map[*UTXOInput]Output, error) {
-	outputs := make(map[*UTXOInput]Output)
-	for _, outputIDHex := range res.OutputIDs {
-		utxoInput, err := outputIDHex.AsUTXOInput()
-		if err != nil {
-			return nil, nil, err
-		}
-
-		outputRes, err := api.OutputByID(ctx, utxoInput.ID())
-		if err != nil {
-			return nil, nil, err
-		}
-
-		output, err := outputRes.Output()
-		if err != nil {
-			return nil, nil, err
-		}
-		outputs[utxoInput] = output
+	outputs, err := api.OutputsByIDs(ctx, res.OutputIDs)
+	if err != nil {
+		return nil, nil, err
 	}
-
 	return res, outputs, nil
 }
 
+// OutputsByIDs gets the outputs with the given IDs, fetching up to DefaultOutputFetchConcurrency
+// (or the configured output fetch concurrency) of them concurrently.
+func (api *NodeHTTPAPIClient) OutputsByIDs(ctx context.Context, outputIDs []OutputIDHex) (map[*UTXOInput]Output, error) {
+	return fetchOutputs(ctx, api, outputIDs, api.opts.outputFetchConcurrency)
+}
+
 // TreasuryResponse defines the response of a GET treasury REST API call.
 type TreasuryResponse struct {
 	MilestoneID string `json:"milestoneId"`
@@ -0,0 +1,226 @@
+package iotago
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NodeHTTPAPIInvoker executes a call against the node's HTTP REST API, see NodeHTTPAPIClient.Do.
+type NodeHTTPAPIInvoker func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error)
+
+// NodeHTTPAPIInterceptor intercepts calls going through NodeHTTPAPIClient.Do.
+// An interceptor continues the call by invoking next, it may alter the parameters beforehand
+// and the response and error afterwards or short-circuit the call by not invoking next at all,
+// in which case it is responsible for filling resObj.
+// The route passed to an interceptor is the route including its parameters and query, use NodeAPIRouteTemplate
+// to get the corresponding NodeAPIRoute constant.
+type NodeHTTPAPIInterceptor func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}, next NodeHTTPAPIInvoker) (*http.Response, error)
+
+// chains the given interceptors around the given invoker, the first interceptor being the outermost one.
+func chainNodeHTTPAPIInterceptors(invoker NodeHTTPAPIInvoker, interceptors []NodeHTTPAPIInterceptor) NodeHTTPAPIInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error) {
+			return interceptor(ctx, method, route, reqObj, resObj, next)
+		}
+	}
+	return invoker
+}
+
+// the NodeAPIRoute constants which are parameterized, along with a pattern matching them.
+// more specific routes come first as the patterns of routes with a parameter in the same position would match them as well.
+var nodeAPIRouteTemplates = func() []struct {
+	template string
+	pattern  *regexp.Regexp
+} {
+	templates := []string{
+		NodeAPIRouteMessageMetadata,
+		NodeAPIRouteMessageBytes,
+		NodeAPIRouteMessageChildren,
+		NodeAPIRouteMessageData,
+		NodeAPIRouteMilestoneUTXOChanges,
+		NodeAPIRouteMilestone,
+		NodeAPIRouteOutput,
+		NodeAPIRouteAddressEd25519Outputs,
+		NodeAPIRouteAddressEd25519Balance,
+		NodeAPIRouteAddressBech32Outputs,
+		NodeAPIRouteAddressBech32Balance,
+		NodeAPIRouteReceiptsByMigratedAtIndex,
+		NodeAPIRoutePeer,
+	}
+	routeTemplates := make([]struct {
+		template string
+		pattern  *regexp.Regexp
+	}, len(templates))
+	for i, template := range templates {
+		routeTemplates[i].template = template
+		routeTemplates[i].pattern = regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(template), "%s", "[^/]+") + "$")
+	}
+	return routeTemplates
+}()
+
+// NodeAPIRouteTemplate returns the NodeAPIRoute constant the given route was built from,
+// i.e. NodeAPIRouteMessageMetadata for "/api/v1/messages/<messageID>/metadata".
+// The query of the route is stripped. Routes which don't correspond to any NodeAPIRoute constant are returned as is.
+func NodeAPIRouteTemplate(route string) string {
+	if i := strings.IndexByte(route, '?'); i != -1 {
+		route = route[:i]
+	}
+	for _, routeTemplate := range nodeAPIRouteTemplates {
+		if routeTemplate.pattern.MatchString(route) {
+			return routeTemplate.template
+		}
+	}
+	return route
+}
+
+// NodeHTTPAPILogEntry is an entry written by the interceptor returned by NewNodeHTTPAPILoggingInterceptor.
+type NodeHTTPAPILogEntry struct {
+	// The time the call was started.
+	Time time.Time `json:"time"`
+	// The HTTP method of the call.
+	Method string `json:"method"`
+	// The route of the call.
+	Route string `json:"route"`
+	// The HTTP status code of the response, 0 if the call failed.
+	StatusCode int `json:"statusCode,omitempty"`
+	// The duration of the call in milliseconds.
+	DurationMs float64 `json:"durationMs"`
+	// The error of the call.
+	Error string `json:"error,omitempty"`
+}
+
+// NewNodeHTTPAPILoggingInterceptor returns a NodeHTTPAPIInterceptor writing a NodeHTTPAPILogEntry
+// as a line of JSON to the given writer for every call.
+func NewNodeHTTPAPILoggingInterceptor(w io.Writer) NodeHTTPAPIInterceptor {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	return func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}, next NodeHTTPAPIInvoker) (*http.Response, error) {
+		start := time.Now()
+		res, err := next(ctx, method, route, reqObj, resObj)
+
+		entry := &NodeHTTPAPILogEntry{
+			Time:       start,
+			Method:     method,
+			Route:      route,
+			DurationMs: float64(time.Since(start)) / float64(time.Millisecond),
+		}
+		if res != nil {
+			entry.StatusCode = res.StatusCode
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		// a failing log must not fail the call
+		_ = encoder.Encode(entry)
+		return res, err
+	}
+}
+
+// DefaultNodeHTTPAPILatencyBuckets are the default upper bounds of the buckets of a NodeHTTPAPILatencyHistogram.
+var DefaultNodeHTTPAPILatencyBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// NodeHTTPAPILatencyKey identifies the calls a NodeHTTPAPILatencyHistogram groups together.
+type NodeHTTPAPILatencyKey struct {
+	// The HTTP method of the calls.
+	Method string
+	// The NodeAPIRoute constant of the calls, see NodeAPIRouteTemplate.
+	Route string
+}
+
+// NodeHTTPAPILatencySeries is the latency distribution of the calls with the same NodeHTTPAPILatencyKey.
+type NodeHTTPAPILatencySeries struct {
+	// The cumulative amount of calls per bucket, i.e. Counts[i] is the amount of calls which took
+	// at most Buckets[i]. The last element counts all calls.
+	Counts []uint64
+	// The amount of failed calls.
+	Errors uint64
+	// The sum of the durations of all calls.
+	Sum time.Duration
+}
+
+// NodeHTTPAPILatencyHistogram records the latencies of calls going through NodeHTTPAPIClient.Do grouped by method and route.
+type NodeHTTPAPILatencyHistogram struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	series  map[NodeHTTPAPILatencyKey]*NodeHTTPAPILatencySeries
+}
+
+// NewNodeHTTPAPILatencyHistogram creates a new NodeHTTPAPILatencyHistogram with the given bucket upper bounds.
+// DefaultNodeHTTPAPILatencyBuckets are used if none are given.
+func NewNodeHTTPAPILatencyHistogram(buckets ...time.Duration) *NodeHTTPAPILatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultNodeHTTPAPILatencyBuckets
+	}
+	sorted := make([]time.Duration, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &NodeHTTPAPILatencyHistogram{buckets: sorted, series: make(map[NodeHTTPAPILatencyKey]*NodeHTTPAPILatencySeries)}
+}
+
+// Buckets returns the upper bounds of the buckets of the histogram.
+func (h *NodeHTTPAPILatencyHistogram) Buckets() []time.Duration {
+	buckets := make([]time.Duration, len(h.buckets))
+	copy(buckets, h.buckets)
+	return buckets
+}
+
+// Observe records a call with the given method, route and duration.
+func (h *NodeHTTPAPILatencyHistogram) Observe(method string, route string, duration time.Duration, failed bool) {
+	key := NodeHTTPAPILatencyKey{Method: method, Route: NodeAPIRouteTemplate(route)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, has := h.series[key]
+	if !has {
+		series = &NodeHTTPAPILatencySeries{Counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = series
+	}
+	for i, bucket := range h.buckets {
+		if duration <= bucket {
+			series.Counts[i]++
+		}
+	}
+	series.Counts[len(h.buckets)]++
+	series.Sum += duration
+	if failed {
+		series.Errors++
+	}
+}
+
+// Snapshot returns a copy of the recorded series.
+func (h *NodeHTTPAPILatencyHistogram) Snapshot() map[NodeHTTPAPILatencyKey]NodeHTTPAPILatencySeries {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshot := make(map[NodeHTTPAPILatencyKey]NodeHTTPAPILatencySeries, len(h.series))
+	for key, series := range h.series {
+		counts := make([]uint64, len(series.Counts))
+		copy(counts, series.Counts)
+		snapshot[key] = NodeHTTPAPILatencySeries{Counts: counts, Errors: series.Errors, Sum: series.Sum}
+	}
+	return snapshot
+}
+
+// Interceptor returns a NodeHTTPAPIInterceptor recording the latency of every call in the histogram.
+func (h *NodeHTTPAPILatencyHistogram) Interceptor() NodeHTTPAPIInterceptor {
+	return func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}, next NodeHTTPAPIInvoker) (*http.Response, error) {
+		start := time.Now()
+		res, err := next(ctx, method, route, reqObj, resObj)
+		h.Observe(method, route, time.Since(start), err != nil)
+		return res, err
+	}
+}
@@ -0,0 +1,165 @@
+package iotago_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+func TestNodeHTTPAPIClient_Interceptors(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeInfoResponse{Name: "HORNET"}})
+
+	var calls []string
+	recordingInterceptor := func(name string) iotago.NodeHTTPAPIInterceptor {
+		return func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}, next iotago.NodeHTTPAPIInvoker) (*http.Response, error) {
+			calls = append(calls, fmt.Sprintf("%s before %s %s", name, method, route))
+			res, err := next(ctx, method, route, reqObj, resObj)
+			calls = append(calls, fmt.Sprintf("%s after %d", name, res.StatusCode))
+			return res, err
+		}
+	}
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl,
+		iotago.WithNodeHTTPAPIClientInterceptors(recordingInterceptor("outer")),
+		iotago.WithNodeHTTPAPIClientInterceptors(recordingInterceptor("inner")),
+	)
+	info, err := nodeAPI.Info(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "HORNET", info.Name)
+	require.Equal(t, []string{
+		"outer before GET /api/v1/info",
+		"inner before GET /api/v1/info",
+		"inner after 200",
+		"outer after 200",
+	}, calls)
+}
+
+func TestNodeHTTPAPIClient_InterceptorShortCircuit(t *testing.T) {
+	defer gock.Off()
+
+	errFake := errors.New("short-circuited")
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl,
+		iotago.WithNodeHTTPAPIClientInterceptors(func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}, next iotago.NodeHTTPAPIInvoker) (*http.Response, error) {
+			if route != iotago.NodeAPIRouteInfo {
+				return nil, errFake
+			}
+			resObj.(*iotago.NodeInfoResponse).Name = "fake"
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}),
+	)
+
+	info, err := nodeAPI.Info(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fake", info.Name)
+
+	_, err = nodeAPI.Tips(context.Background())
+	require.True(t, errors.Is(err, errFake))
+	require.False(t, gock.HasUnmatchedRequest())
+}
+
+func TestNodeHTTPAPIClient_LoggingInterceptor(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeInfoResponse{}})
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteTips).
+		Reply(500).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+
+	var log bytes.Buffer
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientInterceptors(iotago.NewNodeHTTPAPILoggingInterceptor(&log)))
+
+	_, err := nodeAPI.Info(context.Background())
+	require.NoError(t, err)
+	_, err = nodeAPI.Tips(context.Background())
+	require.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	require.Len(t, lines, 2)
+
+	entry := &iotago.NodeHTTPAPILogEntry{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), entry))
+	require.Equal(t, http.MethodGet, entry.Method)
+	require.Equal(t, iotago.NodeAPIRouteInfo, entry.Route)
+	require.Equal(t, http.StatusOK, entry.StatusCode)
+	require.Empty(t, entry.Error)
+
+	entry = &iotago.NodeHTTPAPILogEntry{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), entry))
+	require.Equal(t, iotago.NodeAPIRouteTips, entry.Route)
+	require.Contains(t, entry.Error, iotago.ErrHTTPInternalServerError.Error())
+}
+
+func TestNodeHTTPAPILatencyHistogram(t *testing.T) {
+	defer gock.Off()
+
+	msgID := "733ed2810f2333e9d6cd702c7d5c8264cd9f1ae454b61e75cf702c451f68611d"
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMessageMetadata, msgID)).
+		Times(2).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MessageMetadataResponse{MessageID: msgID}})
+
+	histogram := iotago.NewNodeHTTPAPILatencyHistogram()
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientInterceptors(histogram.Interceptor()))
+
+	msgIDBytes, err := iotago.MessageIDFromHexString(msgID)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = nodeAPI.MessageMetadataByMessageID(context.Background(), msgIDBytes)
+		require.NoError(t, err)
+	}
+
+	snapshot := histogram.Snapshot()
+	require.Len(t, snapshot, 1)
+	series, has := snapshot[iotago.NodeHTTPAPILatencyKey{Method: http.MethodGet, Route: iotago.NodeAPIRouteMessageMetadata}]
+	require.True(t, has)
+	require.EqualValues(t, 2, series.Counts[len(histogram.Buckets())])
+	require.Zero(t, series.Errors)
+
+	histogram = iotago.NewNodeHTTPAPILatencyHistogram(time.Second, 10*time.Millisecond)
+	histogram.Observe(http.MethodGet, iotago.NodeAPIRouteInfo, 5*time.Millisecond, false)
+	histogram.Observe(http.MethodGet, iotago.NodeAPIRouteInfo, 50*time.Millisecond, false)
+	histogram.Observe(http.MethodGet, iotago.NodeAPIRouteInfo, 5*time.Second, true)
+	require.Equal(t, []time.Duration{10 * time.Millisecond, time.Second}, histogram.Buckets())
+	series = histogram.Snapshot()[iotago.NodeHTTPAPILatencyKey{Method: http.MethodGet, Route: iotago.NodeAPIRouteInfo}]
+	require.Equal(t, []uint64{1, 2, 3}, series.Counts)
+	require.EqualValues(t, 1, series.Errors)
+	require.Equal(t, 5055*time.Millisecond, series.Sum)
+}
+
+func TestNodeAPIRouteTemplate(t *testing.T) {
+	for route, template := range map[string]string{
+		iotago.NodeAPIRouteInfo:                         iotago.NodeAPIRouteInfo,
+		"/api/v1/messages?index=abcd":                   iotago.NodeAPIRouteMessages,
+		"/api/v1/messages/abcd":                         iotago.NodeAPIRouteMessageData,
+		"/api/v1/messages/abcd/raw":                     iotago.NodeAPIRouteMessageBytes,
+		"/api/v1/milestones/1337/utxo-changes":          iotago.NodeAPIRouteMilestoneUTXOChanges,
+		"/api/v1/addresses/atoi1abcd":                   iotago.NodeAPIRouteAddressBech32Balance,
+		"/api/v1/addresses/atoi1abcd/outputs":           iotago.NodeAPIRouteAddressBech32Outputs,
+		"/api/v1/addresses/ed25519/abcd":                iotago.NodeAPIRouteAddressEd25519Balance,
+		"/api/v1/addresses/ed25519/abcd/outputs?type=0": iotago.NodeAPIRouteAddressEd25519Outputs,
+		"/api/v1/unknown/route":                         "/api/v1/unknown/route",
+	} {
+		require.Equal(t, template, iotago.NodeAPIRouteTemplate(route), route)
+	}
+}
@@ -0,0 +1,291 @@
+package iotago
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// NodeAPIQueryParamCursor is the query parameter used to request the page following the one which returned the cursor.
+	NodeAPIQueryParamCursor = "cursor"
+	// NodeAPIQueryParamLedgerIndex is the query parameter used to pin the pages of a query to the ledger index of its first page.
+	NodeAPIQueryParamLedgerIndex = "ledgerIndex"
+
+	// DefaultOutputFetchConcurrency is the default amount of outputs a NodeHTTPAPIClient fetches concurrently.
+	DefaultOutputFetchConcurrency = 8
+)
+
+var (
+	// ErrResultsTruncated gets returned when a node truncated the results of a query to its max. results
+	// and does not provide a cursor to page through the remaining ones.
+	ErrResultsTruncated = errors.New("results truncated by node")
+)
+
+// adds the given query parameters to the route.
+func routeWithQuery(route string, query url.Values) string {
+	if len(query) == 0 {
+		return route
+	}
+	if strings.Contains(route, "?") {
+		return route + "&" + query.Encode()
+	}
+	return route + "?" + query.Encode()
+}
+
+// issues a request against the node HTTP REST API, i.e. NodeHTTPAPIClient.Do.
+type nodeAPIDoFunc func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error)
+
+// a page of results as returned by the node.
+type resultsPage struct {
+	maxResults uint32
+	count      uint32
+	cursor     *string
+}
+
+// pages through the results of a query by following the cursors returned by the node.
+type pageIterator struct {
+	// fetches the page with the given query parameters.
+	fetch func(ctx context.Context, query url.Values) (*resultsPage, error)
+	// the query parameters for the next page.
+	query url.Values
+	// whether the last page was fetched.
+	done bool
+	// the error returned after the last page.
+	doneErr error
+	err     error
+}
+
+// fetches the next page and returns whether there was one.
+func (it *pageIterator) next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.done {
+		it.err = it.doneErr
+		return false
+	}
+
+	page, err := it.fetch(ctx, it.query)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	switch {
+	case page.cursor != nil && *page.cursor != "":
+		if it.query == nil {
+			it.query = make(url.Values)
+		}
+		it.query.Set(NodeAPIQueryParamCursor, *page.cursor)
+	case page.maxResults > 0 && page.count >= page.maxResults:
+		it.done = true
+		it.doneErr = fmt.Errorf("%w: node returned %d of max. %d results without a cursor", ErrResultsTruncated, page.count, page.maxResults)
+	default:
+		it.done = true
+	}
+	return true
+}
+
+// AddressOutputsIterator pages through the output IDs of an address.
+// The pages following the first one are pinned to the ledger index of the first page.
+type AddressOutputsIterator struct {
+	pageIterator
+	page *AddressOutputsResponse
+}
+
+// creates a new AddressOutputsIterator for the given route, issuing its requests via the given do function.
+func newAddressOutputsIterator(do nodeAPIDoFunc, route string) *AddressOutputsIterator {
+	it := &AddressOutputsIterator{}
+	it.fetch = func(ctx context.Context, query url.Values) (*resultsPage, error) {
+		if it.page != nil && it.page.LedgerIndex != 0 {
+			query.Set(NodeAPIQueryParamLedgerIndex, strconv.FormatUint(it.page.LedgerIndex, 10))
+		}
+		res := &AddressOutputsResponse{}
+		if _, err := do(ctx, http.MethodGet, routeWithQuery(route, query), nil, res); err != nil {
+			return nil, err
+		}
+		it.page = res
+		return &resultsPage{maxResults: res.MaxResults, count: res.Count, cursor: res.Cursor}, nil
+	}
+	return it
+}
+
+// Next fetches the next page and returns whether there was one. It returns false once all pages were fetched or an error occurred.
+func (it *AddressOutputsIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Page returns the page fetched by the last call to Next.
+func (it *AddressOutputsIterator) Page() *AddressOutputsResponse {
+	return it.page
+}
+
+// Err returns the error which stopped the iteration. It wraps ErrResultsTruncated if the node truncated the last page
+// without providing a cursor, in which case the truncated page is still returned by Page.
+func (it *AddressOutputsIterator) Err() error {
+	return it.err
+}
+
+// all fetches the remaining pages and merges them into a single response.
+func (it *AddressOutputsIterator) all(ctx context.Context) (*AddressOutputsResponse, error) {
+	var merged *AddressOutputsResponse
+	for it.Next(ctx) {
+		page := it.Page()
+		if merged == nil {
+			merged = &AddressOutputsResponse{
+				AddressType: page.AddressType,
+				Address:     page.Address,
+				MaxResults:  page.MaxResults,
+				LedgerIndex: page.LedgerIndex,
+				OutputIDs:   make([]OutputIDHex, 0, len(page.OutputIDs)),
+			}
+		}
+		merged.OutputIDs = append(merged.OutputIDs, page.OutputIDs...)
+		merged.Count += page.Count
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// MessageIDsByIndexIterator pages through the IDs of messages with a given index.
+type MessageIDsByIndexIterator struct {
+	pageIterator
+	page *MessageIDsByIndexResponse
+}
+
+// creates a new MessageIDsByIndexIterator for the given index, issuing its requests via the given do function.
+func newMessageIDsByIndexIterator(do nodeAPIDoFunc, index []byte) *MessageIDsByIndexIterator {
+	it := &MessageIDsByIndexIterator{}
+	it.query = url.Values{"index": []string{hex.EncodeToString(index)}}
+	it.fetch = func(ctx context.Context, query url.Values) (*resultsPage, error) {
+		res := &MessageIDsByIndexResponse{}
+		if _, err := do(ctx, http.MethodGet, routeWithQuery(NodeAPIRouteMessages, query), nil, res); err != nil {
+			return nil, err
+		}
+		it.page = res
+		return &resultsPage{maxResults: res.MaxResults, count: res.Count, cursor: res.Cursor}, nil
+	}
+	return it
+}
+
+// Next fetches the next page and returns whether there was one. It returns false once all pages were fetched or an error occurred.
+func (it *MessageIDsByIndexIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Page returns the page fetched by the last call to Next.
+func (it *MessageIDsByIndexIterator) Page() *MessageIDsByIndexResponse {
+	return it.page
+}
+
+// Err returns the error which stopped the iteration. It wraps ErrResultsTruncated if the node truncated the last page
+// without providing a cursor, in which case the truncated page is still returned by Page.
+func (it *MessageIDsByIndexIterator) Err() error {
+	return it.err
+}
+
+// all fetches the remaining pages and merges them into a single response.
+func (it *MessageIDsByIndexIterator) all(ctx context.Context) (*MessageIDsByIndexResponse, error) {
+	var merged *MessageIDsByIndexResponse
+	for it.Next(ctx) {
+		page := it.Page()
+		if merged == nil {
+			merged = &MessageIDsByIndexResponse{
+				Index:      page.Index,
+				MaxResults: page.MaxResults,
+				MessageIDs: make([]string, 0, len(page.MessageIDs)),
+			}
+		}
+		merged.MessageIDs = append(merged.MessageIDs, page.MessageIDs...)
+		merged.Count += page.Count
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// fetches the outputs with the given IDs from the given NodeAPI using the given amount of concurrent requests.
+func fetchOutputs(ctx context.Context, nodeAPI NodeAPI, outputIDs []OutputIDHex, concurrency int) (map[*UTXOInput]Output, error) {
+	utxoInputs := make([]*UTXOInput, len(outputIDs))
+	for i, outputIDHex := range outputIDs {
+		utxoInput, err := outputIDHex.AsUTXOInput()
+		if err != nil {
+			return nil, err
+		}
+		utxoInputs[i] = utxoInput
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		outputs  = make(map[*UTXOInput]Output, len(utxoInputs))
+		work     = make(chan *UTXOInput)
+	)
+
+	for i := 0; i < concurrency && i < len(utxoInputs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for utxoInput := range work {
+				output, err := fetchOutput(ctx, nodeAPI, utxoInput)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else {
+					outputs[utxoInput] = output
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, utxoInput := range utxoInputs {
+		select {
+		case work <- utxoInput:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// fetches the output with the given ID.
+func fetchOutput(ctx context.Context, nodeAPI NodeAPI, utxoInput *UTXOInput) (Output, error) {
+	outputRes, err := nodeAPI.OutputByID(ctx, utxoInput.ID())
+	if err != nil {
+		return nil, err
+	}
+	return outputRes.Output()
+}
@@ -0,0 +1,188 @@
+package iotago_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/iotaledger/iota.go/v2/tpkg"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+func randOutputIDHexes(count int) []iotago.OutputIDHex {
+	outputIDs := make([]iotago.OutputIDHex, count)
+	for i := range outputIDs {
+		utxoInput := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray(), TransactionOutputIndex: uint16(i)}
+		outputIDs[i] = iotago.OutputIDHex(utxoInput.ID().ToHex())
+	}
+	return outputIDs
+}
+
+func TestNodeHTTPAPIClient_OutputIDsPaging(t *testing.T) {
+	defer gock.Off()
+
+	ed25519Addr, _ := tpkg.RandEd25519Address()
+	route := fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Outputs, ed25519Addr.String())
+	outputIDs := randOutputIDHexes(3)
+	cursor := "next-page"
+
+	gock.New(nodeAPIUrl).
+		Get(route).
+		MatchParam("include-spent", "true").
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+			AddressType: iotago.AddressEd25519,
+			Address:     ed25519Addr.String(),
+			MaxResults:  2,
+			Count:       2,
+			OutputIDs:   outputIDs[:2],
+			LedgerIndex: 1337,
+			Cursor:      &cursor,
+		}})
+	gock.New(nodeAPIUrl).
+		Get(route).
+		MatchParam("include-spent", "true").
+		MatchParam(iotago.NodeAPIQueryParamCursor, cursor).
+		MatchParam(iotago.NodeAPIQueryParamLedgerIndex, "1337").
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+			AddressType: iotago.AddressEd25519,
+			Address:     ed25519Addr.String(),
+			MaxResults:  2,
+			Count:       1,
+			OutputIDs:   outputIDs[2:],
+			LedgerIndex: 1337,
+		}})
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl)
+	res, err := nodeAPI.OutputIDsByEd25519Address(context.Background(), ed25519Addr, true)
+	require.NoError(t, err)
+	require.True(t, gock.IsDone())
+	require.EqualValues(t, &iotago.AddressOutputsResponse{
+		AddressType: iotago.AddressEd25519,
+		Address:     ed25519Addr.String(),
+		MaxResults:  2,
+		Count:       3,
+		OutputIDs:   outputIDs,
+		LedgerIndex: 1337,
+	}, res)
+}
+
+func TestNodeHTTPAPIClient_OutputIDsTruncated(t *testing.T) {
+	defer gock.Off()
+
+	ed25519Addr, _ := tpkg.RandEd25519Address()
+	route := fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Outputs, ed25519Addr.String())
+	outputIDs := randOutputIDHexes(2)
+
+	gock.New(nodeAPIUrl).
+		Get(route).
+		Times(2).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+			MaxResults: 2,
+			Count:      2,
+			OutputIDs:  outputIDs,
+		}})
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl)
+	_, err := nodeAPI.OutputIDsByEd25519Address(context.Background(), ed25519Addr, false)
+	require.True(t, errors.Is(err, iotago.ErrResultsTruncated))
+
+	// the truncated page is still available through the iterator
+	it := nodeAPI.OutputIDsByEd25519AddressIterator(ed25519Addr, false)
+	require.True(t, it.Next(context.Background()))
+	require.NoError(t, it.Err())
+	require.Equal(t, outputIDs, it.Page().OutputIDs)
+	require.False(t, it.Next(context.Background()))
+	require.True(t, errors.Is(it.Err(), iotago.ErrResultsTruncated))
+	require.False(t, it.Next(context.Background()))
+}
+
+func TestNodeHTTPAPIClient_MessageIDsByIndexPaging(t *testing.T) {
+	defer gock.Off()
+
+	index := []byte("paged")
+	cursor := "next-page"
+	msgID1, msgID2 := tpkg.Rand32ByteArray(), tpkg.Rand32ByteArray()
+	msgIDs := []string{hex.EncodeToString(msgID1[:]), hex.EncodeToString(msgID2[:])}
+
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteMessages).
+		MatchParam("index", hex.EncodeToString(index)).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MessageIDsByIndexResponse{
+			Index:      hex.EncodeToString(index),
+			MaxResults: 2,
+			Count:      1,
+			MessageIDs: msgIDs[:1],
+			Cursor:     &cursor,
+		}})
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteMessages).
+		MatchParam("index", hex.EncodeToString(index)).
+		MatchParam(iotago.NodeAPIQueryParamCursor, cursor).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MessageIDsByIndexResponse{
+			Index:      hex.EncodeToString(index),
+			MaxResults: 2,
+			Count:      1,
+			MessageIDs: msgIDs[1:],
+		}})
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl)
+	it := nodeAPI.MessageIDsByIndexIterator(index)
+
+	var pages int
+	var gotMsgIDs []string
+	for it.Next(context.Background()) {
+		pages++
+		gotMsgIDs = append(gotMsgIDs, it.Page().MessageIDs...)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, 2, pages)
+	require.Equal(t, msgIDs, gotMsgIDs)
+	require.True(t, gock.IsDone())
+}
+
+func TestNodeHTTPAPIClient_OutputsByIDs(t *testing.T) {
+	defer gock.Off()
+
+	outputIDs := randOutputIDHexes(20)
+	for _, outputID := range outputIDs {
+		output, _ := tpkg.RandSigLockedSingleOutput(iotago.AddressEd25519)
+		outputJSON, err := output.MarshalJSON()
+		require.NoError(t, err)
+		rawOutput := json.RawMessage(outputJSON)
+
+		gock.New(nodeAPIUrl).
+			Get(fmt.Sprintf(iotago.NodeAPIRouteOutput, outputID)).
+			Reply(200).
+			JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeOutputResponse{RawOutput: &rawOutput}})
+	}
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientOutputFetchConcurrency(4))
+	outputs, err := nodeAPI.OutputsByIDs(context.Background(), outputIDs)
+	require.NoError(t, err)
+	require.Len(t, outputs, len(outputIDs))
+	for utxoInput := range outputs {
+		require.Contains(t, outputIDs, iotago.OutputIDHex(utxoInput.ID().ToHex()))
+	}
+	require.True(t, gock.IsDone())
+
+	// the first failing output fails the whole fetch
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteOutput, outputIDs[0])).
+		Reply(404).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+
+	_, err = nodeAPI.OutputsByIDs(context.Background(), outputIDs[:1])
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotFound))
+}
@@ -0,0 +1,684 @@
+package iotago
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNodeHTTPAPIClientPoolEmpty gets returned when a NodeHTTPAPIClientPool is created without clients.
+	ErrNodeHTTPAPIClientPoolEmpty = errors.New("node HTTP API client pool needs at least one client")
+	// ErrNodeHTTPAPIClientPoolNoQuorum gets returned when the nodes asked in a quorum read do not agree on an answer.
+	ErrNodeHTTPAPIClientPoolNoQuorum = errors.New("nodes did not reach a quorum")
+)
+
+// NodeAPI is the method set of a client for the node HTTP REST API.
+// It is implemented by NodeHTTPAPIClient and NodeHTTPAPIClientPool.
+type NodeAPI interface {
+	Do(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error)
+	Health(ctx context.Context) (bool, error)
+	Info(ctx context.Context) (*NodeInfoResponse, error)
+	Tips(ctx context.Context) (*NodeTipsResponse, error)
+	SubmitMessage(ctx context.Context, m *Message) (*Message, error)
+	MessageIDsByIndex(ctx context.Context, index []byte) (*MessageIDsByIndexResponse, error)
+	MessageIDsByIndexIterator(index []byte) *MessageIDsByIndexIterator
+	MessageMetadataByMessageID(ctx context.Context, msgID MessageID) (*MessageMetadataResponse, error)
+	MessageJSONByMessageID(ctx context.Context, msgID MessageID) (*Message, error)
+	MessageByMessageID(ctx context.Context, msgID MessageID) (*Message, error)
+	ChildrenByMessageID(ctx context.Context, msgID MessageID) (*ChildrenResponse, error)
+	OutputByID(ctx context.Context, utxoID UTXOInputID) (*NodeOutputResponse, error)
+	BalanceByBech32Address(ctx context.Context, bech32Addr string) (*AddressBalanceResponse, error)
+	BalanceByEd25519Address(ctx context.Context, addr *Ed25519Address) (*AddressBalanceResponse, error)
+	OutputIDsByBech32Address(ctx context.Context, bech32Addr string, includeSpentOutputs bool) (*AddressOutputsResponse, error)
+	OutputIDsByBech32AddressIterator(bech32Addr string, includeSpentOutputs bool) *AddressOutputsIterator
+	OutputsByBech32Address(ctx context.Context, bech32Addr string, includeSpentOutputs bool) (*AddressOutputsResponse, map[*UTXOInput]Output, error)
+	OutputIDsByEd25519Address(ctx context.Context, addr *Ed25519Address, includeSpentOutputs bool) (*AddressOutputsResponse, error)
+	OutputIDsByEd25519AddressIterator(addr *Ed25519Address, includeSpentOutputs bool) *AddressOutputsIterator
+	OutputsByEd25519Address(ctx context.Context, addr *Ed25519Address, includeSpentOutputs bool) (*AddressOutputsResponse, map[*UTXOInput]Output, error)
+	OutputsByIDs(ctx context.Context, outputIDs []OutputIDHex) (map[*UTXOInput]Output, error)
+	Treasury(ctx context.Context) (*TreasuryResponse, error)
+	Receipts(ctx context.Context) ([]*ReceiptTuple, error)
+	ReceiptsByMigratedAtIndex(ctx context.Context, index uint32) ([]*ReceiptTuple, error)
+	MilestoneByIndex(ctx context.Context, index uint32) (*MilestoneResponse, error)
+	MilestoneUTXOChangesByIndex(ctx context.Context, index uint32) (*MilestoneUTXOChangesResponse, error)
+	PeerByID(ctx context.Context, id string) (*PeerResponse, error)
+	RemovePeerByID(ctx context.Context, id string) error
+	Peers(ctx context.Context) ([]*PeerResponse, error)
+	AddPeer(ctx context.Context, multiAddress string, alias ...string) (*PeerResponse, error)
+}
+
+var (
+	_ NodeAPI = &NodeHTTPAPIClient{}
+	_ NodeAPI = &NodeHTTPAPIClientPool{}
+)
+
+// NodeHTTPAPIClientPoolOption is a function setting a NodeHTTPAPIClientPool option.
+type NodeHTTPAPIClientPoolOption func(opts *NodeHTTPAPIClientPoolOptions)
+
+// NodeHTTPAPIClientPoolOptions define options for the NodeHTTPAPIClientPool.
+type NodeHTTPAPIClientPoolOptions struct {
+	// The interval in which the health and sync state of the nodes is refreshed.
+	healthCheckInterval time.Duration
+	// The max. difference between the latest and confirmed milestone index of a synced node.
+	maxMilestoneLag uint32
+	// The amount of nodes asked in a quorum read, 0 disables quorum reads.
+	quorumSize int
+}
+
+// applies the given NodeHTTPAPIClientPoolOption.
+func (po *NodeHTTPAPIClientPoolOptions) apply(opts ...NodeHTTPAPIClientPoolOption) {
+	for _, opt := range opts {
+		opt(po)
+	}
+}
+
+// WithNodeHTTPAPIClientPoolHealthCheckInterval defines the interval in which the health and sync state of the nodes
+// is refreshed. The refresh happens lazily before a request is routed.
+func WithNodeHTTPAPIClientPoolHealthCheckInterval(interval time.Duration) NodeHTTPAPIClientPoolOption {
+	return func(opts *NodeHTTPAPIClientPoolOptions) {
+		opts.healthCheckInterval = interval
+	}
+}
+
+// WithNodeHTTPAPIClientPoolMaxMilestoneLag defines the max. difference between the latest and confirmed milestone index
+// of a node for it to be considered synced.
+func WithNodeHTTPAPIClientPoolMaxMilestoneLag(lag uint32) NodeHTTPAPIClientPoolOption {
+	return func(opts *NodeHTTPAPIClientPoolOptions) {
+		opts.maxMilestoneLag = lag
+	}
+}
+
+// WithNodeHTTPAPIClientPoolQuorum enables quorum reads for BalanceByEd25519Address and OutputByID.
+// A quorum read asks the given amount of nodes and only accepts an answer which the majority of them agree on.
+// A size of 0 disables quorum reads.
+func WithNodeHTTPAPIClientPoolQuorum(size int) NodeHTTPAPIClientPoolOption {
+	return func(opts *NodeHTTPAPIClientPoolOptions) {
+		opts.quorumSize = size
+	}
+}
+
+// the default options applied to the NodeHTTPAPIClientPool.
+var defaultNodeHTTPAPIClientPoolOptions = []NodeHTTPAPIClientPoolOption{
+	WithNodeHTTPAPIClientPoolHealthCheckInterval(30 * time.Second),
+	WithNodeHTTPAPIClientPoolMaxMilestoneLag(2),
+	WithNodeHTTPAPIClientPoolQuorum(0),
+}
+
+// NewNodeHTTPAPIClientPool creates a new NodeHTTPAPIClientPool routing requests to the given clients.
+func NewNodeHTTPAPIClientPool(clients []*NodeHTTPAPIClient, opts ...NodeHTTPAPIClientPoolOption) (*NodeHTTPAPIClientPool, error) {
+	if len(clients) == 0 {
+		return nil, ErrNodeHTTPAPIClientPoolEmpty
+	}
+
+	options := &NodeHTTPAPIClientPoolOptions{}
+	options.apply(defaultNodeHTTPAPIClientPoolOptions...)
+	options.apply(opts...)
+
+	p := &NodeHTTPAPIClientPool{opts: options, nodes: make([]*poolNode, len(clients))}
+	for i, client := range clients {
+		// nodes are assumed to be healthy until the first health check
+		p.nodes[i] = &poolNode{client: client, healthy: true, synced: true}
+	}
+	return p, nil
+}
+
+// NodeHTTPAPIClientPool routes node HTTP REST API calls to a set of nodes.
+// Requests go to the healthy and synced node with the highest confirmed milestone index and fail over to the
+// next node on ErrHTTPInternalServerError or transport errors. A node failing a request is considered unhealthy
+// until the next health check.
+type NodeHTTPAPIClientPool struct {
+	mu        sync.Mutex
+	opts      *NodeHTTPAPIClientPoolOptions
+	nodes     []*poolNode
+	lastCheck time.Time
+}
+
+// a node within a NodeHTTPAPIClientPool.
+type poolNode struct {
+	client         *NodeHTTPAPIClient
+	healthy        bool
+	synced         bool
+	confirmedIndex uint32
+}
+
+// NodeHTTPAPIClientPoolNodeState is the state of a node within a NodeHTTPAPIClientPool.
+type NodeHTTPAPIClientPoolNodeState struct {
+	// The base URL of the node.
+	BaseURL string
+	// Whether the node is healthy.
+	Healthy bool
+	// Whether the node is synced.
+	Synced bool
+	// The confirmed milestone index of the node as of the last health check.
+	ConfirmedMilestoneIndex uint32
+	// The client of the node, i.e. to manage its peers.
+	Client *NodeHTTPAPIClient
+}
+
+// Nodes returns the state of the nodes in the order in which requests are routed to them.
+func (p *NodeHTTPAPIClientPool) Nodes() []*NodeHTTPAPIClientPoolNodeState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	states := make([]*NodeHTTPAPIClientPoolNodeState, len(p.nodes))
+	for i, n := range p.nodes {
+		states[i] = &NodeHTTPAPIClientPoolNodeState{
+			BaseURL:                 n.client.BaseURL,
+			Healthy:                 n.healthy,
+			Synced:                  n.synced,
+			ConfirmedMilestoneIndex: n.confirmedIndex,
+			Client:                  n.client,
+		}
+	}
+	return states
+}
+
+// CheckHealth refreshes the health and sync state of all nodes and re-orders them accordingly.
+func (p *NodeHTTPAPIClientPool) CheckHealth(ctx context.Context) {
+	p.mu.Lock()
+	nodes := make([]*poolNode, len(p.nodes))
+	copy(nodes, p.nodes)
+	p.mu.Unlock()
+
+	type check struct {
+		healthy, synced bool
+		confirmedIndex  uint32
+	}
+	checks := make([]check, len(nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n *poolNode) {
+			defer wg.Done()
+			healthy, err := n.client.Health(ctx)
+			if err != nil || !healthy {
+				return
+			}
+			info, err := n.client.Info(ctx)
+			if err != nil {
+				return
+			}
+			checks[i] = check{
+				healthy:        info.IsHealthy,
+				synced:         info.LatestMilestoneIndex <= info.ConfirmedMilestoneIndex+p.opts.maxMilestoneLag,
+				confirmedIndex: info.ConfirmedMilestoneIndex,
+			}
+		}(i, n)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, n := range nodes {
+		n.healthy, n.synced, n.confirmedIndex = checks[i].healthy, checks[i].synced, checks[i].confirmedIndex
+	}
+	p.lastCheck = time.Now()
+	p.sortNodes()
+}
+
+// orders the nodes by health, sync state and confirmed milestone index.
+func (p *NodeHTTPAPIClientPool) sortNodes() {
+	rank := func(n *poolNode) int {
+		switch {
+		case n.healthy && n.synced:
+			return 0
+		case n.healthy:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(p.nodes, func(i, j int) bool {
+		if rankI, rankJ := rank(p.nodes[i]), rank(p.nodes[j]); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return p.nodes[i].confirmedIndex > p.nodes[j].confirmedIndex
+	})
+}
+
+// returns the nodes in routing order, refreshing their state first if the health check interval passed.
+func (p *NodeHTTPAPIClientPool) routingOrder(ctx context.Context) []*poolNode {
+	p.mu.Lock()
+	stale := time.Since(p.lastCheck) > p.opts.healthCheckInterval
+	p.mu.Unlock()
+
+	if stale {
+		p.CheckHealth(ctx)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	nodes := make([]*poolNode, len(p.nodes))
+	copy(nodes, p.nodes)
+	return nodes
+}
+
+// marks the given node as unhealthy until the next health check.
+func (p *NodeHTTPAPIClientPool) markUnhealthy(node *poolNode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	node.healthy = false
+	p.sortNodes()
+}
+
+// tells whether the given error justifies trying the request against another node.
+func isNodeFailoverError(err error) bool {
+	if errors.Is(err, ErrHTTPInternalServerError) || errors.Is(err, ErrHTTPServiceUnavailable) || errors.Is(err, ErrHTTPTooManyRequests) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// tells whether the given error shows that the request never reached the node, i.e. as the connection was refused.
+func isNodeUnreachedError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// runs the given non-idempotent call against the nodes in routing order until it succeeds or fails with
+// an error which doesn't rule out that the call reached the node.
+func (p *NodeHTTPAPIClientPool) doUnreached(ctx context.Context, call func(client *NodeHTTPAPIClient) error) error {
+	var err error
+	for _, node := range p.routingOrder(ctx) {
+		if err = call(node.client); err == nil || !isNodeUnreachedError(err) || ctx.Err() != nil {
+			if err != nil && isNodeFailoverError(err) {
+				p.markUnhealthy(node)
+			}
+			return err
+		}
+		p.markUnhealthy(node)
+	}
+	return err
+}
+
+// runs the given call against the nodes in routing order until it succeeds or fails with a non failover error.
+func (p *NodeHTTPAPIClientPool) do(ctx context.Context, call func(client *NodeHTTPAPIClient) error) error {
+	var err error
+	for _, node := range p.routingOrder(ctx) {
+		if err = call(node.client); err == nil || !isNodeFailoverError(err) || ctx.Err() != nil {
+			return err
+		}
+		p.markUnhealthy(node)
+	}
+	return err
+}
+
+// returns a do function which routes its first request through the pool and pins all following ones
+// to the node which served the first one.
+func (p *NodeHTTPAPIClientPool) pinnedDo() nodeAPIDoFunc {
+	var pinned *NodeHTTPAPIClient
+	return func(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error) {
+		if pinned != nil {
+			return pinned.Do(ctx, method, route, reqObj, resObj)
+		}
+		var res *http.Response
+		err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+			if res, err = client.Do(ctx, method, route, reqObj, resObj); err == nil {
+				pinned = client
+			}
+			return
+		})
+		return res, err
+	}
+}
+
+// runs the given call against the configured amount of nodes and returns the index of the result which
+// the majority agrees on according to the given equal function.
+func (p *NodeHTTPAPIClientPool) quorum(ctx context.Context, call func(i int, client *NodeHTTPAPIClient) error, equal func(i, j int) bool) (int, error) {
+	nodes := p.routingOrder(ctx)
+	size := p.opts.quorumSize
+	if size > len(nodes) {
+		size = len(nodes)
+	}
+	nodes = nodes[:size]
+
+	errs := make([]error, size)
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node *poolNode) {
+			defer wg.Done()
+			if errs[i] = call(i, node.client); errs[i] != nil && isNodeFailoverError(errs[i]) {
+				p.markUnhealthy(node)
+			}
+		}(i, node)
+	}
+	wg.Wait()
+
+	majority := size/2 + 1
+	var lastErr error
+	for i := range nodes {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		agreeing := 0
+		for j := range nodes {
+			if errs[j] == nil && equal(i, j) {
+				agreeing++
+			}
+		}
+		if agreeing >= majority {
+			return i, nil
+		}
+	}
+
+	if lastErr != nil {
+		return 0, fmt.Errorf("%w: %d nodes asked, majority of %d needed, last error: %s", ErrNodeHTTPAPIClientPoolNoQuorum, size, majority, lastErr)
+	}
+	return 0, fmt.Errorf("%w: %d nodes asked, majority of %d needed", ErrNodeHTTPAPIClientPoolNoQuorum, size, majority)
+}
+
+// Do routes the given request through the pool. Requests other than GET and HEAD are not idempotent
+// and therefore only fail over to the next node if the node could not be connected to.
+func (p *NodeHTTPAPIClientPool) Do(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) (*http.Response, error) {
+	do := p.do
+	if method != http.MethodGet && method != http.MethodHead {
+		do = p.doUnreached
+	}
+	var res *http.Response
+	err := do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.Do(ctx, method, route, reqObj, resObj)
+		return
+	})
+	return res, err
+}
+
+// Health returns whether any node of the pool is healthy.
+func (p *NodeHTTPAPIClientPool) Health(ctx context.Context) (bool, error) {
+	p.CheckHealth(ctx)
+	for _, node := range p.Nodes() {
+		if node.Healthy {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *NodeHTTPAPIClientPool) Info(ctx context.Context) (*NodeInfoResponse, error) {
+	var res *NodeInfoResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.Info(ctx)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) Tips(ctx context.Context) (*NodeTipsResponse, error) {
+	var res *NodeTipsResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.Tips(ctx)
+		return
+	})
+	return res, err
+}
+
+// SubmitMessage submits the given message to a node of the pool and fetches the resulting message from that node.
+// The submission only fails over to the next node if the node could not be connected to, as a node
+// which received the message might have already accepted it, i.e. filling in its own tips as parents.
+func (p *NodeHTTPAPIClientPool) SubmitMessage(ctx context.Context, m *Message) (*Message, error) {
+	var accepting *NodeHTTPAPIClient
+	var messageID MessageID
+	if err := p.doUnreached(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		if messageID, err = client.submitMessage(ctx, m); err == nil {
+			accepting = client
+		}
+		return
+	}); err != nil {
+		return nil, err
+	}
+	return accepting.MessageByMessageID(ctx, messageID)
+}
+
+func (p *NodeHTTPAPIClientPool) MessageIDsByIndex(ctx context.Context, index []byte) (*MessageIDsByIndexResponse, error) {
+	var res *MessageIDsByIndexResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.MessageIDsByIndex(ctx, index)
+		return
+	})
+	return res, err
+}
+
+// MessageIDsByIndexIterator returns a MessageIDsByIndexIterator whose first page is routed through the pool.
+// The following pages are fetched from the node which served the first one, as its cursor is only valid on that node.
+func (p *NodeHTTPAPIClientPool) MessageIDsByIndexIterator(index []byte) *MessageIDsByIndexIterator {
+	return newMessageIDsByIndexIterator(p.pinnedDo(), index)
+}
+
+func (p *NodeHTTPAPIClientPool) MessageMetadataByMessageID(ctx context.Context, msgID MessageID) (*MessageMetadataResponse, error) {
+	var res *MessageMetadataResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.MessageMetadataByMessageID(ctx, msgID)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) MessageJSONByMessageID(ctx context.Context, msgID MessageID) (*Message, error) {
+	var res *Message
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.MessageJSONByMessageID(ctx, msgID)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) MessageByMessageID(ctx context.Context, msgID MessageID) (*Message, error) {
+	var res *Message
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.MessageByMessageID(ctx, msgID)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) ChildrenByMessageID(ctx context.Context, msgID MessageID) (*ChildrenResponse, error) {
+	var res *ChildrenResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.ChildrenByMessageID(ctx, msgID)
+		return
+	})
+	return res, err
+}
+
+// OutputByID gets an output by its ID. If quorum reads are enabled, the output is only returned
+// if the majority of the asked nodes agree on it.
+func (p *NodeHTTPAPIClientPool) OutputByID(ctx context.Context, utxoID UTXOInputID) (*NodeOutputResponse, error) {
+	if p.opts.quorumSize == 0 {
+		var res *NodeOutputResponse
+		err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+			res, err = client.OutputByID(ctx, utxoID)
+			return
+		})
+		return res, err
+	}
+
+	results := make([]*NodeOutputResponse, p.opts.quorumSize)
+	i, err := p.quorum(ctx, func(i int, client *NodeHTTPAPIClient) (err error) {
+		results[i], err = client.OutputByID(ctx, utxoID)
+		return
+	}, func(i, j int) bool {
+		// the ledger index is not compared as the nodes may be at different milestones
+		a, b := results[i], results[j]
+		return a.MessageID == b.MessageID && a.TransactionID == b.TransactionID && a.OutputIndex == b.OutputIndex &&
+			a.Spent == b.Spent && a.RawOutput != nil && b.RawOutput != nil && bytes.Equal(*a.RawOutput, *b.RawOutput)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results[i], nil
+}
+
+func (p *NodeHTTPAPIClientPool) BalanceByBech32Address(ctx context.Context, bech32Addr string) (*AddressBalanceResponse, error) {
+	var res *AddressBalanceResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.BalanceByBech32Address(ctx, bech32Addr)
+		return
+	})
+	return res, err
+}
+
+// BalanceByEd25519Address returns the balance of an Ed25519Address. If quorum reads are enabled, the balance is only
+// returned if the majority of the asked nodes agree on it.
+func (p *NodeHTTPAPIClientPool) BalanceByEd25519Address(ctx context.Context, addr *Ed25519Address) (*AddressBalanceResponse, error) {
+	if p.opts.quorumSize == 0 {
+		var res *AddressBalanceResponse
+		err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+			res, err = client.BalanceByEd25519Address(ctx, addr)
+			return
+		})
+		return res, err
+	}
+
+	results := make([]*AddressBalanceResponse, p.opts.quorumSize)
+	i, err := p.quorum(ctx, func(i int, client *NodeHTTPAPIClient) (err error) {
+		results[i], err = client.BalanceByEd25519Address(ctx, addr)
+		return
+	}, func(i, j int) bool {
+		// the ledger index is not compared as the nodes may be at different milestones
+		a, b := results[i], results[j]
+		return a.AddressType == b.AddressType && a.Address == b.Address && a.Balance == b.Balance && a.DustAllowed == b.DustAllowed
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results[i], nil
+}
+
+func (p *NodeHTTPAPIClientPool) OutputIDsByBech32Address(ctx context.Context, bech32Addr string, includeSpentOutputs bool) (*AddressOutputsResponse, error) {
+	var res *AddressOutputsResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.OutputIDsByBech32Address(ctx, bech32Addr, includeSpentOutputs)
+		return
+	})
+	return res, err
+}
+
+// OutputIDsByBech32AddressIterator returns an AddressOutputsIterator whose first page is routed through the pool.
+// The following pages are fetched from the node which served the first one, as its cursor and ledger index only apply to that node.
+func (p *NodeHTTPAPIClientPool) OutputIDsByBech32AddressIterator(bech32Addr string, includeSpentOutputs bool) *AddressOutputsIterator {
+	return newAddressOutputsIterator(p.pinnedDo(), addressOutputsRoute(NodeAPIRouteAddressBech32Outputs, bech32Addr, includeSpentOutputs))
+}
+
+func (p *NodeHTTPAPIClientPool) OutputsByBech32Address(ctx context.Context, bech32Addr string, includeSpentOutputs bool) (*AddressOutputsResponse, map[*UTXOInput]Output, error) {
+	var res *AddressOutputsResponse
+	var outputs map[*UTXOInput]Output
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, outputs, err = client.OutputsByBech32Address(ctx, bech32Addr, includeSpentOutputs)
+		return
+	})
+	return res, outputs, err
+}
+
+func (p *NodeHTTPAPIClientPool) OutputIDsByEd25519Address(ctx context.Context, addr *Ed25519Address, includeSpentOutputs bool) (*AddressOutputsResponse, error) {
+	var res *AddressOutputsResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.OutputIDsByEd25519Address(ctx, addr, includeSpentOutputs)
+		return
+	})
+	return res, err
+}
+
+// OutputIDsByEd25519AddressIterator returns an AddressOutputsIterator whose first page is routed through the pool.
+// The following pages are fetched from the node which served the first one, as its cursor and ledger index only apply to that node.
+func (p *NodeHTTPAPIClientPool) OutputIDsByEd25519AddressIterator(addr *Ed25519Address, includeSpentOutputs bool) *AddressOutputsIterator {
+	return newAddressOutputsIterator(p.pinnedDo(), addressOutputsRoute(NodeAPIRouteAddressEd25519Outputs, addr.String(), includeSpentOutputs))
+}
+
+func (p *NodeHTTPAPIClientPool) OutputsByEd25519Address(ctx context.Context, addr *Ed25519Address, includeSpentOutputs bool) (*AddressOutputsResponse, map[*UTXOInput]Output, error) {
+	var res *AddressOutputsResponse
+	var outputs map[*UTXOInput]Output
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, outputs, err = client.OutputsByEd25519Address(ctx, addr, includeSpentOutputs)
+		return
+	})
+	return res, outputs, err
+}
+
+// OutputsByIDs gets the outputs with the given IDs concurrently, every output being routed through the pool individually.
+func (p *NodeHTTPAPIClientPool) OutputsByIDs(ctx context.Context, outputIDs []OutputIDHex) (map[*UTXOInput]Output, error) {
+	return fetchOutputs(ctx, p, outputIDs, DefaultOutputFetchConcurrency)
+}
+
+func (p *NodeHTTPAPIClientPool) Treasury(ctx context.Context) (*TreasuryResponse, error) {
+	var res *TreasuryResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.Treasury(ctx)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) Receipts(ctx context.Context) ([]*ReceiptTuple, error) {
+	var res []*ReceiptTuple
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.Receipts(ctx)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) ReceiptsByMigratedAtIndex(ctx context.Context, index uint32) ([]*ReceiptTuple, error) {
+	var res []*ReceiptTuple
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.ReceiptsByMigratedAtIndex(ctx, index)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) MilestoneByIndex(ctx context.Context, index uint32) (*MilestoneResponse, error) {
+	var res *MilestoneResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.MilestoneByIndex(ctx, index)
+		return
+	})
+	return res, err
+}
+
+func (p *NodeHTTPAPIClientPool) MilestoneUTXOChangesByIndex(ctx context.Context, index uint32) (*MilestoneUTXOChangesResponse, error) {
+	var res *MilestoneUTXOChangesResponse
+	err := p.do(ctx, func(client *NodeHTTPAPIClient) (err error) {
+		res, err = client.MilestoneUTXOChangesByIndex(ctx, index)
+		return
+	})
+	return res, err
+}
+
+// PeerByID returns an error wrapping ErrHTTPNotImplemented as peers are managed per node.
+// Use the client of a specific node from NodeHTTPAPIClientPool.Nodes instead.
+func (p *NodeHTTPAPIClientPool) PeerByID(ctx context.Context, id string) (*PeerResponse, error) {
+	return nil, errPoolPeerManagement("PeerByID")
+}
+
+// RemovePeerByID returns an error wrapping ErrHTTPNotImplemented as peers are managed per node.
+// Use the client of a specific node from NodeHTTPAPIClientPool.Nodes instead.
+func (p *NodeHTTPAPIClientPool) RemovePeerByID(ctx context.Context, id string) error {
+	return errPoolPeerManagement("RemovePeerByID")
+}
+
+// Peers returns an error wrapping ErrHTTPNotImplemented as peers are managed per node.
+// Use the client of a specific node from NodeHTTPAPIClientPool.Nodes instead.
+func (p *NodeHTTPAPIClientPool) Peers(ctx context.Context) ([]*PeerResponse, error) {
+	return nil, errPoolPeerManagement("Peers")
+}
+
+// AddPeer returns an error wrapping ErrHTTPNotImplemented as peers are managed per node.
+// Use the client of a specific node from NodeHTTPAPIClientPool.Nodes instead.
+func (p *NodeHTTPAPIClientPool) AddPeer(ctx context.Context, multiAddress string, alias ...string) (*PeerResponse, error) {
+	return nil, errPoolPeerManagement("AddPeer")
+}
+
+// returns the error for peer management calls, which can't be routed to an arbitrary node of the pool.
+func errPoolPeerManagement(method string) error {
+	return fmt.Errorf("%w: %s is not supported by the pool as peers are managed per node, use the client of a specific node from Nodes", ErrHTTPNotImplemented, method)
+}
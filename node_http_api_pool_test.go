@@ -0,0 +1,282 @@
+package iotago_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+// mocks the health and info routes of the node with the given URL.
+func mockPoolNodeHealth(nodeURL string, latestIndex uint32, confirmedIndex uint32) {
+	gock.New(nodeURL).
+		Get(iotago.NodeAPIRouteHealth).
+		Reply(200)
+
+	gock.New(nodeURL).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeInfoResponse{
+			IsHealthy:               true,
+			LatestMilestoneIndex:    latestIndex,
+			ConfirmedMilestoneIndex: confirmedIndex,
+		}})
+}
+
+func newTestNodeHTTPAPIClientPool(t *testing.T, nodeURLs []string, opts ...iotago.NodeHTTPAPIClientPoolOption) *iotago.NodeHTTPAPIClientPool {
+	clients := make([]*iotago.NodeHTTPAPIClient, len(nodeURLs))
+	for i, nodeURL := range nodeURLs {
+		clients[i] = iotago.NewNodeHTTPAPIClient(nodeURL)
+	}
+	pool, err := iotago.NewNodeHTTPAPIClientPool(clients, opts...)
+	require.NoError(t, err)
+	return pool
+}
+
+func TestNodeHTTPAPIClientPool_Failover(t *testing.T) {
+	defer gock.Off()
+
+	const node1, node2, node3 = "http://node1:14265", "http://node2:14265", "http://node3:14265"
+
+	// node1 is not synced, node2 is ahead of node3
+	mockPoolNodeHealth(node1, 100, 90)
+	mockPoolNodeHealth(node2, 100, 100)
+	mockPoolNodeHealth(node3, 99, 99)
+
+	gock.New(node2).
+		Get(iotago.NodeAPIRouteTips).
+		Reply(500).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+
+	originRes := &iotago.NodeTipsResponse{TipsHex: []string{iotago.MessageIDToHexString(tpkg.Rand32ByteArray())}}
+	gock.New(node3).
+		Get(iotago.NodeAPIRouteTips).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: originRes})
+
+	pool := newTestNodeHTTPAPIClientPool(t, []string{node1, node2, node3})
+	tips, err := pool.Tips(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, originRes, tips)
+	require.True(t, gock.IsDone())
+
+	nodes := pool.Nodes()
+	require.Equal(t, node3, nodes[0].BaseURL)
+	require.Equal(t, node1, nodes[1].BaseURL)
+	require.False(t, nodes[1].Synced)
+	require.Equal(t, node2, nodes[2].BaseURL)
+	require.False(t, nodes[2].Healthy)
+
+	// non failover errors are returned right away
+	gock.New(node3).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMilestone, "5")).
+		Reply(404).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+
+	_, err = pool.MilestoneByIndex(context.Background(), 5)
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotFound))
+}
+
+func TestNodeHTTPAPIClientPool_Quorum(t *testing.T) {
+	const node1, node2, node3 = "http://node1:14265", "http://node2:14265", "http://node3:14265"
+	addr, _ := tpkg.RandEd25519Address()
+
+	mockBalance := func(nodeURL string, balance uint64) {
+		gock.New(nodeURL).
+			Get(fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Balance, addr.String())).
+			Reply(200).
+			JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressBalanceResponse{
+				AddressType: iotago.AddressEd25519,
+				Address:     addr.String(),
+				Balance:     balance,
+				LedgerIndex: uint64(balance),
+			}})
+	}
+
+	t.Run("ok - majority agrees", func(t *testing.T) {
+		defer gock.Off()
+
+		mockPoolNodeHealth(node1, 100, 100)
+		mockPoolNodeHealth(node2, 100, 100)
+		mockPoolNodeHealth(node3, 100, 100)
+		mockBalance(node1, 1337)
+		mockBalance(node2, 1000)
+		mockBalance(node3, 1337)
+
+		pool := newTestNodeHTTPAPIClientPool(t, []string{node1, node2, node3}, iotago.WithNodeHTTPAPIClientPoolQuorum(3))
+		res, err := pool.BalanceByEd25519Address(context.Background(), addr)
+		require.NoError(t, err)
+		require.EqualValues(t, 1337, res.Balance)
+	})
+
+	t.Run("err - no majority", func(t *testing.T) {
+		defer gock.Off()
+
+		mockPoolNodeHealth(node1, 100, 100)
+		mockPoolNodeHealth(node2, 100, 100)
+		mockPoolNodeHealth(node3, 100, 100)
+		mockBalance(node1, 1337)
+		mockBalance(node2, 1000)
+		gock.New(node3).
+			Get(fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Balance, addr.String())).
+			Reply(500).
+			JSON(&iotago.HTTPErrorResponseEnvelope{})
+
+		pool := newTestNodeHTTPAPIClientPool(t, []string{node1, node2, node3}, iotago.WithNodeHTTPAPIClientPoolQuorum(3))
+		_, err := pool.BalanceByEd25519Address(context.Background(), addr)
+		require.True(t, errors.Is(err, iotago.ErrNodeHTTPAPIClientPoolNoQuorum))
+	})
+}
+
+func TestNodeHTTPAPIClientPool_IteratorPinnedToNode(t *testing.T) {
+	defer gock.Off()
+
+	const node1, node2 = "http://node1:14265", "http://node2:14265"
+	addr, _ := tpkg.RandEd25519Address()
+	route := fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Outputs, addr.String())
+	outputIDs := randOutputIDHexes(3)
+	cursor := "next-page"
+
+	mockPoolNodeHealth(node1, 100, 100)
+	mockPoolNodeHealth(node2, 99, 99)
+	// node2 overtakes node1 by the next health check
+	mockPoolNodeHealth(node1, 100, 100)
+	mockPoolNodeHealth(node2, 101, 101)
+
+	gock.New(node1).
+		Get(route).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+			MaxResults:  2,
+			Count:       1,
+			OutputIDs:   outputIDs[:1],
+			LedgerIndex: 100,
+			Cursor:      &cursor,
+		}})
+
+	mockNextPage := func(nodeURL string, outputIDs []iotago.OutputIDHex, ledgerIndex uint64) {
+		gock.New(nodeURL).
+			Get(route).
+			MatchParam(iotago.NodeAPIQueryParamCursor, cursor).
+			MatchParam(iotago.NodeAPIQueryParamLedgerIndex, "100").
+			Reply(200).
+			JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+				MaxResults:  2,
+				Count:       1,
+				OutputIDs:   outputIDs,
+				LedgerIndex: ledgerIndex,
+			}})
+	}
+	mockNextPage(node2, outputIDs[2:], 101)
+	mockNextPage(node1, outputIDs[1:2], 100)
+
+	pool := newTestNodeHTTPAPIClientPool(t, []string{node1, node2}, iotago.WithNodeHTTPAPIClientPoolHealthCheckInterval(0))
+	it := pool.OutputIDsByEd25519AddressIterator(addr, false)
+
+	var gotOutputIDs []iotago.OutputIDHex
+	for it.Next(context.Background()) {
+		require.EqualValues(t, 100, it.Page().LedgerIndex)
+		gotOutputIDs = append(gotOutputIDs, it.Page().OutputIDs...)
+	}
+	require.NoError(t, it.Err())
+	// the cursor of node1 is never handed to node2
+	require.Equal(t, outputIDs[:2], gotOutputIDs)
+}
+
+// an http.RoundTripper refusing the connection for POST requests.
+type refusingPostTransport struct{}
+
+func (refusingPostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNodeHTTPAPIClientPool_SubmitMessage(t *testing.T) {
+	defer gock.Off()
+
+	const node1, node2, node3 = "http://node1:14265", "http://node2:14265", "http://node3:14265"
+	msg := &iotago.Message{Parents: tpkg.SortedRand32BytArray(1)}
+	msgID := tpkg.Rand32ByteArray()
+	msgIDHex := iotago.MessageIDToHexString(msgID)
+
+	mockPoolNodeHealth(node1, 101, 101)
+	mockPoolNodeHealth(node2, 100, 100)
+	mockPoolNodeHealth(node3, 99, 99)
+
+	// node1 refuses the connection, so the message is submitted to node2
+	// which might have accepted it before failing, so it isn't submitted to node3
+	gock.New(node2).
+		Post(iotago.NodeAPIRouteMessages).
+		Reply(500).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+	gock.New(node3).
+		Post(iotago.NodeAPIRouteMessages).
+		Reply(201).
+		AddHeader("Location", msgIDHex)
+
+	pool, err := iotago.NewNodeHTTPAPIClientPool([]*iotago.NodeHTTPAPIClient{
+		iotago.NewNodeHTTPAPIClient(node1, iotago.WithNodeHTTPAPIClientHTTPClient(&http.Client{Transport: refusingPostTransport{}})),
+		iotago.NewNodeHTTPAPIClient(node2),
+		iotago.NewNodeHTTPAPIClient(node3),
+	})
+	require.NoError(t, err)
+	_, err = pool.SubmitMessage(context.Background(), msg)
+	require.True(t, errors.Is(err, iotago.ErrHTTPInternalServerError))
+	require.Len(t, gock.Pending(), 1)
+
+	// with node1 and node2 being unhealthy, the message is submitted to node3 and fetched from it
+	serializedMsg, err := msg.Serialize(serializer.DeSeriModeNoValidation)
+	require.NoError(t, err)
+	gock.New(node3).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMessageBytes, msgIDHex)).
+		Reply(200).
+		Body(bytes.NewReader(serializedMsg))
+
+	res, err := pool.SubmitMessage(context.Background(), msg)
+	require.NoError(t, err)
+	require.Equal(t, msg.Parents, res.Parents)
+	require.True(t, gock.IsDone())
+}
+
+func TestNodeHTTPAPIClientPool_Peers(t *testing.T) {
+	defer gock.Off()
+
+	const node1, node2 = "http://node1:14265", "http://node2:14265"
+	pool := newTestNodeHTTPAPIClientPool(t, []string{node1, node2})
+
+	// peers are managed per node and therefore not routed through the pool
+	_, err := pool.Peers(context.Background())
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotImplemented))
+	_, err = pool.PeerByID(context.Background(), "12D3KooWFJ8Nq6gHLLvigTpPSbyMmLk35k1TcpJof8Y4y8yFAB32")
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotImplemented))
+	_, err = pool.AddPeer(context.Background(), "/ip4/127.0.0.1/tcp/15600/p2p/12D3KooWFJ8Nq6gHLLvigTpPSbyMmLk35k1TcpJof8Y4y8yFAB32")
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotImplemented))
+	require.True(t, errors.Is(pool.RemovePeerByID(context.Background(), "12D3KooWFJ8Nq6gHLLvigTpPSbyMmLk35k1TcpJof8Y4y8yFAB32"), iotago.ErrHTTPNotImplemented))
+
+	// instead, the client of a specific node is used
+	originRes := []*iotago.PeerResponse{}
+	gock.New(node2).
+		Get(iotago.NodeAPIRoutePeers).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: originRes})
+
+	nodes := pool.Nodes()
+	require.Equal(t, node2, nodes[1].Client.BaseURL)
+	peers, err := nodes[1].Client.Peers(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, originRes, peers)
+	require.True(t, gock.IsDone())
+}
@@ -0,0 +1,131 @@
+package iotago
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the default max. amount of attempts (including the first one) made for a request.
+	DefaultRetryMaxAttempts = 4
+	// DefaultRetryInitialBackoff is the default backoff before the first retry.
+	DefaultRetryInitialBackoff = 250 * time.Millisecond
+	// DefaultRetryMaxBackoff is the default upper bound of the backoff between two attempts.
+	DefaultRetryMaxBackoff = 10 * time.Second
+	// DefaultRetryMaxRetryAfter is the default max. duration a Retry-After header is honored for.
+	DefaultRetryMaxRetryAfter = time.Minute
+	// DefaultRetryMultiplier is the default factor by which the backoff grows with each attempt.
+	DefaultRetryMultiplier = 2
+	// DefaultRetryJitter is the default fraction by which the backoff is randomized.
+	DefaultRetryJitter = 0.2
+)
+
+// RetryPolicy defines how a NodeHTTPAPIClient retries failed idempotent requests.
+// Requests are retried on transport errors and on 429, 500, 502, 503 and 504 responses
+// with an exponentially growing, jittered backoff. A Retry-After header sent along 429 and 503 responses
+// takes precedence over the computed backoff.
+type RetryPolicy struct {
+	// The max. amount of attempts (including the first one) made for a request.
+	MaxAttempts int
+	// The backoff before the first retry.
+	InitialBackoff time.Duration
+	// The upper bound of the computed backoff.
+	MaxBackoff time.Duration
+	// The max. duration a Retry-After header is honored for. Requests asking for a longer delay are not retried.
+	MaxRetryAfter time.Duration
+	// The factor by which the backoff grows with each attempt.
+	Multiplier float64
+	// The fraction in [0, 1] by which the backoff is randomized, i.e. 0.2 results in a backoff of +/- 20%.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with the default values.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    DefaultRetryMaxAttempts,
+		InitialBackoff: DefaultRetryInitialBackoff,
+		MaxBackoff:     DefaultRetryMaxBackoff,
+		MaxRetryAfter:  DefaultRetryMaxRetryAfter,
+		Multiplier:     DefaultRetryMultiplier,
+		Jitter:         DefaultRetryJitter,
+	}
+}
+
+// Backoff returns the jittered backoff after the given failed attempt (starting at 1).
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+// returns the duration to wait before the next attempt and whether the request should be retried at all.
+// res might be nil if the request failed before a response was received.
+func (p *RetryPolicy) backoff(attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts || !isRetryableAttempt(res, err) {
+		return 0, false
+	}
+
+	backoff := p.Backoff(attempt)
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return backoff, true
+	}
+
+	retryAfter, has := parseRetryAfter(res.Header.Get("Retry-After"))
+	if !has {
+		return backoff, true
+	}
+	if p.MaxRetryAfter > 0 && retryAfter > p.MaxRetryAfter {
+		return 0, false
+	}
+	return retryAfter, true
+}
+
+// checks whether the given failed request attempt is worth retrying.
+func isRetryableAttempt(res *http.Response, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if res != nil {
+		switch res.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// parses the value of a Retry-After header which is either an amount of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	retryAfter := time.Until(date)
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, true
+}
@@ -0,0 +1,186 @@
+package iotago_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+func testRetryPolicy(maxAttempts int) *iotago.RetryPolicy {
+	retryPolicy := iotago.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = maxAttempts
+	retryPolicy.InitialBackoff = time.Millisecond
+	retryPolicy.MaxBackoff = 5 * time.Millisecond
+	return retryPolicy
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	retryPolicy := &iotago.RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	require.Equal(t, 100*time.Millisecond, retryPolicy.Backoff(1))
+	require.Equal(t, 200*time.Millisecond, retryPolicy.Backoff(2))
+	require.Equal(t, 800*time.Millisecond, retryPolicy.Backoff(4))
+	require.Equal(t, time.Second, retryPolicy.Backoff(10))
+
+	retryPolicy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := retryPolicy.Backoff(2)
+		require.GreaterOrEqual(t, int64(backoff), int64(100*time.Millisecond))
+		require.LessOrEqual(t, int64(backoff), int64(300*time.Millisecond))
+	}
+}
+
+func TestNodeHTTPAPIClient_RetryOnServiceUnavailable(t *testing.T) {
+	defer gock.Off()
+
+	originInfo := &iotago.NodeInfoResponse{Name: "HORNET", LatestMilestoneIndex: 1337}
+
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(503)
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(500).
+		JSON(&iotago.HTTPErrorResponseEnvelope{})
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: originInfo})
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientRetryPolicy(testRetryPolicy(3)))
+	info, err := nodeAPI.Info(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, originInfo, info)
+	require.True(t, gock.IsDone())
+}
+
+func TestNodeHTTPAPIClient_RetryAttemptsExhausted(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Times(2).
+		Reply(429)
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeInfoResponse{}})
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientRetryPolicy(testRetryPolicy(2)))
+	_, err := nodeAPI.Info(context.Background())
+	require.True(t, errors.Is(err, iotago.ErrHTTPTooManyRequests))
+	require.True(t, gock.IsPending())
+}
+
+func TestNodeHTTPAPIClient_RetryAfter(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(429).
+		SetHeader("Retry-After", "0")
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeInfoResponse{}})
+
+	// the Retry-After header takes precedence over the backoff of the policy
+	retryPolicy := testRetryPolicy(2)
+	retryPolicy.InitialBackoff = time.Hour
+	retryPolicy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientRetryPolicy(retryPolicy))
+	_, err := nodeAPI.Info(ctx)
+	require.NoError(t, err)
+	require.True(t, gock.IsDone())
+
+	// a Retry-After exceeding the max. is not honored
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(503).
+		SetHeader("Retry-After", "3600")
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteInfo).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeInfoResponse{}})
+
+	_, err = nodeAPI.Info(ctx)
+	require.True(t, errors.Is(err, iotago.ErrHTTPServiceUnavailable))
+	require.True(t, gock.IsPending())
+}
+
+func TestNodeHTTPAPIClient_NoRetryOfNonIdempotentRequests(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(nodeAPIUrl).
+		Post(iotago.NodeAPIRoutePeers).
+		Reply(503)
+	gock.New(nodeAPIUrl).
+		Post(iotago.NodeAPIRoutePeers).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.PeerResponse{}})
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(nodeAPIUrl, iotago.WithNodeHTTPAPIClientRetryPolicy(testRetryPolicy(3)))
+	_, err := nodeAPI.AddPeer(context.Background(), "/ip4/127.0.0.1/tcp/15600/p2p/12D3KooWCKwcTWevoRKa2kEBprCrNBBC5AdGnMWcJhGB5FZbM5xj")
+	require.True(t, errors.Is(err, iotago.ErrHTTPServiceUnavailable))
+	require.True(t, gock.IsPending())
+}
+
+func TestNodeHTTPAPIClient_MaxConcurrentRequests(t *testing.T) {
+	const maxConcurrentRequests = 2
+
+	var inFlight, maxInFlight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	nodeAPI := iotago.NewNodeHTTPAPIClient(srv.URL,
+		iotago.WithNodeHTTPAPIClientHTTPClient(&http.Client{Transport: &http.Transport{}}),
+		iotago.WithNodeHTTPAPIClientMaxConcurrentRequests(maxConcurrentRequests),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			healthy, err := nodeAPI.Health(context.Background())
+			require.NoError(t, err)
+			require.True(t, healthy)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, maxConcurrentRequests, atomic.LoadInt32(&maxInFlight))
+
+	// requests waiting for a free slot abort once their context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := nodeAPI.Health(ctx)
+	require.Error(t, err)
+}
@@ -0,0 +1,608 @@
+// Package nodetest provides an in-process fake node implementing the node's HTTP REST API for tests.
+// The fake node is backed by an in-memory ledger.Ledger and message store: submitted messages are checked
+// the way a node checks them and become part of the ledger once they are referenced by a milestone
+// issued via Node.IssueMilestone.
+package nodetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/ledger"
+	"github.com/iotaledger/iota.go/v2/pow"
+)
+
+const (
+	// DefaultNetworkName is the default name of the network the fake node operates on.
+	DefaultNetworkName = "nodetest"
+	// DefaultMaxResults is the default max. amount of results the fake node returns per page.
+	DefaultMaxResults = 1000
+	// FeaturePoW is the feature announced by the fake node if it does the proof-of-work for submitted messages.
+	FeaturePoW = "PoW"
+)
+
+var (
+	// ErrMessageBelowMinPowScore gets returned when a submitted message does not fulfill the min. PoW score.
+	ErrMessageBelowMinPowScore = errors.New("message does not fulfill the min. PoW score")
+	// ErrMessageNetworkIDMismatch gets returned when a submitted message is meant for another network.
+	ErrMessageNetworkIDMismatch = errors.New("message network ID does not match")
+	// ErrMessageMilestonePayload gets returned when a milestone is submitted to the fake node.
+	ErrMessageMilestonePayload = errors.New("milestones can only be issued by the fake node")
+	// ErrUnknownParent gets returned when a submitted message references a parent not known to the fake node.
+	ErrUnknownParent = errors.New("unknown parent")
+)
+
+// Option is a function setting a Node option.
+type Option func(opts *Options)
+
+// Options define options for the Node.
+type Options struct {
+	// The name of the network the node operates on.
+	networkName string
+	// The HRP used for Bech32 addresses.
+	bech32HRP iotago.NetworkPrefix
+	// The min. PoW score submitted messages must fulfill.
+	minPowScore float64
+	// Whether the node does the proof-of-work for submitted messages without a nonce.
+	powEnabled bool
+	// The ledger backing the node.
+	ledger *ledger.Ledger
+	// The max. amount of results returned per page.
+	maxResults int
+	// Whether the node returns cursors for truncated results.
+	cursors bool
+	// The keys used to sign milestones.
+	milestoneKeys []ed25519.PrivateKey
+}
+
+// applies the given Option.
+func (no *Options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(no)
+	}
+}
+
+// WithNetworkName sets the name of the network the node operates on. The network ID of messages is derived from it.
+func WithNetworkName(networkName string) Option {
+	return func(opts *Options) {
+		opts.networkName = networkName
+	}
+}
+
+// WithBech32HRP sets the HRP used for Bech32 addresses.
+func WithBech32HRP(hrp iotago.NetworkPrefix) Option {
+	return func(opts *Options) {
+		opts.bech32HRP = hrp
+	}
+}
+
+// WithMinPowScore sets the min. PoW score submitted messages must fulfill.
+func WithMinPowScore(minPowScore float64) Option {
+	return func(opts *Options) {
+		opts.minPowScore = minPowScore
+	}
+}
+
+// WithPoW defines whether the node does the proof-of-work for submitted messages which don't carry a nonce.
+func WithPoW(enabled bool) Option {
+	return func(opts *Options) {
+		opts.powEnabled = enabled
+	}
+}
+
+// WithLedger sets the ledger backing the node, i.e. one created from a snapshot holding the genesis outputs.
+// Outputs might still be added to the ledger directly, all other mutations must happen through the node.
+func WithLedger(l *ledger.Ledger) Option {
+	return func(opts *Options) {
+		opts.ledger = l
+	}
+}
+
+// WithMaxResults sets the max. amount of results the node returns per page.
+func WithMaxResults(maxResults int) Option {
+	return func(opts *Options) {
+		opts.maxResults = maxResults
+	}
+}
+
+// WithCursors defines whether the node returns cursors for truncated results.
+// Without cursors, results exceeding the max. results are truncated like older nodes do.
+func WithCursors(enabled bool) Option {
+	return func(opts *Options) {
+		opts.cursors = enabled
+	}
+}
+
+// WithMilestoneKeys sets the keys used to sign milestones. Per default a single random key is used.
+func WithMilestoneKeys(prvKeys ...ed25519.PrivateKey) Option {
+	return func(opts *Options) {
+		opts.milestoneKeys = prvKeys
+	}
+}
+
+// the default options applied to the Node.
+var defaultOptions = []Option{
+	WithNetworkName(DefaultNetworkName),
+	WithBech32HRP(iotago.PrefixTestnet),
+	WithMinPowScore(0),
+	WithPoW(false),
+	WithMaxResults(DefaultMaxResults),
+	WithCursors(true),
+}
+
+// a message known to the node along with its metadata.
+type message struct {
+	id       iotago.MessageID
+	msg      *iotago.Message
+	data     []byte
+	children []iotago.MessageID
+	// the milestone index which referenced the message, 0 if not yet referenced.
+	referencedBy   uint32
+	milestoneIndex uint32
+	inclusionState iotago.LedgerInclusionState
+	conflictReason iotago.ConflictReason
+}
+
+// a milestone issued by the node.
+type milestone struct {
+	index     uint32
+	msgID     iotago.MessageID
+	timestamp int64
+	created   []iotago.UTXOInputID
+	consumed  []iotago.UTXOInputID
+}
+
+// a peer added to the node.
+type peer struct {
+	id           string
+	multiAddress string
+	alias        *string
+}
+
+// NewNode creates and starts a new Node. Close must be called once the Node is no longer needed.
+func NewNode(opts ...Option) (*Node, error) {
+	options := &Options{}
+	options.apply(defaultOptions...)
+	options.apply(opts...)
+
+	if options.ledger == nil {
+		options.ledger = ledger.New()
+	}
+
+	if len(options.milestoneKeys) == 0 {
+		_, prvKey, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate milestone key: %w", err)
+		}
+		options.milestoneKeys = []ed25519.PrivateKey{prvKey}
+	}
+
+	n := &Node{
+		opts:          options,
+		networkID:     iotago.NetworkIDFromString(options.networkName),
+		healthy:       true,
+		keyManager:    iotago.NewMilestoneKeyManager(len(options.milestoneKeys)),
+		keyMapping:    make(iotago.MilestonePublicKeyMapping),
+		messages:      make(map[iotago.MessageID]*message),
+		tips:          make(map[iotago.MessageID]struct{}),
+		indexes:       make(map[string][]iotago.MessageID),
+		milestones:    make(map[uint32]*milestone),
+		spentByAddr:   make(map[string][]iotago.UTXOInputID),
+		peers:         make(map[string]*peer),
+		latestMsIndex: options.ledger.Index(),
+	}
+
+	for _, prvKey := range options.milestoneKeys {
+		var pubKey iotago.MilestonePublicKey
+		copy(pubKey[:], prvKey.Public().(ed25519.PublicKey))
+		n.keyMapping[pubKey] = prvKey
+		n.pubKeys = append(n.pubKeys, pubKey)
+		if err := n.keyManager.AddKeyRange(pubKey, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	n.srv = httptest.NewServer(n.router())
+	return n, nil
+}
+
+// Node is an in-process fake node serving the node's HTTP REST API.
+// It keeps all messages in memory and only changes its ledger when a milestone is issued via IssueMilestone,
+// which references all messages not yet referenced in the same order a node applies them.
+type Node struct {
+	opts       *Options
+	srv        *httptest.Server
+	networkID  iotago.NetworkID
+	keyManager *iotago.MilestoneKeyManager
+	keyMapping iotago.MilestonePublicKeyMapping
+	pubKeys    []iotago.MilestonePublicKey
+
+	mu            sync.RWMutex
+	healthy       bool
+	messages      map[iotago.MessageID]*message
+	tips          map[iotago.MessageID]struct{}
+	indexes       map[string][]iotago.MessageID
+	milestones    map[uint32]*milestone
+	receipts      []*iotago.ReceiptTuple
+	spentByAddr   map[string][]iotago.UTXOInputID
+	peers         map[string]*peer
+	latestMsIndex uint32
+	latestMsTime  int64
+}
+
+// URL returns the base URL of the node.
+func (n *Node) URL() string {
+	return n.srv.URL
+}
+
+// Close shuts down the node.
+func (n *Node) Close() {
+	n.srv.Close()
+}
+
+// Client returns a NodeHTTPAPIClient talking to the node.
+func (n *Node) Client(opts ...iotago.NodeHTTPAPIClientOption) *iotago.NodeHTTPAPIClient {
+	return iotago.NewNodeHTTPAPIClient(n.srv.URL, append([]iotago.NodeHTTPAPIClientOption{
+		iotago.WithNodeHTTPAPIClientHTTPClient(n.srv.Client()),
+	}, opts...)...)
+}
+
+// Ledger returns the ledger backing the node. Outputs might be added to it directly, see WithLedger.
+func (n *Node) Ledger() *ledger.Ledger {
+	return n.opts.ledger
+}
+
+// NetworkID returns the network ID of the messages the node accepts.
+func (n *Node) NetworkID() iotago.NetworkID {
+	return n.networkID
+}
+
+// MilestoneKeyManager returns a MilestoneKeyManager holding the public keys the node signs milestones with.
+func (n *Node) MilestoneKeyManager() *iotago.MilestoneKeyManager {
+	return n.keyManager
+}
+
+// SetHealthy sets whether the node reports itself as healthy.
+func (n *Node) SetHealthy(healthy bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.healthy = healthy
+}
+
+// AddMessage adds the given message to the node as if it had been received via gossip,
+// it is subject to the same checks as a submitted message.
+func (n *Node) AddMessage(msg *iotago.Message) (iotago.MessageID, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.addMessage(msg)
+}
+
+// returns the current tips in lexical order, the zero message ID acts as tip if there are none.
+func (n *Node) sortedTips() iotago.MessageIDs {
+	tips := make(iotago.MessageIDs, 0, len(n.tips))
+	for tip := range n.tips {
+		tips = append(tips, tip)
+	}
+	if len(tips) == 0 {
+		return iotago.MessageIDs{{}}
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		for k := range tips[i] {
+			if tips[i][k] != tips[j][k] {
+				return tips[i][k] < tips[j][k]
+			}
+		}
+		return false
+	})
+	if len(tips) > iotago.MaxParentsInAMessage {
+		tips = tips[:iotago.MaxParentsInAMessage]
+	}
+	return tips
+}
+
+// submits the given message which was serialized without validation.
+// Missing network ID, parents and nonce are filled in before the message is checked.
+func (n *Node) submitMessage(data []byte) (iotago.MessageID, error) {
+	msg := &iotago.Message{}
+	if _, err := msg.Deserialize(data, serializer.DeSeriModeNoValidation); err != nil {
+		return iotago.MessageID{}, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if msg.NetworkID == 0 {
+		msg.NetworkID = n.networkID
+	}
+
+	if len(msg.Parents) == 0 {
+		msg.Parents = n.sortedTips()
+	}
+
+	if msg.Nonce == 0 && n.opts.powEnabled && n.opts.minPowScore > 0 {
+		msgData, err := msg.Serialize(serializer.DeSeriModePerformValidation)
+		if err != nil {
+			return iotago.MessageID{}, err
+		}
+		nonce, err := pow.New().Mine(context.Background(), msgData[:len(msgData)-serializer.UInt64ByteSize], n.opts.minPowScore)
+		if err != nil {
+			return iotago.MessageID{}, fmt.Errorf("unable to complete proof-of-work: %w", err)
+		}
+		msg.Nonce = nonce
+	}
+
+	return n.addMessage(msg)
+}
+
+// checks the given message and adds it to the message store.
+func (n *Node) addMessage(msg *iotago.Message) (iotago.MessageID, error) {
+	data, err := msg.Serialize(serializer.DeSeriModePerformValidation)
+	if err != nil {
+		return iotago.MessageID{}, err
+	}
+
+	if msg.NetworkID != n.networkID {
+		return iotago.MessageID{}, fmt.Errorf("%w: expected %d but got %d", ErrMessageNetworkIDMismatch, n.networkID, msg.NetworkID)
+	}
+
+	if score := pow.Score(data); score < n.opts.minPowScore {
+		return iotago.MessageID{}, fmt.Errorf("%w: score %f, min. score %f", ErrMessageBelowMinPowScore, score, n.opts.minPowScore)
+	}
+
+	for _, parent := range msg.Parents {
+		if _, has := n.messages[parent]; !has && parent != (iotago.MessageID{}) {
+			return iotago.MessageID{}, fmt.Errorf("%w: %x", ErrUnknownParent, parent)
+		}
+	}
+
+	switch payload := msg.Payload.(type) {
+	case *iotago.Milestone:
+		return iotago.MessageID{}, ErrMessageMilestonePayload
+	case *iotago.Transaction:
+		if err := n.validateTransaction(payload); err != nil {
+			return iotago.MessageID{}, err
+		}
+	}
+
+	msgID, err := msg.ID()
+	if err != nil {
+		return iotago.MessageID{}, err
+	}
+	if _, has := n.messages[*msgID]; has {
+		return *msgID, nil
+	}
+
+	n.storeMessage(*msgID, msg, data)
+	if indexation := indexationOf(msg); indexation != nil {
+		key := string(indexation.Index)
+		n.indexes[key] = append(n.indexes[key], *msgID)
+	}
+	return *msgID, nil
+}
+
+// stores the given message and updates the tips.
+func (n *Node) storeMessage(msgID iotago.MessageID, msg *iotago.Message, data []byte) *message {
+	stored := &message{id: msgID, msg: msg, data: data}
+	n.messages[msgID] = stored
+	for _, parent := range msg.Parents {
+		if parentMsg, has := n.messages[parent]; has {
+			parentMsg.children = append(parentMsg.children, msgID)
+		}
+		delete(n.tips, parent)
+	}
+	n.tips[msgID] = struct{}{}
+	return stored
+}
+
+// returns the indexation of the given message, either its payload or the one embedded in its transaction.
+func indexationOf(msg *iotago.Message) *iotago.Indexation {
+	switch payload := msg.Payload.(type) {
+	case *iotago.Indexation:
+		return payload
+	case *iotago.Transaction:
+		essence, ok := payload.Essence.(*iotago.TransactionEssence)
+		if !ok || essence.Payload == nil {
+			return nil
+		}
+		indexation, _ := essence.Payload.(*iotago.Indexation)
+		return indexation
+	}
+	return nil
+}
+
+// validates the given transaction against the current ledger state. The transaction might still conflict
+// once it gets referenced by a milestone as other transactions might spend the same inputs first.
+func (n *Node) validateTransaction(tx *iotago.Transaction) error {
+	if err := tx.SyntacticallyValidate(); err != nil {
+		return err
+	}
+
+	utxos := make(iotago.InputToOutputMapping)
+	for i, input := range tx.Essence.(*iotago.TransactionEssence).Inputs {
+		utxoID := input.(*iotago.UTXOInput).ID()
+		if n.opts.ledger.IsSpent(utxoID) {
+			return fmt.Errorf("%w: %s (input at index %d)", iotago.ErrUTXOAlreadySpent, utxoID.ToHex(), i)
+		}
+		if output, err := n.opts.ledger.Output(utxoID); err == nil {
+			utxos[utxoID] = output.Output
+		}
+	}
+	return tx.SemanticallyValidate(utxos)
+}
+
+// IssueMilestone issues a milestone referencing all messages not yet referenced and applies
+// their transactions to the ledger. Conflicting transactions are marked as such in the metadata of their message.
+func (n *Node) IssueMilestone() (*iotago.Message, error) {
+	return n.IssueMilestoneWithReceipt(nil)
+}
+
+// IssueMilestoneWithReceipt works like IssueMilestone but the milestone carries the given receipt,
+// which must consume the current treasury of the ledger.
+func (n *Node) IssueMilestoneWithReceipt(receipt *iotago.Receipt) (*iotago.Message, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	index := n.latestMsIndex + 1
+	parents := n.sortedTips()
+
+	// apply the transactions of the referenced messages in the order a node does,
+	// the diffs are rolled back if the milestone itself can't be applied
+	var (
+		referenced     = n.pastCone(parents)
+		included       iotago.MessageIDs
+		applied        int
+		created        []iotago.UTXOInputID
+		consumed       []*ledger.Spent
+		spentInThisMs  = make(map[iotago.UTXOInputID]struct{})
+		inclusionState = make(map[iotago.MessageID]iotago.LedgerInclusionState, len(referenced))
+		conflicts      = make(map[iotago.MessageID]iotago.ConflictReason)
+	)
+	for _, msg := range referenced {
+		tx, isTx := msg.msg.Payload.(*iotago.Transaction)
+		if !isTx {
+			inclusionState[msg.id] = iotago.LedgerInclusionStateNoTransaction
+			continue
+		}
+
+		diff, err := n.opts.ledger.ApplyMessage(msg.msg)
+		if err != nil {
+			inclusionState[msg.id] = iotago.LedgerInclusionStateConflicting
+			conflicts[msg.id] = conflictReason(tx, err, spentInThisMs)
+			continue
+		}
+		applied++
+		inclusionState[msg.id] = iotago.LedgerInclusionStateIncluded
+		included = append(included, msg.id)
+		for _, output := range diff.Created {
+			created = append(created, output.ID)
+		}
+		for _, spent := range diff.Consumed {
+			consumed = append(consumed, spent)
+			spentInThisMs[spent.Output.ID] = struct{}{}
+		}
+	}
+
+	msMsg, msDiff, err := n.applyMilestone(index, parents, included, receipt)
+	if err != nil {
+		for i := 0; i < applied; i++ {
+			if _, rollbackErr := n.opts.ledger.Rollback(); rollbackErr != nil {
+				return nil, fmt.Errorf("unable to roll back ledger after failed milestone (%v): %w", err, rollbackErr)
+			}
+		}
+		return nil, err
+	}
+	for _, output := range msDiff.Created {
+		created = append(created, output.ID)
+	}
+
+	msMsgID := msMsg.MustID()
+	ms := msMsg.Payload.(*iotago.Milestone)
+	for _, msg := range referenced {
+		msg.referencedBy = index
+		msg.inclusionState = inclusionState[msg.id]
+		msg.conflictReason = conflicts[msg.id]
+	}
+
+	msData, err := msMsg.Serialize(serializer.DeSeriModePerformValidation)
+	if err != nil {
+		return nil, err
+	}
+	stored := n.storeMessage(msMsgID, msMsg, msData)
+	stored.referencedBy = index
+	stored.milestoneIndex = index
+	stored.inclusionState = iotago.LedgerInclusionStateNoTransaction
+
+	issued := &milestone{index: index, msgID: msMsgID, timestamp: int64(ms.Timestamp), created: created}
+	for _, spent := range consumed {
+		issued.consumed = append(issued.consumed, spent.Output.ID)
+		addrKey := spent.Output.Address().String()
+		n.spentByAddr[addrKey] = append(n.spentByAddr[addrKey], spent.Output.ID)
+	}
+	n.milestones[index] = issued
+	if receipt != nil {
+		n.receipts = append(n.receipts, &iotago.ReceiptTuple{Receipt: receipt, MilestoneIndex: index})
+	}
+	n.latestMsIndex = index
+	n.latestMsTime = issued.timestamp
+
+	return msMsg, nil
+}
+
+// builds, signs and applies the milestone with the given index.
+func (n *Node) applyMilestone(index uint32, parents iotago.MessageIDs, included iotago.MessageIDs, receipt *iotago.Receipt) (*iotago.Message, *ledger.Diff, error) {
+	var proof iotago.MilestoneInclusionMerkleProof
+	if len(included) > 0 {
+		proof = iotago.MerkleTreeHash(included)
+	}
+
+	pubKeys := make([]iotago.MilestonePublicKey, len(n.pubKeys))
+	copy(pubKeys, n.pubKeys)
+	ms, err := iotago.NewMilestone(index, uint64(time.Now().Unix()), parents, proof, pubKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if receipt != nil {
+		ms.Receipt = receipt
+	}
+	if err := ms.Sign(iotago.InMemoryEd25519MilestoneSigner(n.keyMapping)); err != nil {
+		return nil, nil, err
+	}
+
+	msMsg := &iotago.Message{NetworkID: n.networkID, Parents: parents, Payload: ms}
+	diff, err := n.opts.ledger.ApplyMessage(msMsg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to apply milestone %d: %w", index, err)
+	}
+	return msMsg, diff, nil
+}
+
+// returns the not yet referenced messages in the past cone of the given parents
+// in the order a node applies them, being a post-order depth-first traversal visiting parents in order.
+func (n *Node) pastCone(parents iotago.MessageIDs) []*message {
+	var (
+		cone    []*message
+		visited = make(map[iotago.MessageID]struct{})
+		visit   func(msgID iotago.MessageID)
+	)
+	visit = func(msgID iotago.MessageID) {
+		if _, seen := visited[msgID]; seen {
+			return
+		}
+		visited[msgID] = struct{}{}
+		msg, has := n.messages[msgID]
+		if !has || msg.referencedBy != 0 {
+			return
+		}
+		for _, parent := range msg.msg.Parents {
+			visit(parent)
+		}
+		cone = append(cone, msg)
+	}
+	for _, parent := range parents {
+		visit(parent)
+	}
+	return cone
+}
+
+// returns the ConflictReason for the given error returned by the ledger for the given transaction.
+func conflictReason(tx *iotago.Transaction, err error, spentInThisMs map[iotago.UTXOInputID]struct{}) iotago.ConflictReason {
+	reason := iotago.ConflictReasonFromError(err)
+	if reason != iotago.ConflictInputUTXOAlreadySpent {
+		return reason
+	}
+	for _, input := range tx.Essence.(*iotago.TransactionEssence).Inputs {
+		if _, has := spentInThisMs[input.(*iotago.UTXOInput).ID()]; has {
+			return iotago.ConflictInputUTXOAlreadySpentInThisMilestone
+		}
+	}
+	return reason
+}
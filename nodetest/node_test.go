@@ -0,0 +1,331 @@
+package nodetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/stretchr/testify/require"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/ledger"
+	"github.com/iotaledger/iota.go/v2/nodetest"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+const mi = iotago.OutputSigLockedDustAllowanceOutputMinDeposit
+
+type identity struct {
+	addr   *iotago.Ed25519Address
+	signer iotago.AddressSigner
+}
+
+func randIdentity() *identity {
+	prvKey := tpkg.RandEd25519PrivateKey()
+	addr := iotago.AddressFromEd25519PubKey(prvKey.Public().(ed25519.PublicKey))
+	return &identity{addr: &addr, signer: iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(&addr, prvKey))}
+}
+
+func newNode(t *testing.T, opts ...nodetest.Option) *nodetest.Node {
+	node, err := nodetest.NewNode(opts...)
+	require.NoError(t, err)
+	t.Cleanup(node.Close)
+	return node
+}
+
+func genesisOutput(t *testing.T, l *ledger.Ledger, addr iotago.Address, amount uint64) *iotago.UTXOInput {
+	utxoInput := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray()}
+	_, err := l.AddOutputs(&ledger.Output{ID: utxoInput.ID(), Output: &iotago.SigLockedSingleOutput{Address: addr, Amount: amount}})
+	require.NoError(t, err)
+	return utxoInput
+}
+
+func transfer(t *testing.T, from *identity, input *iotago.UTXOInput, outputs ...iotago.Output) *iotago.Message {
+	builder := iotago.NewTransactionBuilder().AddInput(&iotago.ToBeSignedUTXOInput{Address: from.addr, Input: input})
+	for _, output := range outputs {
+		builder.AddOutput(output)
+	}
+	tx, err := builder.Build(from.signer)
+	require.NoError(t, err)
+	return &iotago.Message{Payload: tx}
+}
+
+func TestNode_SubmitIndexation(t *testing.T) {
+	node := newNode(t)
+	nodeAPI := node.Client()
+	ctx := context.Background()
+
+	healthy, err := nodeAPI.Health(ctx)
+	require.NoError(t, err)
+	require.True(t, healthy)
+
+	index := []byte("nodetest")
+	var msgIDs []iotago.MessageID
+	for i := 0; i < 3; i++ {
+		msg, err := nodeAPI.SubmitMessage(ctx, &iotago.Message{Payload: &iotago.Indexation{Index: index, Data: tpkg.RandBytes(10)}})
+		require.NoError(t, err)
+		require.Equal(t, node.NetworkID(), msg.NetworkID)
+		msgIDs = append(msgIDs, msg.MustID())
+	}
+
+	// every message attaches to the previous one which is the only tip
+	tipsRes, err := nodeAPI.Tips(ctx)
+	require.NoError(t, err)
+	tips, err := tipsRes.Tips()
+	require.NoError(t, err)
+	require.Equal(t, iotago.MessageIDs{msgIDs[2]}, tips)
+
+	children, err := nodeAPI.ChildrenByMessageID(ctx, msgIDs[0])
+	require.NoError(t, err)
+	require.Len(t, children.Children, 1)
+
+	byIndex, err := nodeAPI.MessageIDsByIndex(ctx, index)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, byIndex.Count)
+
+	msgJSON, err := nodeAPI.MessageJSONByMessageID(ctx, msgIDs[1])
+	require.NoError(t, err)
+	require.Equal(t, msgIDs[1], msgJSON.MustID())
+
+	metadata, err := nodeAPI.MessageMetadataByMessageID(ctx, msgIDs[0])
+	require.NoError(t, err)
+	require.Nil(t, metadata.ReferencedByMilestoneIndex)
+
+	msMsg, err := node.IssueMilestone()
+	require.NoError(t, err)
+	require.NoError(t, node.MilestoneKeyManager().VerifyMilestone(msMsg.Payload.(*iotago.Milestone)))
+
+	metadata, err = nodeAPI.MessageMetadataByMessageID(ctx, msgIDs[0])
+	require.NoError(t, err)
+	require.EqualValues(t, 1, *metadata.ReferencedByMilestoneIndex)
+	require.Equal(t, iotago.LedgerInclusionStateNoTransaction, *metadata.LedgerInclusionState)
+
+	milestone, err := nodeAPI.MilestoneByIndex(ctx, 1)
+	require.NoError(t, err)
+	msMsgID := msMsg.MustID()
+	require.Equal(t, iotago.MessageIDToHexString(msMsgID), milestone.MessageID)
+
+	info, err := nodeAPI.Info(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, info.LatestMilestoneIndex)
+	require.EqualValues(t, 1, info.ConfirmedMilestoneIndex)
+
+	_, err = nodeAPI.MilestoneByIndex(ctx, 2)
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotFound))
+
+	node.SetHealthy(false)
+	healthy, err = nodeAPI.Health(ctx)
+	require.NoError(t, err)
+	require.False(t, healthy)
+}
+
+func TestNode_Transactions(t *testing.T) {
+	alice, bob := randIdentity(), randIdentity()
+
+	l := ledger.New()
+	genesis := genesisOutput(t, l, alice.addr, 10*mi)
+
+	node := newNode(t, nodetest.WithLedger(l))
+	nodeAPI := node.Client()
+	ctx := context.Background()
+
+	transferMsg, err := nodeAPI.SubmitMessage(ctx, transfer(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 4 * mi},
+		&iotago.SigLockedSingleOutput{Address: alice.addr, Amount: 6 * mi},
+	))
+	require.NoError(t, err)
+
+	// both spend the genesis output, only the first one referenced gets included
+	doubleSpendMsg, err := nodeAPI.SubmitMessage(ctx, transfer(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10 * mi},
+	))
+	require.NoError(t, err)
+
+	// invalid transactions are rejected right away
+	_, err = nodeAPI.SubmitMessage(ctx, transfer(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 11 * mi},
+	))
+	require.True(t, errors.Is(err, iotago.ErrHTTPBadRequest))
+
+	_, err = node.IssueMilestone()
+	require.NoError(t, err)
+
+	metadata, err := nodeAPI.MessageMetadataByMessageID(ctx, transferMsg.MustID())
+	require.NoError(t, err)
+	require.Equal(t, iotago.LedgerInclusionStateIncluded, *metadata.LedgerInclusionState)
+
+	metadata, err = nodeAPI.MessageMetadataByMessageID(ctx, doubleSpendMsg.MustID())
+	require.NoError(t, err)
+	require.Equal(t, iotago.LedgerInclusionStateConflicting, *metadata.LedgerInclusionState)
+	require.Equal(t, iotago.ConflictInputUTXOAlreadySpentInThisMilestone, metadata.ConflictReason)
+
+	balance, err := nodeAPI.BalanceByEd25519Address(ctx, bob.addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 4*mi, balance.Balance)
+
+	unspent, err := nodeAPI.OutputIDsByBech32Address(ctx, alice.addr.Bech32(iotago.PrefixTestnet), false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, unspent.Count)
+
+	all, err := nodeAPI.OutputIDsByEd25519Address(ctx, alice.addr, true)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, all.Count)
+
+	genesisRes, err := nodeAPI.OutputByID(ctx, genesis.ID())
+	require.NoError(t, err)
+	require.True(t, genesisRes.Spent)
+
+	changes, err := nodeAPI.MilestoneUTXOChangesByIndex(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, changes.CreatedOutputs, 2)
+	require.Equal(t, []string{genesis.ID().ToHex()}, changes.ConsumedOutputs)
+
+	// spending a spent output is rejected right away
+	_, err = nodeAPI.SubmitMessage(ctx, transfer(t, alice, genesis,
+		&iotago.SigLockedSingleOutput{Address: bob.addr, Amount: 10 * mi},
+	))
+	require.True(t, errors.Is(err, iotago.ErrHTTPBadRequest))
+}
+
+func TestNode_MinPowScore(t *testing.T) {
+	const minPowScore = 10
+	ctx := context.Background()
+
+	node := newNode(t, nodetest.WithMinPowScore(minPowScore))
+	_, err := node.Client().SubmitMessage(ctx, &iotago.Message{Payload: &iotago.Indexation{Index: []byte("pow")}})
+	require.True(t, errors.Is(err, iotago.ErrHTTPBadRequest))
+
+	// messages carrying enough PoW are accepted
+	msg, err := iotago.NewMessageBuilder().
+		NetworkID(node.NetworkID()).
+		Payload(&iotago.Indexation{Index: []byte("pow")}).
+		ParentsMessageIDs(iotago.MessageIDs{{}}).
+		ProofOfWork(ctx, minPowScore).
+		Build()
+	require.NoError(t, err)
+	_, err = node.Client().SubmitMessage(ctx, msg)
+	require.NoError(t, err)
+
+	// a node offering PoW does it on behalf of the client
+	powNode := newNode(t, nodetest.WithMinPowScore(minPowScore), nodetest.WithPoW(true))
+	info, err := powNode.Client().Info(ctx)
+	require.NoError(t, err)
+	require.Contains(t, info.Features, nodetest.FeaturePoW)
+
+	msg, err = powNode.Client().SubmitMessage(ctx, &iotago.Message{Payload: &iotago.Indexation{Index: []byte("pow")}})
+	require.NoError(t, err)
+	score, err := msg.POW()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, score, float64(minPowScore))
+}
+
+func TestNode_Paging(t *testing.T) {
+	index := []byte("paging")
+	ctx := context.Background()
+
+	submit := func(node *nodetest.Node) {
+		for i := 0; i < 5; i++ {
+			_, err := node.AddMessage(&iotago.Message{
+				NetworkID: node.NetworkID(),
+				Parents:   iotago.MessageIDs{{}},
+				Payload:   &iotago.Indexation{Index: index, Data: tpkg.RandBytes(10)},
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	node := newNode(t, nodetest.WithMaxResults(2))
+	submit(node)
+	res, err := node.Client().MessageIDsByIndex(ctx, index)
+	require.NoError(t, err)
+	require.Len(t, res.MessageIDs, 5)
+
+	truncatingNode := newNode(t, nodetest.WithMaxResults(2), nodetest.WithCursors(false))
+	submit(truncatingNode)
+	_, err = truncatingNode.Client().MessageIDsByIndex(ctx, index)
+	require.True(t, errors.Is(err, iotago.ErrResultsTruncated))
+}
+
+func TestNode_Receipts(t *testing.T) {
+	alice := randIdentity()
+
+	l := ledger.New()
+	prevMsID := tpkg.Rand32ByteArray()
+	_, err := l.SetTreasury(&ledger.Treasury{MilestoneID: prevMsID, Amount: 100 * mi})
+	require.NoError(t, err)
+
+	node := newNode(t, nodetest.WithLedger(l))
+	nodeAPI := node.Client()
+	ctx := context.Background()
+
+	treasuryInput := iotago.TreasuryInput(prevMsID)
+	receipt := &iotago.Receipt{
+		MigratedAt: 1000,
+		Final:      true,
+		Funds: serializer.Serializables{
+			&iotago.MigratedFundsEntry{TailTransactionHash: tpkg.Rand49ByteArray(), Address: alice.addr, Deposit: 10 * mi},
+		},
+		Transaction: &iotago.TreasuryTransaction{
+			Input:  &treasuryInput,
+			Output: &iotago.TreasuryOutput{Amount: 90 * mi},
+		},
+	}
+
+	// a receipt not matching the treasury leaves the node untouched
+	invalidInput := iotago.TreasuryInput(tpkg.Rand32ByteArray())
+	_, err = node.IssueMilestoneWithReceipt(&iotago.Receipt{
+		MigratedAt:  1000,
+		Funds:       receipt.Funds,
+		Transaction: &iotago.TreasuryTransaction{Input: &invalidInput, Output: &iotago.TreasuryOutput{Amount: 90 * mi}},
+	})
+	require.True(t, errors.Is(err, ledger.ErrTreasuryMismatch))
+	require.Zero(t, l.Index())
+
+	msMsg, err := node.IssueMilestoneWithReceipt(receipt)
+	require.NoError(t, err)
+	msID, err := msMsg.Payload.(*iotago.Milestone).ID()
+	require.NoError(t, err)
+
+	treasury, err := nodeAPI.Treasury(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &iotago.TreasuryResponse{MilestoneID: iotago.MessageIDToHexString(*msID), Amount: 90 * mi}, treasury)
+
+	receipts, err := nodeAPI.ReceiptsByMigratedAtIndex(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, receipts, 1)
+	require.EqualValues(t, 1, receipts[0].MilestoneIndex)
+
+	receipts, err = nodeAPI.ReceiptsByMigratedAtIndex(ctx, 1001)
+	require.NoError(t, err)
+	require.Empty(t, receipts)
+
+	balance, err := nodeAPI.BalanceByEd25519Address(ctx, alice.addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 10*mi, balance.Balance)
+}
+
+func TestNode_Peers(t *testing.T) {
+	const peerID = "12D3KooWCKwcTWevoRKa2kEBprCrNBBC5AdGnMWcJhGB5FZbM5xj"
+
+	node := newNode(t)
+	nodeAPI := node.Client()
+	ctx := context.Background()
+
+	added, err := nodeAPI.AddPeer(ctx, "/ip4/127.0.0.1/tcp/15600/p2p/"+peerID, "alias")
+	require.NoError(t, err)
+	require.Equal(t, peerID, added.ID)
+
+	peer, err := nodeAPI.PeerByID(ctx, peerID)
+	require.NoError(t, err)
+	require.Equal(t, "alias", *peer.Alias)
+
+	peers, err := nodeAPI.Peers(ctx)
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+
+	require.NoError(t, nodeAPI.RemovePeerByID(ctx, peerID))
+	_, err = nodeAPI.PeerByID(ctx, peerID)
+	require.True(t, errors.Is(err, iotago.ErrHTTPNotFound))
+}
@@ -0,0 +1,636 @@
+package nodetest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ledger"
+)
+
+// a handler of a route, params holds the parameters of the route in order.
+type routeHandler func(w http.ResponseWriter, r *http.Request, params []string)
+
+// a route served by the node.
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handler routeHandler
+}
+
+// creates a route for the given method and NodeAPIRoute constant.
+func newRoute(method string, template string, handler routeHandler) route {
+	pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(template), "%s", "([^/]+)") + "$")
+	return route{method: method, pattern: pattern, handler: handler}
+}
+
+// returns the handler serving all NodeAPIRoute(s).
+func (n *Node) router() http.Handler {
+	// the ed25519 routes come first as the bech32 routes with a parameter in the same position would match them as well
+	routes := []route{
+		newRoute(http.MethodGet, iotago.NodeAPIRouteHealth, n.health),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteInfo, n.info),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteTips, n.tipsHandler),
+		newRoute(http.MethodPost, iotago.NodeAPIRouteMessages, n.submit),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMessages, n.messageIDsByIndex),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMessageData, n.messageData),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMessageMetadata, n.messageMetadata),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMessageBytes, n.messageBytes),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMessageChildren, n.messageChildren),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMilestone, n.milestone),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteMilestoneUTXOChanges, n.milestoneUTXOChanges),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteOutput, n.output),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteAddressEd25519Balance, n.ed25519Balance),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteAddressEd25519Outputs, n.ed25519Outputs),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteAddressBech32Balance, n.bech32Balance),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteAddressBech32Outputs, n.bech32Outputs),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteTreasury, n.treasury),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteReceipts, n.receiptsHandler),
+		newRoute(http.MethodGet, iotago.NodeAPIRouteReceiptsByMigratedAtIndex, n.receiptsByMigratedAtIndex),
+		newRoute(http.MethodGet, iotago.NodeAPIRoutePeers, n.peersHandler),
+		newRoute(http.MethodPost, iotago.NodeAPIRoutePeers, n.addPeer),
+		newRoute(http.MethodGet, iotago.NodeAPIRoutePeer, n.peer),
+		newRoute(http.MethodDelete, iotago.NodeAPIRoutePeer, n.removePeer),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, route := range routes {
+			if route.method != r.Method {
+				continue
+			}
+			if matches := route.pattern.FindStringSubmatch(r.URL.Path); matches != nil {
+				route.handler(w, r, matches[1:])
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "route %s %s not found", r.Method, r.URL.Path)
+	})
+}
+
+// writes the given data within a HTTPOkResponseEnvelope.
+func writeData(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&iotago.HTTPOkResponseEnvelope{Data: data})
+}
+
+// writes a HTTPErrorResponseEnvelope with the given message.
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	errRes := &iotago.HTTPErrorResponseEnvelope{}
+	errRes.Error.Code = strconv.Itoa(status)
+	errRes.Error.Message = fmt.Sprintf(format, args...)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errRes)
+}
+
+// returns the bounds of the page requested by the cursor of the given request and the cursor of the following page.
+func (n *Node) page(r *http.Request, total int) (int, int, *string, error) {
+	start := 0
+	if cursor := r.URL.Query().Get(iotago.NodeAPIQueryParamCursor); cursor != "" {
+		offset, err := strconv.Atoi(cursor)
+		if err != nil || offset < 0 || offset > total {
+			return 0, 0, nil, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		start = offset
+	}
+
+	end := total
+	if n.opts.maxResults > 0 && end-start > n.opts.maxResults {
+		end = start + n.opts.maxResults
+	}
+
+	var next *string
+	if end < total && n.opts.cursors {
+		cursor := strconv.Itoa(end)
+		next = &cursor
+	}
+	return start, end, next, nil
+}
+
+// returns the stored message with the ID given as hex.
+func (n *Node) messageByHexID(w http.ResponseWriter, msgIDHex string) (*message, bool) {
+	msgIDBytes, err := hex.DecodeString(msgIDHex)
+	if err != nil || len(msgIDBytes) != iotago.MessageIDLength {
+		writeError(w, http.StatusBadRequest, "invalid message ID %q", msgIDHex)
+		return nil, false
+	}
+	var msgID iotago.MessageID
+	copy(msgID[:], msgIDBytes)
+
+	msg, has := n.messages[msgID]
+	if !has {
+		writeError(w, http.StatusNotFound, "message %s not found", msgIDHex)
+		return nil, false
+	}
+	return msg, true
+}
+
+// returns the milestone with the index given as string.
+func (n *Node) milestoneByIndex(w http.ResponseWriter, indexStr string) (*milestone, bool) {
+	index, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid milestone index %q", indexStr)
+		return nil, false
+	}
+	ms, has := n.milestones[uint32(index)]
+	if !has {
+		writeError(w, http.StatusNotFound, "milestone %d not found", index)
+		return nil, false
+	}
+	return ms, true
+}
+
+func (n *Node) health(w http.ResponseWriter, _ *http.Request, _ []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if !n.healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (n *Node) info(w http.ResponseWriter, _ *http.Request, _ []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	features := []string{}
+	if n.opts.powEnabled {
+		features = append(features, FeaturePoW)
+	}
+
+	writeData(w, http.StatusOK, &iotago.NodeInfoResponse{
+		Name:                     "nodetest",
+		Version:                  "1.0.0",
+		IsHealthy:                n.healthy,
+		NetworkID:                n.opts.networkName,
+		Bech32HRP:                string(n.opts.bech32HRP),
+		MinPowScore:              n.opts.minPowScore,
+		LatestMilestoneTimestamp: n.latestMsTime,
+		LatestMilestoneIndex:     n.latestMsIndex,
+		ConfirmedMilestoneIndex:  n.opts.ledger.Index(),
+		Features:                 features,
+	})
+}
+
+func (n *Node) tipsHandler(w http.ResponseWriter, _ *http.Request, _ []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	tips := n.sortedTips()
+	res := &iotago.NodeTipsResponse{TipsHex: make([]string, len(tips))}
+	for i, tip := range tips {
+		res.TipsHex[i] = hex.EncodeToString(tip[:])
+	}
+	writeData(w, http.StatusOK, res)
+}
+
+func (n *Node) submit(w http.ResponseWriter, r *http.Request, _ []string) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "unable to read message: %s", err)
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		msg := &iotago.Message{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid message: %s", err)
+			return
+		}
+		if data, err = msg.Serialize(serializer.DeSeriModeNoValidation); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid message: %s", err)
+			return
+		}
+	}
+
+	msgID, err := n.submitMessage(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid message: %s", err)
+		return
+	}
+
+	msgIDHex := hex.EncodeToString(msgID[:])
+	w.Header().Set("Location", msgIDHex)
+	writeData(w, http.StatusCreated, struct {
+		MessageID string `json:"messageId"`
+	}{MessageID: msgIDHex})
+}
+
+func (n *Node) messageIDsByIndex(w http.ResponseWriter, r *http.Request, _ []string) {
+	indexHex := r.URL.Query().Get("index")
+	index, err := hex.DecodeString(indexHex)
+	if err != nil || len(index) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid index %q", indexHex)
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	msgIDs := n.indexes[string(index)]
+	start, end, cursor, err := n.page(r, len(msgIDs))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	res := &iotago.MessageIDsByIndexResponse{
+		Index:      indexHex,
+		MaxResults: uint32(n.opts.maxResults),
+		Count:      uint32(end - start),
+		MessageIDs: make([]string, 0, end-start),
+		Cursor:     cursor,
+	}
+	for _, msgID := range msgIDs[start:end] {
+		res.MessageIDs = append(res.MessageIDs, hex.EncodeToString(msgID[:]))
+	}
+	writeData(w, http.StatusOK, res)
+}
+
+func (n *Node) messageData(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	msg, ok := n.messageByHexID(w, params[0])
+	if !ok {
+		return
+	}
+	writeData(w, http.StatusOK, msg.msg)
+}
+
+func (n *Node) messageMetadata(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	msg, ok := n.messageByHexID(w, params[0])
+	if !ok {
+		return
+	}
+
+	res := &iotago.MessageMetadataResponse{
+		MessageID: hex.EncodeToString(msg.id[:]),
+		Parents:   make([]string, len(msg.msg.Parents)),
+		Solid:     true,
+	}
+	for i, parent := range msg.msg.Parents {
+		res.Parents[i] = hex.EncodeToString(parent[:])
+	}
+
+	if msg.referencedBy == 0 {
+		shouldPromote, shouldReattach := false, false
+		res.ShouldPromote, res.ShouldReattach = &shouldPromote, &shouldReattach
+		writeData(w, http.StatusOK, res)
+		return
+	}
+
+	referencedBy, inclusionState := msg.referencedBy, msg.inclusionState
+	res.ReferencedByMilestoneIndex = &referencedBy
+	res.LedgerInclusionState = &inclusionState
+	res.ConflictReason = msg.conflictReason
+	if msg.milestoneIndex != 0 {
+		milestoneIndex := msg.milestoneIndex
+		res.MilestoneIndex = &milestoneIndex
+	}
+	writeData(w, http.StatusOK, res)
+}
+
+func (n *Node) messageBytes(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	msg, ok := n.messageByHexID(w, params[0])
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(msg.data)
+}
+
+func (n *Node) messageChildren(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	msg, ok := n.messageByHexID(w, params[0])
+	if !ok {
+		return
+	}
+
+	children := msg.children
+	if n.opts.maxResults > 0 && len(children) > n.opts.maxResults {
+		children = children[:n.opts.maxResults]
+	}
+
+	res := &iotago.ChildrenResponse{
+		MessageID:  hex.EncodeToString(msg.id[:]),
+		MaxResults: uint32(n.opts.maxResults),
+		Count:      uint32(len(children)),
+		Children:   make([]string, len(children)),
+	}
+	for i, child := range children {
+		res.Children[i] = hex.EncodeToString(child[:])
+	}
+	writeData(w, http.StatusOK, res)
+}
+
+func (n *Node) milestone(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	ms, ok := n.milestoneByIndex(w, params[0])
+	if !ok {
+		return
+	}
+	writeData(w, http.StatusOK, &iotago.MilestoneResponse{
+		Index:     ms.index,
+		MessageID: hex.EncodeToString(ms.msgID[:]),
+		Time:      ms.timestamp,
+	})
+}
+
+func (n *Node) milestoneUTXOChanges(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	ms, ok := n.milestoneByIndex(w, params[0])
+	if !ok {
+		return
+	}
+
+	res := &iotago.MilestoneUTXOChangesResponse{
+		Index:           ms.index,
+		CreatedOutputs:  make([]string, len(ms.created)),
+		ConsumedOutputs: make([]string, len(ms.consumed)),
+	}
+	for i, outputID := range ms.created {
+		res.CreatedOutputs[i] = outputID.ToHex()
+	}
+	for i, outputID := range ms.consumed {
+		res.ConsumedOutputs[i] = outputID.ToHex()
+	}
+	writeData(w, http.StatusOK, res)
+}
+
+func (n *Node) output(w http.ResponseWriter, _ *http.Request, params []string) {
+	outputIDBytes, err := hex.DecodeString(params[0])
+	if err != nil || len(outputIDBytes) != iotago.TransactionIDLength+serializer.UInt16ByteSize {
+		writeError(w, http.StatusBadRequest, "invalid output ID %q", params[0])
+		return
+	}
+	var outputID iotago.UTXOInputID
+	copy(outputID[:], outputIDBytes)
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	spent := false
+	output, err := n.opts.ledger.Output(outputID)
+	if err != nil {
+		consumed, err := n.opts.ledger.Spent(outputID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "output %s not found", params[0])
+			return
+		}
+		output, spent = consumed.Output, true
+	}
+
+	rawOutput, err := json.Marshal(output.Output)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "unable to serialize output: %s", err)
+		return
+	}
+	rawOutputJSON := json.RawMessage(rawOutput)
+
+	outputIDHex := iotago.OutputIDHex(params[0])
+	txID, outputIndex, _ := outputIDHex.SplitParts()
+	writeData(w, http.StatusOK, &iotago.NodeOutputResponse{
+		MessageID:     hex.EncodeToString(output.MessageID[:]),
+		TransactionID: hex.EncodeToString(txID[:]),
+		OutputIndex:   outputIndex,
+		Spent:         spent,
+		LedgerIndex:   uint64(n.opts.ledger.Index()),
+		RawOutput:     &rawOutputJSON,
+	})
+}
+
+// parses the hex encoded Ed25519 address.
+func parseEd25519Address(w http.ResponseWriter, addrHex string) (iotago.Address, bool) {
+	addrBytes, err := hex.DecodeString(addrHex)
+	if err != nil || len(addrBytes) != iotago.Ed25519AddressBytesLength {
+		writeError(w, http.StatusBadRequest, "invalid ed25519 address %q", addrHex)
+		return nil, false
+	}
+	addr := &iotago.Ed25519Address{}
+	copy(addr[:], addrBytes)
+	return addr, true
+}
+
+// parses the Bech32 encoded address, which must use the HRP of the node.
+func (n *Node) parseBech32Address(w http.ResponseWriter, bech32Addr string) (iotago.Address, bool) {
+	hrp, addr, err := iotago.ParseBech32(bech32Addr)
+	if err != nil || hrp != n.opts.bech32HRP {
+		writeError(w, http.StatusBadRequest, "invalid bech32 address %q", bech32Addr)
+		return nil, false
+	}
+	return addr, true
+}
+
+func (n *Node) ed25519Balance(w http.ResponseWriter, r *http.Request, params []string) {
+	if addr, ok := parseEd25519Address(w, params[0]); ok {
+		n.balance(w, r, addr)
+	}
+}
+
+func (n *Node) bech32Balance(w http.ResponseWriter, r *http.Request, params []string) {
+	if addr, ok := n.parseBech32Address(w, params[0]); ok {
+		n.balance(w, r, addr)
+	}
+}
+
+func (n *Node) balance(w http.ResponseWriter, _ *http.Request, addr iotago.Address) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	addrState := n.opts.ledger.AddressState(addr)
+	writeData(w, http.StatusOK, &iotago.AddressBalanceResponse{
+		AddressType: addr.Type(),
+		Address:     addr.String(),
+		Balance:     addrState.Balance,
+		DustAllowed: addrState.DustAllowanceSum > 0,
+		LedgerIndex: uint64(n.opts.ledger.Index()),
+	})
+}
+
+func (n *Node) ed25519Outputs(w http.ResponseWriter, r *http.Request, params []string) {
+	if addr, ok := parseEd25519Address(w, params[0]); ok {
+		n.addressOutputs(w, r, addr)
+	}
+}
+
+func (n *Node) bech32Outputs(w http.ResponseWriter, r *http.Request, params []string) {
+	if addr, ok := n.parseBech32Address(w, params[0]); ok {
+		n.addressOutputs(w, r, addr)
+	}
+}
+
+// serves the output IDs of the given address. Only the current ledger state is served,
+// the ledger index a query is pinned to is ignored.
+func (n *Node) addressOutputs(w http.ResponseWriter, r *http.Request, addr iotago.Address) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	outputIDs := outputIDsOf(n.opts.ledger.UnspentOutputsByAddress(addr))
+	if includeSpent, _ := strconv.ParseBool(r.URL.Query().Get("include-spent")); includeSpent {
+		outputIDs = append(outputIDs, n.spentByAddr[addr.String()]...)
+	}
+
+	start, end, cursor, err := n.page(r, len(outputIDs))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	res := &iotago.AddressOutputsResponse{
+		AddressType: addr.Type(),
+		Address:     addr.String(),
+		MaxResults:  uint32(n.opts.maxResults),
+		Count:       uint32(end - start),
+		OutputIDs:   make([]iotago.OutputIDHex, 0, end-start),
+		LedgerIndex: uint64(n.opts.ledger.Index()),
+		Cursor:      cursor,
+	}
+	for _, outputID := range outputIDs[start:end] {
+		res.OutputIDs = append(res.OutputIDs, iotago.OutputIDHex(outputID.ToHex()))
+	}
+	writeData(w, http.StatusOK, res)
+}
+
+// returns the IDs of the given outputs in lexical order, so that pages stay stable between requests.
+func outputIDsOf(outputs []*ledger.Output) []iotago.UTXOInputID {
+	outputIDs := make([]iotago.UTXOInputID, len(outputs))
+	for i, output := range outputs {
+		outputIDs[i] = output.ID
+	}
+	sort.Slice(outputIDs, func(i, j int) bool {
+		return bytes.Compare(outputIDs[i][:], outputIDs[j][:]) < 0
+	})
+	return outputIDs
+}
+
+func (n *Node) treasury(w http.ResponseWriter, _ *http.Request, _ []string) {
+	treasury := n.opts.ledger.Treasury()
+	if treasury == nil {
+		writeError(w, http.StatusNotFound, "no treasury")
+		return
+	}
+	writeData(w, http.StatusOK, &iotago.TreasuryResponse{
+		MilestoneID: hex.EncodeToString(treasury.MilestoneID[:]),
+		Amount:      treasury.Amount,
+	})
+}
+
+func (n *Node) receiptsHandler(w http.ResponseWriter, _ *http.Request, _ []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	writeData(w, http.StatusOK, &iotago.ReceiptsResponse{Receipts: append([]*iotago.ReceiptTuple{}, n.receipts...)})
+}
+
+func (n *Node) receiptsByMigratedAtIndex(w http.ResponseWriter, _ *http.Request, params []string) {
+	migratedAt, err := strconv.ParseUint(params[0], 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid migrated at index %q", params[0])
+		return
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	receipts := []*iotago.ReceiptTuple{}
+	for _, receipt := range n.receipts {
+		if receipt.Receipt.MigratedAt == uint32(migratedAt) {
+			receipts = append(receipts, receipt)
+		}
+	}
+	writeData(w, http.StatusOK, &iotago.ReceiptsResponse{Receipts: receipts})
+}
+
+// returns the PeerResponse of the given peer.
+func (p *peer) response() *iotago.PeerResponse {
+	return &iotago.PeerResponse{
+		ID:             p.id,
+		MultiAddresses: []string{p.multiAddress},
+		Alias:          p.alias,
+		Relation:       "static",
+	}
+}
+
+func (n *Node) peersHandler(w http.ResponseWriter, _ *http.Request, _ []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	peers := make([]*iotago.PeerResponse, 0, len(n.peers))
+	for _, p := range n.peers {
+		peers = append(peers, p.response())
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+	writeData(w, http.StatusOK, peers)
+}
+
+func (n *Node) addPeer(w http.ResponseWriter, r *http.Request, _ []string) {
+	req := &iotago.AddPeerRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request: %s", err)
+		return
+	}
+
+	// the peer ID is the last component of the multi address, i.e. "/ip4/127.0.0.1/tcp/15600/p2p/<peerID>"
+	parts := strings.Split(req.MultiAddress, "/")
+	if len(parts) < 3 || parts[len(parts)-2] != "p2p" || parts[len(parts)-1] == "" {
+		writeError(w, http.StatusBadRequest, "invalid multi address %q", req.MultiAddress)
+		return
+	}
+
+	p := &peer{id: parts[len(parts)-1], multiAddress: req.MultiAddress, alias: req.Alias}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.peers[p.id] = p
+	writeData(w, http.StatusOK, p.response())
+}
+
+func (n *Node) peer(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	p, has := n.peers[params[0]]
+	if !has {
+		writeError(w, http.StatusNotFound, "peer %s not found", params[0])
+		return
+	}
+	writeData(w, http.StatusOK, p.response())
+}
+
+func (n *Node) removePeer(w http.ResponseWriter, _ *http.Request, params []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, has := n.peers[params[0]]; !has {
+		writeError(w, http.StatusNotFound, "peer %s not found", params[0])
+		return
+	}
+	delete(n.peers, params[0])
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,195 @@
+package dispatcher
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+)
+
+const (
+	// KeyFileVersion defines the version of the encrypted key file format.
+	KeyFileVersion = 1
+
+	// scrypt parameters used to derive the key file encryption key from the passphrase.
+	keyFileScryptN      = 1 << 15
+	keyFileScryptR      = 8
+	keyFileScryptP      = 1
+	keyFileSaltLength   = 32
+	keyFileScryptKeyLen = chacha20poly1305.KeySize
+)
+
+var (
+	// ErrKeyNotFound gets returned when a KeyStore does not hold the private key of a public key.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrKeyFileInvalid gets returned when an encrypted key file can't be decoded or decrypted.
+	ErrKeyFileInvalid = errors.New("invalid key file")
+)
+
+// KeyStore holds the private keys a Server signs with.
+type KeyStore interface {
+	// PrivateKey returns the private key of the given public key or an error wrapping ErrKeyNotFound.
+	PrivateKey(pubKey iotago.MilestonePublicKey) (ed25519.PrivateKey, error)
+}
+
+// NewInMemoryKeyStore creates a new InMemoryKeyStore holding the given private keys.
+func NewInMemoryKeyStore(prvKeys ...ed25519.PrivateKey) *InMemoryKeyStore {
+	ks := &InMemoryKeyStore{keys: make(iotago.MilestonePublicKeyMapping)}
+	for _, prvKey := range prvKeys {
+		ks.Add(prvKey)
+	}
+	return ks
+}
+
+// InMemoryKeyStore is a KeyStore which holds its private keys in memory.
+type InMemoryKeyStore struct {
+	mu   sync.RWMutex
+	keys iotago.MilestonePublicKeyMapping
+}
+
+// Add adds the given private key to the InMemoryKeyStore.
+func (ks *InMemoryKeyStore) Add(prvKey ed25519.PrivateKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	var pubKey iotago.MilestonePublicKey
+	copy(pubKey[:], prvKey.Public().(ed25519.PublicKey))
+	ks.keys[pubKey] = prvKey
+}
+
+// PublicKeys returns the public keys of all private keys held by the InMemoryKeyStore.
+func (ks *InMemoryKeyStore) PublicKeys() []iotago.MilestonePublicKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	pubKeys := make([]iotago.MilestonePublicKey, 0, len(ks.keys))
+	for pubKey := range ks.keys {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys
+}
+
+func (ks *InMemoryKeyStore) PrivateKey(pubKey iotago.MilestonePublicKey) (ed25519.PrivateKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	prvKey, has := ks.keys[pubKey]
+	if !has {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, hex.EncodeToString(pubKey[:]))
+	}
+	return prvKey, nil
+}
+
+// the JSON representation of an encrypted key file.
+type jsonKeyFile struct {
+	Version    int    `json:"version"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// WriteEncryptedKeyFile writes the given private keys into a key file at the given path.
+// The keys are encrypted with XChaCha20-Poly1305 using a key derived from the passphrase via scrypt.
+func WriteEncryptedKeyFile(path string, passphrase []byte, prvKeys ...ed25519.PrivateKey) error {
+	plaintext := make([]byte, 0, len(prvKeys)*ed25519.PrivateKeySize)
+	for _, prvKey := range prvKeys {
+		plaintext = append(plaintext, prvKey...)
+	}
+
+	salt := make([]byte, keyFileSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("unable to generate key file salt: %w", err)
+	}
+
+	aead, err := keyFileAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("unable to generate key file nonce: %w", err)
+	}
+
+	keyFileJSON, err := json.MarshalIndent(&jsonKeyFile{
+		Version:    KeyFileVersion,
+		Salt:       hex.EncodeToString(salt),
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, keyFileJSON, 0o600)
+}
+
+// NewKeyStoreFromEncryptedKeyFile decrypts the key file at the given path with the given passphrase
+// and returns an InMemoryKeyStore holding its private keys.
+func NewKeyStoreFromEncryptedKeyFile(path string, passphrase []byte) (*InMemoryKeyStore, error) {
+	keyFileJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read key file: %w", err)
+	}
+
+	keyFile := &jsonKeyFile{}
+	if err := json.Unmarshal(keyFileJSON, keyFile); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyFileInvalid, err)
+	}
+	if keyFile.Version != KeyFileVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrKeyFileInvalid, keyFile.Version)
+	}
+
+	salt, err := hex.DecodeString(keyFile.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode salt: %s", ErrKeyFileInvalid, err)
+	}
+	nonce, err := hex.DecodeString(keyFile.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode nonce: %s", ErrKeyFileInvalid, err)
+	}
+	ciphertext, err := hex.DecodeString(keyFile.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode ciphertext: %s", ErrKeyFileInvalid, err)
+	}
+
+	aead, err := keyFileAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce length %d", ErrKeyFileInvalid, len(nonce))
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decrypt, wrong passphrase?", ErrKeyFileInvalid)
+	}
+	if len(plaintext)%ed25519.PrivateKeySize != 0 {
+		return nil, fmt.Errorf("%w: invalid plaintext length %d", ErrKeyFileInvalid, len(plaintext))
+	}
+
+	ks := NewInMemoryKeyStore()
+	for i := 0; i < len(plaintext); i += ed25519.PrivateKeySize {
+		ks.Add(ed25519.PrivateKey(plaintext[i : i+ed25519.PrivateKeySize]))
+	}
+	return ks, nil
+}
+
+// derives the key file AEAD from the given passphrase and salt.
+func keyFileAEAD(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, keyFileScryptN, keyFileScryptR, keyFileScryptP, keyFileScryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive key file encryption key: %w", err)
+	}
+	return chacha20poly1305.NewX(key)
+}
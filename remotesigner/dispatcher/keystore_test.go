@@ -0,0 +1,47 @@
+package dispatcher_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/remotesigner/dispatcher"
+)
+
+// generates a random milestone key pair.
+func randMilestoneKey(t *testing.T) (iotago.MilestonePublicKey, ed25519.PrivateKey) {
+	pubKey, prvKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var msPubKey iotago.MilestonePublicKey
+	copy(msPubKey[:], pubKey)
+	return msPubKey, prvKey
+}
+
+func TestEncryptedKeyFile(t *testing.T) {
+	pubKey1, prvKey1 := randMilestoneKey(t)
+	pubKey2, prvKey2 := randMilestoneKey(t)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	passphrase := []byte("correct horse battery staple")
+
+	require.NoError(t, dispatcher.WriteEncryptedKeyFile(path, passphrase, prvKey1, prvKey2))
+
+	ks, err := dispatcher.NewKeyStoreFromEncryptedKeyFile(path, passphrase)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []iotago.MilestonePublicKey{pubKey1, pubKey2}, ks.PublicKeys())
+
+	prvKey, err := ks.PrivateKey(pubKey2)
+	require.NoError(t, err)
+	require.Equal(t, prvKey2, prvKey)
+
+	unknownPubKey, _ := randMilestoneKey(t)
+	_, err = ks.PrivateKey(unknownPubKey)
+	require.True(t, errors.Is(err, dispatcher.ErrKeyNotFound))
+
+	_, err = dispatcher.NewKeyStoreFromEncryptedKeyFile(path, []byte("wrong"))
+	require.True(t, errors.Is(err, dispatcher.ErrKeyFileInvalid))
+}
@@ -0,0 +1,368 @@
+// Package dispatcher provides a reference implementation of the remotesigner SignatureDispatcher service.
+//
+// The Server signs milestone essences with private keys held by a pluggable KeyStore. It only signs
+// essences which parse as milestone essences, refuses to sign conflicting milestones (double-signing)
+// and writes an audit log entry for every produced signature. The Server expects clients to pass the serialized
+// milestone essence via the remotesigner.MilestoneEssenceMetadataKey gRPC metadata, as done by
+// iotago.MilestoneSigningCoordinator. Transport security (i.e. mutual TLS) is configured on the grpc.Server
+// the Server is registered on.
+package dispatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"golang.org/x/crypto/blake2b"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/remotesigner"
+)
+
+var (
+	// ErrMilestoneEssenceMissing gets returned when a request does not carry the serialized milestone essence.
+	ErrMilestoneEssenceMissing = errors.New("serialized milestone essence missing")
+	// ErrMilestoneEssenceInvalid gets returned when the serialized milestone essence does not parse
+	// or does not match the essence hash of the request.
+	ErrMilestoneEssenceInvalid = errors.New("invalid milestone essence")
+	// ErrPublicKeyNotInMilestone gets returned when a requested public key is not part of the milestone.
+	ErrPublicKeyNotInMilestone = errors.New("public key is not part of the milestone")
+	// ErrMilestoneIndexNotMonotonic gets returned when a milestone with an index lower than the last signed one
+	// or a conflicting milestone with the same index is requested to be signed.
+	ErrMilestoneIndexNotMonotonic = errors.New("milestone index is not monotonic")
+)
+
+// ServerOption is a function setting a Server option.
+type ServerOption func(opts *ServerOptions)
+
+// ServerOptions define options for the Server.
+type ServerOptions struct {
+	// The writer the audit log is written to.
+	auditLog io.Writer
+	// The path of the file persisting the last signed milestone.
+	stateFilePath string
+}
+
+// applies the given ServerOption.
+func (so *ServerOptions) apply(opts ...ServerOption) {
+	for _, opt := range opts {
+		opt(so)
+	}
+}
+
+// WithAuditLog sets the writer to which an audit log entry (a JSON object per line) is written for every produced signature.
+func WithAuditLog(w io.Writer) ServerOption {
+	return func(opts *ServerOptions) {
+		opts.auditLog = w
+	}
+}
+
+// WithStateFile sets the path of the file persisting the last signed milestone, so that the
+// double-signing protection survives restarts.
+func WithStateFile(path string) ServerOption {
+	return func(opts *ServerOptions) {
+		opts.stateFilePath = path
+	}
+}
+
+// the default options applied to the Server.
+var defaultServerOptions = []ServerOption{
+	WithAuditLog(ioutil.Discard),
+}
+
+// AuditLogEntry is an entry of the audit log.
+type AuditLogEntry struct {
+	// The time at which the signature was produced.
+	Time time.Time `json:"time"`
+	// The address of the client which requested the signature.
+	Peer string `json:"peer"`
+	// The index of the signed milestone.
+	MilestoneIndex uint32 `json:"milestoneIndex"`
+	// The hex encoded hash of the signed essence.
+	Essence string `json:"essence"`
+	// The hex encoded public key.
+	PublicKey string `json:"publicKey"`
+	// The hex encoded signature.
+	Signature string `json:"signature"`
+}
+
+// the persisted state of the Server.
+type serverState struct {
+	// The serialized essence of the last signed milestone.
+	LastEssence string `json:"lastEssence"`
+}
+
+// NewServer creates a new Server signing with the keys of the given KeyStore.
+func NewServer(keyStore KeyStore, opts ...ServerOption) (*Server, error) {
+	options := &ServerOptions{}
+	options.apply(defaultServerOptions...)
+	options.apply(opts...)
+
+	s := &Server{opts: options, keyStore: keyStore}
+	if err := s.loadState(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Server is a remotesigner.SignatureDispatcherServer signing milestone essences with the keys of a KeyStore.
+type Server struct {
+	remotesigner.UnimplementedSignatureDispatcherServer
+	mu       sync.Mutex
+	opts     *ServerOptions
+	keyStore KeyStore
+	// the last signed milestone
+	last *iotago.Milestone
+}
+
+// LastSignedIndex returns the index of the last signed milestone.
+func (s *Server) LastSignedIndex() uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		return 0
+	}
+	return s.last.Index
+}
+
+func (s *Server) SignMilestone(ctx context.Context, req *remotesigner.SignMilestoneRequest) (*remotesigner.SignMilestoneResponse, error) {
+	ms, err := milestoneFromRequest(ctx, req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	prvKeys := make([]ed25519.PrivateKey, len(req.GetPubKeys()))
+	for i, pubKeyBytes := range req.GetPubKeys() {
+		var pubKey iotago.MilestonePublicKey
+		if len(pubKeyBytes) != len(pubKey) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid length %d of public key at pos %d", len(pubKeyBytes), i)
+		}
+		copy(pubKey[:], pubKeyBytes)
+
+		if !containsPublicKey(ms.PublicKeys, pubKey) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s: %s", ErrPublicKeyNotInMilestone, hex.EncodeToString(pubKeyBytes))
+		}
+
+		prvKey, err := s.keyStore.PrivateKey(pubKey)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				return nil, status.Error(codes.NotFound, err.Error())
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		prvKeys[i] = prvKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkMonotonic(ms); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	// the state must be persisted before any signature leaves the server
+	if err := s.storeState(ms); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.last = ms
+
+	peerAddr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		peerAddr = p.Addr.String()
+	}
+
+	res := &remotesigner.SignMilestoneResponse{Signatures: make([][]byte, len(prvKeys))}
+	for i, prvKey := range prvKeys {
+		sig := ed25519.Sign(prvKey, req.GetMsEssence())
+		res.Signatures[i] = sig
+
+		if err := s.audit(&AuditLogEntry{
+			Time:           time.Now(),
+			Peer:           peerAddr,
+			MilestoneIndex: ms.Index,
+			Essence:        hex.EncodeToString(req.GetMsEssence()),
+			PublicKey:      hex.EncodeToString(req.GetPubKeys()[i]),
+			Signature:      hex.EncodeToString(sig),
+		}); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return res, nil
+}
+
+// parses the milestone essence passed via the metadata and checks it against the essence hash of the request.
+func milestoneFromRequest(ctx context.Context, req *remotesigner.SignMilestoneRequest) (*iotago.Milestone, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ErrMilestoneEssenceMissing
+	}
+	values := md.Get(remotesigner.MilestoneEssenceMetadataKey)
+	if len(values) != 1 {
+		return nil, ErrMilestoneEssenceMissing
+	}
+	msEssenceBytes := []byte(values[0])
+
+	ms := &iotago.Milestone{}
+	bytesRead, err := ms.DeserializeEssence(msEssenceBytes, serializer.DeSeriModePerformValidation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMilestoneEssenceInvalid, err)
+	}
+	if bytesRead != len(msEssenceBytes) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrMilestoneEssenceInvalid, len(msEssenceBytes)-bytesRead)
+	}
+
+	msEssence := blake2b.Sum256(msEssenceBytes)
+	if !bytes.Equal(msEssence[:], req.GetMsEssence()) {
+		return nil, fmt.Errorf("%w: essence does not match the requested essence hash", ErrMilestoneEssenceInvalid)
+	}
+	return ms, nil
+}
+
+// checks that the given milestone does not conflict with the last signed one. A milestone with the same index
+// may only be signed again if it just lists a strict subset of the public keys it was signed for,
+// i.e. because a coordinator drops public keys which failed to sign.
+func (s *Server) checkMonotonic(ms *iotago.Milestone) error {
+	if s.last == nil || ms.Index > s.last.Index {
+		return nil
+	}
+	if ms.Index < s.last.Index {
+		return fmt.Errorf("%w: last signed %d, requested %d", ErrMilestoneIndexNotMonotonic, s.last.Index, ms.Index)
+	}
+
+	equal, err := equalIgnoringPublicKeys(s.last, ms)
+	if err != nil {
+		return err
+	}
+	if !equal {
+		return fmt.Errorf("%w: conflicting milestone with index %d", ErrMilestoneIndexNotMonotonic, ms.Index)
+	}
+	if !isStrictSubset(ms.PublicKeys, s.last.PublicKeys) {
+		return fmt.Errorf("%w: public keys of milestone %d are no strict subset of the ones it was signed for", ErrMilestoneIndexNotMonotonic, ms.Index)
+	}
+	return nil
+}
+
+// tells whether the given public keys are a strict subset of the given set of public keys.
+func isStrictSubset(pubKeys []iotago.MilestonePublicKey, set []iotago.MilestonePublicKey) bool {
+	if len(pubKeys) >= len(set) {
+		return false
+	}
+	for _, pubKey := range pubKeys {
+		if !containsPublicKey(set, pubKey) {
+			return false
+		}
+	}
+	return true
+}
+
+// tells whether the essences of the given milestones are equal apart from their public keys.
+func equalIgnoringPublicKeys(a *iotago.Milestone, b *iotago.Milestone) (bool, error) {
+	essenceWithoutPubKeys := func(ms *iotago.Milestone) ([]byte, error) {
+		msCopy := *ms
+		msCopy.PublicKeys = []iotago.MilestonePublicKey{{}}
+		return msCopy.EssenceBytes()
+	}
+
+	aBytes, err := essenceWithoutPubKeys(a)
+	if err != nil {
+		return false, err
+	}
+	bBytes, err := essenceWithoutPubKeys(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+// tells whether the given public key is within the given public keys.
+func containsPublicKey(pubKeys []iotago.MilestonePublicKey, pubKey iotago.MilestonePublicKey) bool {
+	for _, p := range pubKeys {
+		if p == pubKey {
+			return true
+		}
+	}
+	return false
+}
+
+// writes the given entry to the audit log.
+func (s *Server) audit(entry *AuditLogEntry) error {
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := s.opts.auditLog.Write(append(entryJSON, '\n')); err != nil {
+		return fmt.Errorf("unable to write audit log: %w", err)
+	}
+	return nil
+}
+
+// loads the last signed milestone from the state file.
+func (s *Server) loadState() error {
+	if s.opts.stateFilePath == "" {
+		return nil
+	}
+
+	stateJSON, err := ioutil.ReadFile(s.opts.stateFilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("unable to read state file: %w", err)
+	}
+
+	state := &serverState{}
+	if err := json.Unmarshal(stateJSON, state); err != nil {
+		return fmt.Errorf("unable to parse state file: %w", err)
+	}
+	msEssenceBytes, err := hex.DecodeString(state.LastEssence)
+	if err != nil {
+		return fmt.Errorf("unable to decode last essence of state file: %w", err)
+	}
+
+	ms := &iotago.Milestone{}
+	if _, err := ms.DeserializeEssence(msEssenceBytes, serializer.DeSeriModePerformValidation); err != nil {
+		return fmt.Errorf("unable to parse last essence of state file: %w", err)
+	}
+	s.last = ms
+	return nil
+}
+
+// persists the given milestone as the last signed one.
+func (s *Server) storeState(ms *iotago.Milestone) error {
+	if s.opts.stateFilePath == "" {
+		return nil
+	}
+
+	msEssenceBytes, err := ms.EssenceBytes()
+	if err != nil {
+		return err
+	}
+	stateJSON, err := json.Marshal(&serverState{LastEssence: hex.EncodeToString(msEssenceBytes)})
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that the state file is never left half written
+	tmpPath := s.opts.stateFilePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, stateJSON, 0o600); err != nil {
+		return fmt.Errorf("unable to write state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.opts.stateFilePath); err != nil {
+		return fmt.Errorf("unable to write state file: %w", err)
+	}
+	return nil
+}
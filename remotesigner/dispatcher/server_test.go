@@ -0,0 +1,183 @@
+package dispatcher_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/remotesigner"
+	"github.com/iotaledger/iota.go/v2/remotesigner/dispatcher"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+)
+
+// starts the given Server on an in-memory listener and returns a client connected to it.
+func startServer(t *testing.T, srv *dispatcher.Server) remotesigner.SignatureDispatcherClient {
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	remotesigner.RegisterSignatureDispatcherServer(grpcServer, srv)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return remotesigner.NewSignatureDispatcherClient(conn)
+}
+
+// builds a request to sign the given milestone with the given public keys, passing its serialized essence as metadata.
+func signRequest(t *testing.T, ms *iotago.Milestone, pubKeys ...iotago.MilestonePublicKey) (context.Context, *remotesigner.SignMilestoneRequest) {
+	msEssenceBytes, err := ms.EssenceBytes()
+	require.NoError(t, err)
+	msEssence := blake2b.Sum256(msEssenceBytes)
+
+	req := &remotesigner.SignMilestoneRequest{MsEssence: msEssence[:]}
+	for _, pubKey := range pubKeys {
+		req.PubKeys = append(req.PubKeys, append([]byte{}, pubKey[:]...))
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), remotesigner.MilestoneEssenceMetadataKey, string(msEssenceBytes))
+	return ctx, req
+}
+
+func TestServer_SignMilestone(t *testing.T) {
+	pubKey1, prvKey1 := randMilestoneKey(t)
+	pubKey2, prvKey2 := randMilestoneKey(t)
+	ks := dispatcher.NewInMemoryKeyStore(prvKey1, prvKey2)
+
+	newMilestone := func(t *testing.T, index uint32) *iotago.Milestone {
+		ms, err := iotago.NewMilestone(index, 1337, tpkg.SortedRand32BytArray(1), tpkg.Rand32ByteArray(), []iotago.MilestonePublicKey{pubKey1, pubKey2})
+		require.NoError(t, err)
+		return ms
+	}
+
+	t.Run("ok - signs and audits", func(t *testing.T) {
+		auditLog := &bytes.Buffer{}
+		srv, err := dispatcher.NewServer(ks, dispatcher.WithAuditLog(auditLog))
+		require.NoError(t, err)
+		client := startServer(t, srv)
+
+		ms := newMilestone(t, 1000)
+		ctx, req := signRequest(t, ms, pubKey1, pubKey2)
+		res, err := client.SignMilestone(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, res.GetSignatures(), 2)
+		require.True(t, ed25519.Verify(prvKey1.Public().(ed25519.PublicKey), req.GetMsEssence(), res.GetSignatures()[0]))
+		require.EqualValues(t, 1000, srv.LastSignedIndex())
+
+		var entries int
+		scanner := bufio.NewScanner(auditLog)
+		for scanner.Scan() {
+			entry := &dispatcher.AuditLogEntry{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), entry))
+			require.EqualValues(t, 1000, entry.MilestoneIndex)
+			entries++
+		}
+		require.Equal(t, 2, entries)
+
+		// the same milestone with a reduced set of public keys may be signed again
+		ms.PublicKeys = []iotago.MilestonePublicKey{pubKey2}
+		ctx, req = signRequest(t, ms, pubKey2)
+		_, err = client.SignMilestone(ctx, req)
+		require.NoError(t, err)
+
+		// but not with the same set again
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("err - same milestone with a disjoint set of public keys", func(t *testing.T) {
+		srv, err := dispatcher.NewServer(ks)
+		require.NoError(t, err)
+		client := startServer(t, srv)
+
+		ms := newMilestone(t, 1000)
+		ms.PublicKeys = []iotago.MilestonePublicKey{pubKey1}
+		ctx, req := signRequest(t, ms, pubKey1)
+		_, err = client.SignMilestone(ctx, req)
+		require.NoError(t, err)
+
+		ms.PublicKeys = []iotago.MilestonePublicKey{pubKey2}
+		ctx, req = signRequest(t, ms, pubKey2)
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("err - essence missing or invalid", func(t *testing.T) {
+		srv, err := dispatcher.NewServer(ks)
+		require.NoError(t, err)
+		client := startServer(t, srv)
+
+		_, req := signRequest(t, newMilestone(t, 1000), pubKey1)
+		_, err = client.SignMilestone(context.Background(), req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		garbage := tpkg.RandBytes(100)
+		garbageHash := blake2b.Sum256(garbage)
+		req.MsEssence = garbageHash[:]
+		ctx := metadata.AppendToOutgoingContext(context.Background(), remotesigner.MilestoneEssenceMetadataKey, string(garbage))
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		// the essence hash doesn't match the passed essence
+		ctx, req = signRequest(t, newMilestone(t, 1000), pubKey1)
+		req.MsEssence = garbageHash[:]
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.EqualValues(t, 0, srv.LastSignedIndex())
+	})
+
+	t.Run("err - unknown key", func(t *testing.T) {
+		srv, err := dispatcher.NewServer(dispatcher.NewInMemoryKeyStore(prvKey1))
+		require.NoError(t, err)
+		client := startServer(t, srv)
+
+		ctx, req := signRequest(t, newMilestone(t, 1000), pubKey1, pubKey2)
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("err - double signing across restarts", func(t *testing.T) {
+		stateFile := filepath.Join(t.TempDir(), "state.json")
+		srv, err := dispatcher.NewServer(ks, dispatcher.WithStateFile(stateFile))
+		require.NoError(t, err)
+		client := startServer(t, srv)
+
+		ctx, req := signRequest(t, newMilestone(t, 1000), pubKey1)
+		_, err = client.SignMilestone(ctx, req)
+		require.NoError(t, err)
+
+		srv, err = dispatcher.NewServer(ks, dispatcher.WithStateFile(stateFile))
+		require.NoError(t, err)
+		require.EqualValues(t, 1000, srv.LastSignedIndex())
+		client = startServer(t, srv)
+
+		// conflicting milestone with the same index
+		ctx, req = signRequest(t, newMilestone(t, 1000), pubKey1)
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		// lower index
+		ctx, req = signRequest(t, newMilestone(t, 999), pubKey1)
+		_, err = client.SignMilestone(ctx, req)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		ctx, req = signRequest(t, newMilestone(t, 1001), pubKey1)
+		_, err = client.SignMilestone(ctx, req)
+		require.NoError(t, err)
+	})
+}
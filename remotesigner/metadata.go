@@ -0,0 +1,6 @@
+package remotesigner
+
+// MilestoneEssenceMetadataKey is the gRPC metadata key under which a client passes the serialized milestone essence
+// along with a SignMilestoneRequest. The request itself only carries the hash of the essence, the metadata allows
+// a SignatureDispatcher to inspect the milestone before signing it.
+const MilestoneEssenceMetadataKey = "ms-essence-bin"
@@ -11,6 +11,9 @@ var (
 	// ErrTransactionBuilderUnsupportedAddress gets returned when an unsupported address type
 	// is given for a builder operation.
 	ErrTransactionBuilderUnsupportedAddress = errors.New("unsupported address type")
+	// ErrTransactionBuilderInputSelectionWithManualInputs gets returned when inputs were added manually
+	// to a builder which uses automatic input selection.
+	ErrTransactionBuilderInputSelectionWithManualInputs = errors.New("manually added inputs can not be combined with automatic input selection")
 )
 
 // NewTransactionBuilder creates a new TransactionBuilder.
@@ -21,15 +24,32 @@ func NewTransactionBuilder() *TransactionBuilder {
 			Outputs: serializer.Serializables{},
 			Payload: nil,
 		},
-		inputToAddr: map[UTXOInputID]Address{},
+		inputToAddr:   map[UTXOInputID]Address{},
+		inputToOutput: InputToOutputMapping{},
 	}
 }
 
+// transactionBuilderInputSelection holds the settings for automatic input selection.
+type transactionBuilderInputSelection struct {
+	selectionFunc InputSelectionFunc
+	changeAddr    Address
+}
+
 // TransactionBuilder is used to easily build up a Transaction.
 type TransactionBuilder struct {
 	occurredBuildErr error
 	essence          *TransactionEssence
 	inputToAddr      map[UTXOInputID]Address
+	inputToOutput    InputToOutputMapping
+	inputCandidates  InputCandidates
+	inputSelection   *transactionBuilderInputSelection
+	dustPlanning     *transactionBuilderDustPlanning
+}
+
+// transactionBuilderDustPlanning holds the settings for the dust planning.
+type transactionBuilderDustPlanning struct {
+	ctx     context.Context
+	planner *DustPlanner
 }
 
 // ToBeSignedUTXOInput defines a UTXO input which needs to be signed.
@@ -55,11 +75,12 @@ type TransactionBuilderInputFilter func(utxoInput *UTXOInput, input Output) bool
 // AddInputsViaNodeQuery adds any unspent outputs by the given address as an input to the built transaction
 // if it passes the filter function. It is the caller's job to ensure that the limit of returned outputs on the queried
 // node is enough high for the application's purpose. filter can be nil.
-func (b *TransactionBuilder) AddInputsViaNodeQuery(ctx context.Context, addr Address, nodeHTTPAPIClient *NodeHTTPAPIClient, filter TransactionBuilderInputFilter) *TransactionBuilder {
+func (b *TransactionBuilder) AddInputsViaNodeQuery(ctx context.Context, addr Address, nodeHTTPAPIClient NodeAPI, filter TransactionBuilderInputFilter) *TransactionBuilder {
 	switch x := addr.(type) {
 	case *Ed25519Address:
 	default:
 		b.occurredBuildErr = fmt.Errorf("%w: auto. inputs via node query only supports Ed25519Address but got %T", ErrTransactionBuilderUnsupportedAddress, x)
+		return b
 	}
 
 	_, unspentOutputs, err := nodeHTTPAPIClient.OutputsByEd25519Address(ctx, addr.(*Ed25519Address), false)
@@ -79,6 +100,58 @@ func (b *TransactionBuilder) AddInputsViaNodeQuery(ctx context.Context, addr Add
 	return b
 }
 
+// AddInputCandidate adds the given unspent output as a candidate for the automatic input selection.
+func (b *TransactionBuilder) AddInputCandidate(addr Address, utxoInput *UTXOInput, output Output) *TransactionBuilder {
+	candidate, err := NewInputCandidate(addr, utxoInput, output)
+	if err != nil {
+		b.occurredBuildErr = err
+		return b
+	}
+	b.inputCandidates = append(b.inputCandidates, candidate)
+	return b
+}
+
+// AddInputCandidatesViaNodeQuery adds any unspent outputs by the given address as candidates for the automatic input selection
+// if they pass the filter function. SigLockedDustAllowanceOutput(s) are never added as candidates, as consuming them
+// would lower the dust allowance of the address. filter can be nil.
+func (b *TransactionBuilder) AddInputCandidatesViaNodeQuery(ctx context.Context, addr Address, nodeHTTPAPIClient NodeAPI, filter TransactionBuilderInputFilter) *TransactionBuilder {
+	switch x := addr.(type) {
+	case *Ed25519Address:
+	default:
+		b.occurredBuildErr = fmt.Errorf("%w: input candidates via node query only supports Ed25519Address but got %T", ErrTransactionBuilderUnsupportedAddress, x)
+		return b
+	}
+
+	_, unspentOutputs, err := nodeHTTPAPIClient.OutputsByEd25519Address(ctx, addr.(*Ed25519Address), false)
+	if err != nil {
+		b.occurredBuildErr = err
+		return b
+	}
+
+	for utxoInput, output := range unspentOutputs {
+		if output.Type() == OutputSigLockedDustAllowanceOutput {
+			continue
+		}
+
+		if filter != nil && !filter(utxoInput, output) {
+			continue
+		}
+
+		b.AddInputCandidate(addr, utxoInput, output)
+	}
+
+	return b
+}
+
+// SelectInputs instructs the builder to automatically select inputs from the added input candidates
+// using the given InputSelectionFunc, so that the sum of the outputs is funded. Any remainder is sent back
+// to the given change address. The selection is executed when the transaction is built and can not be
+// combined with inputs added via AddInput.
+func (b *TransactionBuilder) SelectInputs(selectionFunc InputSelectionFunc, changeAddr Address) *TransactionBuilder {
+	b.inputSelection = &transactionBuilderInputSelection{selectionFunc: selectionFunc, changeAddr: changeAddr}
+	return b
+}
+
 // AddOutput adds the given output to the builder.
 func (b *TransactionBuilder) AddOutput(output Output) *TransactionBuilder {
 	b.essence.Outputs = append(b.essence.Outputs, output)
@@ -109,6 +182,14 @@ func (b *TransactionBuilder) BuildAndSwapToMessageBuilder(signer AddressSigner,
 	return msgBuilder.Payload(tx)
 }
 
+// PlanDust instructs the builder to check the transaction against the dust rules using the given DustPlanner
+// before it is signed. The planning is executed when the transaction is built (after the input selection).
+// Any change produced by the planner's fixes is sent to the change address given to SelectInputs.
+func (b *TransactionBuilder) PlanDust(ctx context.Context, planner *DustPlanner) *TransactionBuilder {
+	b.dustPlanning = &transactionBuilderDustPlanning{ctx: ctx, planner: planner}
+	return b
+}
+
 // Build sings the inputs with the given signer and returns the built payload.
 func (b *TransactionBuilder) Build(signer AddressSigner) (*Transaction, error) {
 
@@ -116,6 +197,26 @@ func (b *TransactionBuilder) Build(signer AddressSigner) (*Transaction, error) {
 		return nil, b.occurredBuildErr
 	}
 
+	var changeAddr Address
+	if b.inputSelection != nil {
+		changeAddr = b.inputSelection.changeAddr
+		if err := b.selectInputs(); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.dustPlanning != nil {
+		plan, err := b.dustPlanning.planner.Plan(b.dustPlanning.ctx, b.essence, b.inputToOutput, changeAddr)
+		if err != nil {
+			return nil, err
+		}
+		for _, input := range plan.AddedInputs {
+			b.inputToAddr[input.Input.ID()] = input.Address
+		}
+		// the planning must only happen once
+		b.dustPlanning = nil
+	}
+
 	// sort inputs and outputs by their serialized byte order
 	txEssenceData, err := b.essence.SigningMessage()
 	if err != nil {
@@ -152,3 +253,37 @@ func (b *TransactionBuilder) Build(signer AddressSigner) (*Transaction, error) {
 
 	return sigTxPayload, nil
 }
+
+// selects the inputs from the input candidates and adds a change output for any remainder.
+func (b *TransactionBuilder) selectInputs() error {
+	if len(b.essence.Inputs) > 0 {
+		return ErrTransactionBuilderInputSelectionWithManualInputs
+	}
+
+	var target uint64
+	for i, output := range b.essence.Outputs {
+		deposit, err := output.(Output).Deposit()
+		if err != nil {
+			return fmt.Errorf("unable to get deposit from output at index %d: %w", i, err)
+		}
+		target += deposit
+	}
+
+	selected, err := b.inputSelection.selectionFunc(b.inputCandidates, target, MaxInputsCount)
+	if err != nil {
+		return fmt.Errorf("unable to select inputs: %w", err)
+	}
+
+	for _, candidate := range selected {
+		b.AddInput(&ToBeSignedUTXOInput{Address: candidate.Address, Input: candidate.Input})
+		b.inputToOutput[candidate.Input.ID()] = candidate.Output
+	}
+
+	if remainder := selected.Sum() - target; remainder > 0 {
+		addAmountToAddress(b.essence, &SigLockedSingleOutput{Address: b.inputSelection.changeAddr, Amount: remainder})
+	}
+
+	// the selection must only happen once
+	b.inputSelection = nil
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/iotaledger/iota.go/v2"
 	"github.com/iotaledger/iota.go/v2/ed25519"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransactionBuilder(t *testing.T) {
@@ -120,3 +121,74 @@ func TestTransactionBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionBuilder_SelectInputs(t *testing.T) {
+	const mi = iotago.OutputSigLockedDustAllowanceOutputMinDeposit
+
+	identityOne := tpkg.RandEd25519PrivateKey()
+	inputAddr := iotago.AddressFromEd25519PubKey(identityOne.Public().(ed25519.PublicKey))
+	addrKeys := iotago.AddressKeys{Address: &inputAddr, Keys: identityOne}
+	outputAddr, _ := tpkg.RandEd25519Address()
+
+	newCandidateBuilder := func(deposits ...uint64) *iotago.TransactionBuilder {
+		builder := iotago.NewTransactionBuilder()
+		for i, deposit := range deposits {
+			utxoInput := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray(), TransactionOutputIndex: uint16(i)}
+			builder.AddInputCandidate(&inputAddr, utxoInput, &iotago.SigLockedSingleOutput{Address: &inputAddr, Amount: deposit})
+		}
+		return builder
+	}
+
+	t.Run("ok - with change", func(t *testing.T) {
+		tx, err := newCandidateBuilder(3*mi, 5*mi).
+			AddOutput(&iotago.SigLockedSingleOutput{Address: outputAddr, Amount: 2 * mi}).
+			SelectInputs(iotago.LargestFirstInputSelection, &inputAddr).
+			Build(iotago.NewInMemoryAddressSigner(addrKeys))
+		require.NoError(t, err)
+
+		essence := tx.Essence.(*iotago.TransactionEssence)
+		require.Len(t, essence.Inputs, 1)
+		require.Len(t, essence.Outputs, 2)
+		require.Len(t, tx.UnlockBlocks, 1)
+
+		outputSum, err := tx.SemanticallyValidateOutputs(essence)
+		require.NoError(t, err)
+		require.EqualValues(t, 5*mi, outputSum)
+	})
+
+	t.Run("ok - change merged into output to change address", func(t *testing.T) {
+		changeOutput := &iotago.SigLockedSingleOutput{Address: &inputAddr, Amount: 1 * mi}
+		tx, err := newCandidateBuilder(5*mi).
+			AddOutput(&iotago.SigLockedSingleOutput{Address: outputAddr, Amount: 2 * mi}).
+			AddOutput(changeOutput).
+			SelectInputs(iotago.LargestFirstInputSelection, &inputAddr).
+			Build(iotago.NewInMemoryAddressSigner(addrKeys))
+		require.NoError(t, err)
+
+		essence := tx.Essence.(*iotago.TransactionEssence)
+		require.Len(t, essence.Outputs, 2)
+
+		// the change is merged into the essence's output, not into the one passed to AddOutput
+		require.EqualValues(t, 1*mi, changeOutput.Amount)
+		outputSum, err := tx.SemanticallyValidateOutputs(essence)
+		require.NoError(t, err)
+		require.EqualValues(t, 5*mi, outputSum)
+	})
+
+	t.Run("err - insufficient funds", func(t *testing.T) {
+		_, err := newCandidateBuilder(1*mi).
+			AddOutput(&iotago.SigLockedSingleOutput{Address: outputAddr, Amount: 2 * mi}).
+			SelectInputs(iotago.LargestFirstInputSelection, &inputAddr).
+			Build(iotago.NewInMemoryAddressSigner(addrKeys))
+		require.True(t, errors.Is(err, iotago.ErrInsufficientFunds))
+	})
+
+	t.Run("err - combined with manual inputs", func(t *testing.T) {
+		_, err := newCandidateBuilder(5*mi).
+			AddInput(&iotago.ToBeSignedUTXOInput{Address: &inputAddr, Input: &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray()}}).
+			AddOutput(&iotago.SigLockedSingleOutput{Address: outputAddr, Amount: 2 * mi}).
+			SelectInputs(iotago.LargestFirstInputSelection, &inputAddr).
+			Build(iotago.NewInMemoryAddressSigner(addrKeys))
+		require.True(t, errors.Is(err, iotago.ErrTransactionBuilderInputSelectionWithManualInputs))
+	})
+}
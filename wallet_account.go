@@ -0,0 +1,288 @@
+package iotago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultAccountGapLimit is the default amount of consecutive unused addresses after which an Account stops scanning a chain.
+	DefaultAccountGapLimit = 20
+)
+
+var (
+	// ErrAccountGapLimitInvalid gets returned when an Account is configured with a gap limit of zero.
+	ErrAccountGapLimitInvalid = errors.New("account gap limit must be greater than zero")
+)
+
+// AccountOption is a function setting an Account option.
+type AccountOption func(opts *AccountOptions)
+
+// AccountOptions define options for the Account.
+type AccountOptions struct {
+	// The amount of consecutive unused addresses after which the scan of a chain stops.
+	gapLimit uint32
+}
+
+// applies the given AccountOption.
+func (ao *AccountOptions) apply(opts ...AccountOption) {
+	for _, opt := range opts {
+		opt(ao)
+	}
+}
+
+// WithAccountGapLimit defines the amount of consecutive unused addresses after which an Account stops
+// scanning the public or change chain for further used addresses.
+func WithAccountGapLimit(gapLimit uint32) AccountOption {
+	return func(opts *AccountOptions) {
+		opts.gapLimit = gapLimit
+	}
+}
+
+// the default options applied to the Account.
+var defaultAccountOptions = []AccountOption{
+	WithAccountGapLimit(DefaultAccountGapLimit),
+}
+
+// NewAccount creates a new Account which derives its addresses from the given HDKeychain
+// and queries their outputs via the given NodeHTTPAPIClient.
+func NewAccount(nodeHTTPAPIClient NodeAPI, keychain *HDKeychain, opts ...AccountOption) (*Account, error) {
+	options := &AccountOptions{}
+	options.apply(defaultAccountOptions...)
+	options.apply(opts...)
+
+	if options.gapLimit == 0 {
+		return nil, ErrAccountGapLimitInvalid
+	}
+
+	return &Account{nodeAPI: nodeHTTPAPIClient, keychain: keychain, opts: options}, nil
+}
+
+// Account scans the addresses of an HDKeychain for unspent outputs and caches them together with the derived balances.
+// Addresses are derived sequentially on the public and change chain until DefaultAccountGapLimit (or the configured gap limit)
+// consecutive addresses without any (spent or unspent) outputs are found.
+type Account struct {
+	mu       sync.RWMutex
+	nodeAPI  NodeAPI
+	keychain *HDKeychain
+	opts     *AccountOptions
+	// the state of the last sync
+	addrs       []*AccountAddress
+	ledgerIndex uint64
+}
+
+// AccountAddress is the state of an address of an Account as of its last sync.
+type AccountAddress struct {
+	// The address.
+	Address *Ed25519Address
+	// Whether the address is on the change chain.
+	Change bool
+	// The address index within its chain.
+	Index uint32
+	// Whether the address ever held outputs.
+	Used bool
+	// The unspent outputs residing on the address.
+	Outputs map[*UTXOInput]Output
+	// The sum of deposits of all unspent outputs on the address, including dust allowance outputs.
+	Balance uint64
+	// The sum of deposits of all SigLockedDustAllowanceOutput(s) on the address.
+	DustAllowanceBalance uint64
+	// The ledger index at which the outputs were queried.
+	LedgerIndex uint64
+}
+
+// Keychain returns the HDKeychain of the Account.
+func (a *Account) Keychain() *HDKeychain {
+	return a.keychain
+}
+
+// Sync scans the public and change chain of the Account and replaces the cached outputs and balances.
+// The cached state is left untouched if the scan fails.
+func (a *Account) Sync(ctx context.Context) error {
+	var addrs []*AccountAddress
+	var ledgerIndex uint64
+	for _, change := range []bool{false, true} {
+		chainAddrs, err := a.scanChain(ctx, change)
+		if err != nil {
+			return err
+		}
+		for _, addr := range chainAddrs {
+			if ledgerIndex == 0 || addr.LedgerIndex < ledgerIndex {
+				ledgerIndex = addr.LedgerIndex
+			}
+		}
+		addrs = append(addrs, chainAddrs...)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.addrs = addrs
+	a.ledgerIndex = ledgerIndex
+	return nil
+}
+
+// scans the given chain up to the gap limit. The trailing unused addresses are not part of the result.
+func (a *Account) scanChain(ctx context.Context, change bool) ([]*AccountAddress, error) {
+	var addrs []*AccountAddress
+	var lastUsed int
+	for index, gap := uint32(0), uint32(0); gap < a.opts.gapLimit; index++ {
+		addr, err := a.scanAddress(ctx, change, index)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+
+		if !addr.Used {
+			gap++
+			continue
+		}
+		gap = 0
+		lastUsed = len(addrs)
+	}
+	return addrs[:lastUsed], nil
+}
+
+// queries the state of the address with the given change and address index.
+func (a *Account) scanAddress(ctx context.Context, change bool, index uint32) (*AccountAddress, error) {
+	addr, err := a.keychain.Address(change, index)
+	if err != nil {
+		return nil, err
+	}
+
+	accAddr := &AccountAddress{Address: addr, Change: change, Index: index, Outputs: make(map[*UTXOInput]Output)}
+
+	res, err := a.nodeAPI.OutputIDsByEd25519Address(ctx, addr, true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query output IDs of address %s: %w", addr, err)
+	}
+	accAddr.LedgerIndex = res.LedgerIndex
+	if len(res.OutputIDs) == 0 {
+		return accAddr, nil
+	}
+	accAddr.Used = true
+
+	res, unspentOutputs, err := a.nodeAPI.OutputsByEd25519Address(ctx, addr, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query outputs of address %s: %w", addr, err)
+	}
+	accAddr.LedgerIndex = res.LedgerIndex
+
+	for utxoInput, output := range unspentOutputs {
+		deposit, err := output.Deposit()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get deposit of output %s: %w", utxoInput.ID().ToHex(), err)
+		}
+		accAddr.Outputs[utxoInput] = output
+		accAddr.Balance += deposit
+		if output.Type() == OutputSigLockedDustAllowanceOutput {
+			accAddr.DustAllowanceBalance += deposit
+		}
+	}
+	return accAddr, nil
+}
+
+// LedgerIndex returns the lowest ledger index at which the addresses were queried during the last sync.
+func (a *Account) LedgerIndex() uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.ledgerIndex
+}
+
+// Addresses returns the addresses found by the last sync, the public chain first, ordered by address index.
+func (a *Account) Addresses() []*AccountAddress {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	addrs := make([]*AccountAddress, len(a.addrs))
+	copy(addrs, a.addrs)
+	return addrs
+}
+
+// AddressState returns the state of the given address as of the last sync or nil if the address is not known.
+func (a *Account) AddressState(addr Address) *AccountAddress {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, accAddr := range a.addrs {
+		if accAddr.Address.String() == addr.String() {
+			return accAddr
+		}
+	}
+	return nil
+}
+
+// Balance returns the sum of deposits of all unspent outputs of the Account, including dust allowance outputs.
+func (a *Account) Balance() uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var sum uint64
+	for _, accAddr := range a.addrs {
+		sum += accAddr.Balance
+	}
+	return sum
+}
+
+// DustAllowanceBalance returns the sum of deposits of all SigLockedDustAllowanceOutput(s) of the Account.
+func (a *Account) DustAllowanceBalance() uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var sum uint64
+	for _, accAddr := range a.addrs {
+		sum += accAddr.DustAllowanceBalance
+	}
+	return sum
+}
+
+// UnspentOutputs returns all unspent outputs of the Account.
+func (a *Account) UnspentOutputs() InputToOutputMapping {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	outputs := make(InputToOutputMapping)
+	for _, accAddr := range a.addrs {
+		for utxoInput, output := range accAddr.Outputs {
+			outputs[utxoInput.ID()] = output
+		}
+	}
+	return outputs
+}
+
+// InputCandidates returns the unspent outputs of the Account as InputCandidates for an InputSelectionFunc,
+// ordered by address and output ID. SigLockedDustAllowanceOutputs are left out, as the builder does
+// for input candidates queried from a node.
+func (a *Account) InputCandidates() (InputCandidates, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var candidates InputCandidates
+	for _, accAddr := range a.addrs {
+		addrCandidates := make(InputCandidates, 0, len(accAddr.Outputs))
+		for utxoInput, output := range accAddr.Outputs {
+			if output.Type() == OutputSigLockedDustAllowanceOutput {
+				continue
+			}
+			candidate, err := NewInputCandidate(accAddr.Address, utxoInput, output)
+			if err != nil {
+				return nil, err
+			}
+			addrCandidates = append(addrCandidates, candidate)
+		}
+		sort.Slice(addrCandidates, func(i, j int) bool {
+			return addrCandidates[i].Input.ID().ToHex() < addrCandidates[j].Input.ID().ToHex()
+		})
+		candidates = append(candidates, addrCandidates...)
+	}
+	return candidates, nil
+}
+
+// NextUnusedAddress returns the first address on the given chain following the last used address as of the last sync.
+func (a *Account) NextUnusedAddress(change bool) (*Ed25519Address, error) {
+	a.mu.RLock()
+	var index uint32
+	for _, accAddr := range a.addrs {
+		if accAddr.Change == change && accAddr.Used {
+			index = accAddr.Index + 1
+		}
+	}
+	a.mu.RUnlock()
+	return a.keychain.Address(change, index)
+}
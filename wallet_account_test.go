@@ -0,0 +1,108 @@
+package iotago_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/iotaledger/iota.go/v2"
+)
+
+// mocks the outputs by address route including spent outputs to return the given amount of output IDs.
+func mockAddressOutputIDsIncludingSpent(addr *iotago.Ed25519Address, count int) {
+	outputIDs := make([]iotago.OutputIDHex, count)
+	for i := range outputIDs {
+		utxoInputID := randUTXOInput().ID()
+		outputIDs[i] = iotago.OutputIDHex(utxoInputID.ToHex())
+	}
+
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteAddressEd25519Outputs, addr.String())).
+		MatchParam("include-spent", "true").
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.AddressOutputsResponse{
+			AddressType: iotago.AddressEd25519,
+			Address:     addr.String(),
+			MaxResults:  1000,
+			Count:       uint32(count),
+			OutputIDs:   outputIDs,
+			LedgerIndex: 1337,
+		}})
+}
+
+func TestAccount_Sync(t *testing.T) {
+	defer gock.Off()
+
+	mnemonic, err := iotago.NewMnemonic(iotago.DefaultMnemonicEntropyBitSize)
+	require.NoError(t, err)
+	keychain, err := iotago.NewHDKeychainFromMnemonic(mnemonic, "", 0)
+	require.NoError(t, err)
+
+	addr := func(change bool, index uint32) *iotago.Ed25519Address {
+		a, err := keychain.Address(change, index)
+		require.NoError(t, err)
+		return a
+	}
+
+	// public chain: #0 only held spent outputs, #1 unused, #2 holds funds, #3 and #4 unused
+	mockAddressOutputIDsIncludingSpent(addr(false, 0), 1)
+	mockAddressOutputs(t, addr(false, 0), nil)
+	mockAddressOutputIDsIncludingSpent(addr(false, 1), 0)
+	mockAddressOutputIDsIncludingSpent(addr(false, 2), 3)
+	mockAddressOutputs(t, addr(false, 2), map[*iotago.UTXOInput]iotago.Output{
+		randUTXOInput(): &iotago.SigLockedSingleOutput{Address: addr(false, 2), Amount: 2_000_000},
+		randUTXOInput(): &iotago.SigLockedDustAllowanceOutput{Address: addr(false, 2), Amount: 1_000_000},
+	})
+	mockAddressOutputIDsIncludingSpent(addr(false, 3), 0)
+	mockAddressOutputIDsIncludingSpent(addr(false, 4), 0)
+
+	// change chain: #0 holds funds, #1 and #2 unused
+	mockAddressOutputIDsIncludingSpent(addr(true, 0), 1)
+	mockAddressOutputs(t, addr(true, 0), map[*iotago.UTXOInput]iotago.Output{
+		randUTXOInput(): &iotago.SigLockedSingleOutput{Address: addr(true, 0), Amount: 500},
+	})
+	mockAddressOutputIDsIncludingSpent(addr(true, 1), 0)
+	mockAddressOutputIDsIncludingSpent(addr(true, 2), 0)
+
+	account, err := iotago.NewAccount(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), keychain, iotago.WithAccountGapLimit(2))
+	require.NoError(t, err)
+	require.NoError(t, account.Sync(context.Background()))
+	require.True(t, gock.IsDone())
+
+	require.Len(t, account.Addresses(), 4)
+	require.EqualValues(t, 3_000_500, account.Balance())
+	require.EqualValues(t, 1_000_000, account.DustAllowanceBalance())
+	require.EqualValues(t, 1337, account.LedgerIndex())
+	require.Len(t, account.UnspentOutputs(), 3)
+
+	state := account.AddressState(addr(false, 2))
+	require.NotNil(t, state)
+	require.EqualValues(t, 3_000_000, state.Balance)
+	require.EqualValues(t, 1_000_000, state.DustAllowanceBalance)
+	require.Nil(t, account.AddressState(addr(false, 3)))
+
+	// dust allowance outputs are no input candidates
+	candidates, err := account.InputCandidates()
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	require.EqualValues(t, 2_000_500, candidates.Sum())
+	for _, candidate := range candidates {
+		require.NotEqual(t, iotago.OutputSigLockedDustAllowanceOutput, candidate.Output.Type())
+	}
+
+	nextAddr, err := account.NextUnusedAddress(false)
+	require.NoError(t, err)
+	require.Equal(t, addr(false, 3), nextAddr)
+	nextChangeAddr, err := account.NextUnusedAddress(true)
+	require.NoError(t, err)
+	require.Equal(t, addr(true, 1), nextChangeAddr)
+}
+
+func TestNewAccount_InvalidGapLimit(t *testing.T) {
+	_, err := iotago.NewAccount(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), nil, iotago.WithAccountGapLimit(0))
+	require.True(t, errors.Is(err, iotago.ErrAccountGapLimitInvalid))
+}
@@ -0,0 +1,417 @@
+package iotagox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+var (
+	// ErrMessageConflicting gets returned when a tracked message got referenced but its transaction conflicts with the ledger.
+	ErrMessageConflicting = errors.New("message is conflicting")
+	// ErrConfirmationTimeout gets returned when a tracked message did not get referenced within the configured timeout.
+	ErrConfirmationTimeout = errors.New("message did not get referenced in time")
+)
+
+// ConfirmationState is the final state of a message tracked by a ConfirmationTracker.
+type ConfirmationState int
+
+const (
+	// ConfirmationStateIncluded denotes that the message got referenced and its transaction got included in the ledger.
+	ConfirmationStateIncluded ConfirmationState = iota
+	// ConfirmationStateNoTransaction denotes that the message got referenced and does not contain a transaction.
+	ConfirmationStateNoTransaction
+	// ConfirmationStateConflicting denotes that the message got referenced but its transaction conflicts with the ledger.
+	ConfirmationStateConflicting
+	// ConfirmationStateTimeout denotes that the message did not get referenced within the configured timeout.
+	ConfirmationStateTimeout
+)
+
+func (s ConfirmationState) String() string {
+	switch s {
+	case ConfirmationStateIncluded:
+		return "included"
+	case ConfirmationStateNoTransaction:
+		return "no transaction"
+	case ConfirmationStateConflicting:
+		return "conflicting"
+	case ConfirmationStateTimeout:
+		return "timeout"
+	default:
+		return fmt.Sprintf("unknown confirmation state %d", int(s))
+	}
+}
+
+// ConflictError is the error of a message which got referenced but whose transaction conflicts with the ledger.
+// It matches ErrMessageConflicting and the sentinel error of its ConflictReason via errors.Is.
+type ConflictError struct {
+	// The ID of the conflicting message.
+	MessageID iotago.MessageID
+	// The conflict reason reported by the node.
+	Reason iotago.ConflictReason
+}
+
+func (e *ConflictError) Error() string {
+	reasonErr := e.Reason.Err()
+	if reasonErr == nil {
+		// i.e. the node reported the conflicting state without a reason
+		return fmt.Sprintf("%s: message %s, conflict reason %d (%s)", ErrMessageConflicting, iotago.MessageIDToHexString(e.MessageID), uint8(e.Reason), e.Reason)
+	}
+	return fmt.Sprintf("%s: message %s, %s", ErrMessageConflicting, iotago.MessageIDToHexString(e.MessageID), reasonErr)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrMessageConflicting
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Reason.Err()
+}
+
+// ConfirmationResult is the final result of tracking a message with a ConfirmationTracker.
+type ConfirmationResult struct {
+	// The final state.
+	State ConfirmationState
+	// The ID of the message which got referenced. This is the ID of a reattachment if one of them got referenced.
+	MessageID iotago.MessageID
+	// The metadata of the referenced message, nil on timeout.
+	Metadata *iotago.MessageMetadataResponse
+	// The IDs of the reattachments issued while tracking.
+	Reattachments iotago.MessageIDs
+	// The IDs of the promotion messages issued while tracking.
+	Promotions iotago.MessageIDs
+	// A *ConflictError on ConfirmationStateConflicting, an error wrapping ErrConfirmationTimeout on ConfirmationStateTimeout.
+	Err error
+}
+
+// ConfirmationTrackerOption is a function setting a ConfirmationTracker option.
+type ConfirmationTrackerOption func(opts *ConfirmationTrackerOptions)
+
+// ConfirmationTrackerOptions define options for the ConfirmationTracker.
+type ConfirmationTrackerOptions struct {
+	// The interval in which the metadata of tracked messages is polled.
+	pollInterval time.Duration
+	// The duration after which tracking ends with ConfirmationStateTimeout.
+	timeout time.Duration
+	// The event API client used to listen for metadata changes.
+	eventAPIClient *NodeEventAPIClient
+	// The PoW target score of promotions and reattachments, 0 to use the min. PoW score of the node.
+	targetScore float64
+	// The amount of workers used to do the PoW.
+	powWorkers int
+}
+
+// applies the given ConfirmationTrackerOption.
+func (cto *ConfirmationTrackerOptions) apply(opts ...ConfirmationTrackerOption) {
+	for _, opt := range opts {
+		opt(cto)
+	}
+}
+
+// WithConfirmationTrackerPollInterval defines the interval in which the metadata of tracked messages is polled.
+// Promotions and reattachments are only issued in this interval.
+func WithConfirmationTrackerPollInterval(interval time.Duration) ConfirmationTrackerOption {
+	return func(opts *ConfirmationTrackerOptions) {
+		opts.pollInterval = interval
+	}
+}
+
+// WithConfirmationTrackerTimeout defines the duration after which tracking a message ends with ConfirmationStateTimeout.
+func WithConfirmationTrackerTimeout(timeout time.Duration) ConfirmationTrackerOption {
+	return func(opts *ConfirmationTrackerOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// WithConfirmationTrackerEventAPIClient defines a connected NodeEventAPIClient over which metadata changes
+// of tracked messages are received in addition to polling them.
+func WithConfirmationTrackerEventAPIClient(eventAPIClient *NodeEventAPIClient) ConfirmationTrackerOption {
+	return func(opts *ConfirmationTrackerOptions) {
+		opts.eventAPIClient = eventAPIClient
+	}
+}
+
+// WithConfirmationTrackerPoW defines the target score and the amount of workers used to do the PoW of promotions
+// and reattachments. A target score of 0 uses the min. PoW score reported by the node.
+func WithConfirmationTrackerPoW(targetScore float64, workers int) ConfirmationTrackerOption {
+	return func(opts *ConfirmationTrackerOptions) {
+		opts.targetScore = targetScore
+		opts.powWorkers = workers
+	}
+}
+
+// the default options applied to the ConfirmationTracker.
+var defaultConfirmationTrackerOptions = []ConfirmationTrackerOption{
+	WithConfirmationTrackerPollInterval(5 * time.Second),
+	WithConfirmationTrackerTimeout(10 * time.Minute),
+	WithConfirmationTrackerPoW(0, 1),
+}
+
+// NewConfirmationTracker creates a new ConfirmationTracker using the given NodeHTTPAPIClient.
+func NewConfirmationTracker(nodeHTTPAPIClient iotago.NodeAPI, opts ...ConfirmationTrackerOption) *ConfirmationTracker {
+	options := &ConfirmationTrackerOptions{}
+	options.apply(defaultConfirmationTrackerOptions...)
+	options.apply(opts...)
+	return &ConfirmationTracker{nodeAPI: nodeHTTPAPIClient, opts: options}
+}
+
+// ConfirmationTracker tracks submitted messages until they are referenced by a milestone.
+// It promotes messages and reattaches their payload as advised by the node via MessageMetadataResponse.ShouldPromote
+// and MessageMetadataResponse.ShouldReattach.
+type ConfirmationTracker struct {
+	nodeAPI iotago.NodeAPI
+	opts    *ConfirmationTrackerOptions
+}
+
+// Track tracks the given submitted message and its reattachments until one of them gets referenced or the timeout is reached.
+// An error is only returned if the given context is done, errors while querying the node are retried
+// in the next poll interval and surface in ConfirmationResult.Err on timeout.
+func (ct *ConfirmationTracker) Track(ctx context.Context, msg *iotago.Message) (*ConfirmationResult, error) {
+	msgID, err := msg.ID()
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute message ID: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	t := &trackedMessage{
+		msg:             msg,
+		attachments:     iotago.MessageIDs{*msgID},
+		latest:          *msgID,
+		metadataChanges: make(chan *iotago.MessageMetadataResponse),
+	}
+	ct.listen(ctx, *msgID, t.metadataChanges)
+
+	timeout := time.NewTimer(ct.opts.timeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(ct.opts.pollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		if res := ct.poll(ctx, t, &lastErr); res != nil {
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			res := t.result(ConfirmationStateTimeout, t.latest, nil)
+			res.Err = ErrConfirmationTimeout
+			if lastErr != nil {
+				res.Err = fmt.Errorf("%w: last error: %s", ErrConfirmationTimeout, lastErr)
+			}
+			return res, nil
+		case metadata := <-t.metadataChanges:
+			if res := t.resolve(metadata); res != nil {
+				return res, nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// polls the metadata of all attachments and promotes or reattaches the latest one if advised.
+func (ct *ConfirmationTracker) poll(ctx context.Context, t *trackedMessage, lastErr *error) *ConfirmationResult {
+	var latestMetadata *iotago.MessageMetadataResponse
+	for _, attachment := range t.attachments {
+		metadata, err := ct.nodeAPI.MessageMetadataByMessageID(ctx, attachment)
+		if err != nil {
+			*lastErr = fmt.Errorf("unable to query metadata of message %s: %w", iotago.MessageIDToHexString(attachment), err)
+			continue
+		}
+		if res := t.resolve(metadata); res != nil {
+			return res
+		}
+		if attachment == t.latest {
+			latestMetadata = metadata
+		}
+	}
+
+	if latestMetadata == nil {
+		return nil
+	}
+
+	switch {
+	case latestMetadata.ShouldReattach != nil && *latestMetadata.ShouldReattach:
+		reattachmentID, err := ct.reattach(ctx, t.msg)
+		if err != nil {
+			*lastErr = err
+			return nil
+		}
+		t.attachments = append(t.attachments, reattachmentID)
+		t.reattachments = append(t.reattachments, reattachmentID)
+		t.latest = reattachmentID
+		ct.listen(ctx, reattachmentID, t.metadataChanges)
+	case latestMetadata.ShouldPromote != nil && *latestMetadata.ShouldPromote:
+		promotionID, err := ct.promote(ctx, t.msg.NetworkID, t.latest)
+		if err != nil {
+			*lastErr = err
+			return nil
+		}
+		t.promotions = append(t.promotions, promotionID)
+	}
+	return nil
+}
+
+// reattaches the payload of the given message with fresh tips.
+func (ct *ConfirmationTracker) reattach(ctx context.Context, msg *iotago.Message) (iotago.MessageID, error) {
+	targetScore, err := ct.targetScore(ctx)
+	if err != nil {
+		return iotago.MessageID{}, err
+	}
+
+	reattachment, err := iotago.NewMessageBuilder().
+		NetworkID(msg.NetworkID).
+		Payload(msg.Payload).
+		Tips(ctx, ct.nodeAPI).
+		ProofOfWork(ctx, targetScore, ct.opts.powWorkers).
+		Build()
+	if err != nil {
+		return iotago.MessageID{}, fmt.Errorf("unable to build reattachment: %w", err)
+	}
+	return ct.submit(ctx, reattachment)
+}
+
+// issues a message without payload referencing the given message and tips.
+func (ct *ConfirmationTracker) promote(ctx context.Context, networkID uint64, msgID iotago.MessageID) (iotago.MessageID, error) {
+	targetScore, err := ct.targetScore(ctx)
+	if err != nil {
+		return iotago.MessageID{}, err
+	}
+
+	tipsRes, err := ct.nodeAPI.Tips(ctx)
+	if err != nil {
+		return iotago.MessageID{}, fmt.Errorf("unable to fetch tips for promotion: %w", err)
+	}
+	tips, err := tipsRes.Tips()
+	if err != nil {
+		return iotago.MessageID{}, fmt.Errorf("unable to fetch tips for promotion: %w", err)
+	}
+	if len(tips) >= iotago.MaxParentsInAMessage {
+		tips = tips[:iotago.MaxParentsInAMessage-1]
+	}
+
+	promotion, err := iotago.NewMessageBuilder().
+		NetworkID(networkID).
+		ParentsMessageIDs(append(tips, msgID)).
+		ProofOfWork(ctx, targetScore, ct.opts.powWorkers).
+		Build()
+	if err != nil {
+		return iotago.MessageID{}, fmt.Errorf("unable to build promotion: %w", err)
+	}
+	return ct.submit(ctx, promotion)
+}
+
+// submits the given message and returns its ID.
+func (ct *ConfirmationTracker) submit(ctx context.Context, msg *iotago.Message) (iotago.MessageID, error) {
+	submitted, err := ct.nodeAPI.SubmitMessage(ctx, msg)
+	if err != nil {
+		return iotago.MessageID{}, fmt.Errorf("unable to submit message: %w", err)
+	}
+	msgID, err := submitted.ID()
+	if err != nil {
+		return iotago.MessageID{}, fmt.Errorf("unable to compute message ID: %w", err)
+	}
+	return *msgID, nil
+}
+
+// returns the configured target score or the min. PoW score of the node.
+func (ct *ConfirmationTracker) targetScore(ctx context.Context) (float64, error) {
+	if ct.opts.targetScore != 0 {
+		return ct.opts.targetScore, nil
+	}
+	info, err := ct.nodeAPI.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to query min. PoW score of node: %w", err)
+	}
+	return info.MinPowScore, nil
+}
+
+// forwards the metadata changes of the given message to the given channel, if an event API client is configured and active.
+func (ct *ConfirmationTracker) listen(ctx context.Context, msgID iotago.MessageID, metadataChanges chan<- *iotago.MessageMetadataResponse) {
+	neac := ct.opts.eventAPIClient
+	if neac == nil {
+		return
+	}
+
+	sub, err := neac.MessageMetadataChange(msgID)
+	if err != nil {
+		return
+	}
+	go func() {
+		defer func() { _ = sub.Unsubscribe() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case metadata, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case metadataChanges <- metadata:
+				}
+			}
+		}
+	}()
+}
+
+// the state of a message tracked by a ConfirmationTracker.
+type trackedMessage struct {
+	msg           *iotago.Message
+	attachments   iotago.MessageIDs
+	latest        iotago.MessageID
+	reattachments iotago.MessageIDs
+	promotions    iotago.MessageIDs
+	// receives metadata changes of all attachments
+	metadataChanges chan *iotago.MessageMetadataResponse
+}
+
+// returns the final result if the given metadata shows that the message got referenced.
+func (t *trackedMessage) resolve(metadata *iotago.MessageMetadataResponse) *ConfirmationResult {
+	if metadata == nil || metadata.ReferencedByMilestoneIndex == nil {
+		return nil
+	}
+
+	msgID, err := iotago.MessageIDFromHexString(metadata.MessageID)
+	if err != nil {
+		return nil
+	}
+
+	var inclusionState iotago.LedgerInclusionState
+	if metadata.LedgerInclusionState != nil {
+		inclusionState = *metadata.LedgerInclusionState
+	}
+
+	switch inclusionState {
+	case iotago.LedgerInclusionStateConflicting:
+		res := t.result(ConfirmationStateConflicting, msgID, metadata)
+		res.Err = &ConflictError{MessageID: msgID, Reason: metadata.ConflictReason}
+		return res
+	case iotago.LedgerInclusionStateNoTransaction:
+		return t.result(ConfirmationStateNoTransaction, msgID, metadata)
+	case iotago.LedgerInclusionStateIncluded:
+		return t.result(ConfirmationStateIncluded, msgID, metadata)
+	default:
+		// the inclusion state is not yet known
+		return nil
+	}
+}
+
+func (t *trackedMessage) result(state ConfirmationState, msgID iotago.MessageID, metadata *iotago.MessageMetadataResponse) *ConfirmationResult {
+	return &ConfirmationResult{
+		State:         state,
+		MessageID:     msgID,
+		Metadata:      metadata,
+		Reattachments: t.reattachments,
+		Promotions:    t.promotions,
+	}
+}
@@ -0,0 +1,166 @@
+package iotagox_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+	"github.com/iotaledger/iota.go/v2/x"
+)
+
+const nodeAPIUrl = "http://127.0.0.1:14265"
+
+// mocks the metadata route of the given message.
+func mockMessageMetadata(msgID iotago.MessageID, metadata *iotago.MessageMetadataResponse) {
+	metadata.MessageID = iotago.MessageIDToHexString(msgID)
+	gock.New(nodeAPIUrl).
+		Get(fmt.Sprintf(iotago.NodeAPIRouteMessageMetadata, iotago.MessageIDToHexString(msgID))).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: metadata})
+}
+
+// mocks the tips route.
+func mockTips() {
+	gock.New(nodeAPIUrl).
+		Get(iotago.NodeAPIRouteTips).
+		Reply(200).
+		JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.NodeTipsResponse{
+			TipsHex: []string{iotago.MessageIDToHexString(tpkg.Rand32ByteArray())},
+		}})
+}
+
+// mocks the submit message route to accept the next submitted message and calls onSubmit with it.
+func mockSubmitMessage(t *testing.T, onSubmit func(msgID iotago.MessageID, msg *iotago.Message)) {
+	res := gock.New(nodeAPIUrl).
+		Post(iotago.NodeAPIRouteMessages).
+		Reply(201)
+
+	res.Mock.Request().AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return false, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+		msg := &iotago.Message{}
+		_, err = msg.Deserialize(data, serializer.DeSeriModePerformValidation)
+		require.NoError(t, err)
+		msgID, err := msg.ID()
+		require.NoError(t, err)
+
+		res.SetHeader("Location", iotago.MessageIDToHexString(*msgID))
+		gock.New(nodeAPIUrl).
+			Get(fmt.Sprintf(iotago.NodeAPIRouteMessageBytes, iotago.MessageIDToHexString(*msgID))).
+			Reply(200).
+			Body(bytes.NewReader(data))
+		onSubmit(*msgID, msg)
+		return true, nil
+	})
+}
+
+func TestConfirmationTracker_Track(t *testing.T) {
+	yes, included, conflicting := true, iotago.LedgerInclusionStateIncluded, iotago.LedgerInclusionStateConflicting
+	referencedBy := uint32(1337)
+
+	newTracker := func(opts ...iotagox.ConfirmationTrackerOption) *iotagox.ConfirmationTracker {
+		return iotagox.NewConfirmationTracker(iotago.NewNodeHTTPAPIClient(nodeAPIUrl), append([]iotagox.ConfirmationTrackerOption{
+			iotagox.WithConfirmationTrackerPollInterval(time.Millisecond),
+			iotagox.WithConfirmationTrackerPoW(1, 1),
+		}, opts...)...)
+	}
+
+	newMessage := func(t *testing.T) (*iotago.Message, iotago.MessageID) {
+		msg, _ := tpkg.RandMessage(iotago.IndexationPayloadTypeID)
+		msgID, err := msg.ID()
+		require.NoError(t, err)
+		return msg, *msgID
+	}
+
+	t.Run("ok - included after promotion and reattachment", func(t *testing.T) {
+		defer gock.Off()
+
+		msg, msgID := newMessage(t)
+		mockMessageMetadata(msgID, &iotago.MessageMetadataResponse{ShouldPromote: &yes})
+		mockTips()
+		mockSubmitMessage(t, func(_ iotago.MessageID, promotion *iotago.Message) {
+			require.Nil(t, promotion.Payload)
+			require.Contains(t, promotion.Parents, msgID)
+		})
+
+		mockMessageMetadata(msgID, &iotago.MessageMetadataResponse{ShouldReattach: &yes})
+		mockTips()
+		var reattachmentID iotago.MessageID
+		mockSubmitMessage(t, func(id iotago.MessageID, reattachment *iotago.Message) {
+			require.Equal(t, msg.Payload, reattachment.Payload)
+			reattachmentID = id
+			mockMessageMetadata(msgID, &iotago.MessageMetadataResponse{ShouldReattach: &yes})
+			mockMessageMetadata(id, &iotago.MessageMetadataResponse{ReferencedByMilestoneIndex: &referencedBy, LedgerInclusionState: &included})
+		})
+
+		res, err := newTracker().Track(context.Background(), msg)
+		require.NoError(t, err)
+		require.Equal(t, iotagox.ConfirmationStateIncluded, res.State)
+		require.Equal(t, reattachmentID, res.MessageID)
+		require.Len(t, res.Promotions, 1)
+		require.Equal(t, iotago.MessageIDs{reattachmentID}, res.Reattachments)
+		require.NoError(t, res.Err)
+	})
+
+	t.Run("ok - conflicting", func(t *testing.T) {
+		defer gock.Off()
+
+		msg, msgID := newMessage(t)
+		mockMessageMetadata(msgID, &iotago.MessageMetadataResponse{ReferencedByMilestoneIndex: &referencedBy, LedgerInclusionState: &conflicting, ConflictReason: iotago.ConflictInvalidDustAllowance})
+
+		res, err := newTracker().Track(context.Background(), msg)
+		require.NoError(t, err)
+		require.Equal(t, iotagox.ConfirmationStateConflicting, res.State)
+		require.True(t, errors.Is(res.Err, iotagox.ErrMessageConflicting))
+		require.True(t, errors.Is(res.Err, iotago.ErrInvalidDustAllowance))
+
+		var conflictErr *iotagox.ConflictError
+		require.True(t, errors.As(res.Err, &conflictErr))
+		require.Equal(t, iotago.ConflictInvalidDustAllowance, conflictErr.Reason)
+	})
+
+	t.Run("ok - conflicting without reason", func(t *testing.T) {
+		defer gock.Off()
+
+		msg, msgID := newMessage(t)
+		mockMessageMetadata(msgID, &iotago.MessageMetadataResponse{ReferencedByMilestoneIndex: &referencedBy, LedgerInclusionState: &conflicting, ConflictReason: iotago.ConflictNone})
+
+		res, err := newTracker().Track(context.Background(), msg)
+		require.NoError(t, err)
+		require.Equal(t, iotagox.ConfirmationStateConflicting, res.State)
+		require.True(t, errors.Is(res.Err, iotagox.ErrMessageConflicting))
+		require.NotContains(t, res.Err.Error(), "%!")
+		require.Contains(t, res.Err.Error(), iotago.ConflictNone.String())
+	})
+
+	t.Run("ok - timeout", func(t *testing.T) {
+		defer gock.Off()
+
+		msg, msgID := newMessage(t)
+		gock.New(nodeAPIUrl).
+			Get(fmt.Sprintf(iotago.NodeAPIRouteMessageMetadata, iotago.MessageIDToHexString(msgID))).
+			Persist().
+			Reply(200).
+			JSON(&iotago.HTTPOkResponseEnvelope{Data: &iotago.MessageMetadataResponse{MessageID: iotago.MessageIDToHexString(msgID)}})
+
+		res, err := newTracker(iotagox.WithConfirmationTrackerTimeout(20*time.Millisecond)).Track(context.Background(), msg)
+		require.NoError(t, err)
+		require.Equal(t, iotagox.ConfirmationStateTimeout, res.State)
+		require.True(t, errors.Is(res.Err, iotagox.ErrConfirmationTimeout))
+	})
+}
@@ -7,8 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/finderAUT/hive.go/v2/serializer"
@@ -52,45 +55,247 @@ var (
 	ErrNodeEventAPIClientInactive = errors.New("node event api client is inactive")
 )
 
+const (
+	// DefaultNodeEventAPIClientReconnectInitialBackoff is the default backoff before the first reconnect attempt.
+	DefaultNodeEventAPIClientReconnectInitialBackoff = time.Second
+	// DefaultNodeEventAPIClientReconnectMaxBackoff is the default upper bound of the backoff between two reconnect attempts.
+	DefaultNodeEventAPIClientReconnectMaxBackoff = time.Minute
+)
+
+// NodeEventAPIClientState is the connection state of a NodeEventAPIClient.
+type NodeEventAPIClientState int
+
+const (
+	// NodeEventAPIClientStateDisconnected denotes that the client is not connected and won't try to reconnect.
+	NodeEventAPIClientStateDisconnected NodeEventAPIClientState = iota
+	// NodeEventAPIClientStateConnected denotes that the client is connected and its subscriptions are established.
+	NodeEventAPIClientStateConnected
+	// NodeEventAPIClientStateReconnecting denotes that the client lost its connection and tries to reconnect.
+	NodeEventAPIClientStateReconnecting
+)
+
+func (s NodeEventAPIClientState) String() string {
+	switch s {
+	case NodeEventAPIClientStateDisconnected:
+		return "disconnected"
+	case NodeEventAPIClientStateConnected:
+		return "connected"
+	case NodeEventAPIClientStateReconnecting:
+		return "reconnecting"
+	default:
+		return fmt.Sprintf("unknown state %d", int(s))
+	}
+}
+
+// NodeEventAPIClientStateHandler is called whenever the state of a NodeEventAPIClient changes.
+// err is the error which caused the change, if any. The handler must not block.
+type NodeEventAPIClientStateHandler func(state NodeEventAPIClientState, err error)
+
 func randMQTTClientID() string {
 	return strconv.FormatInt(rand.NewSource(time.Now().UnixNano()).Int63(), 10)
 }
 
-// NewNodeEventAPIClient creates a new NodeEventAPIClient using the given broker URI and default MQTT client options.
-func NewNodeEventAPIClient(brokerURI string) *NodeEventAPIClient {
-	clientOpts := mqtt.NewClientOptions()
-	clientOpts.Order = false
-	clientOpts.ClientID = randMQTTClientID()
-	clientOpts.AddBroker(brokerURI)
-	errChan := make(chan error)
-	clientOpts.OnConnectionLost = func(client mqtt.Client, err error) { sendErrOrDrop(errChan, err) }
-	return &NodeEventAPIClient{
-		MQTTClient: mqtt.NewClient(clientOpts),
-		Errors:     errChan,
+// NodeEventAPIClientOption is a function setting a NodeEventAPIClient option.
+type NodeEventAPIClientOption func(opts *NodeEventAPIClientOptions)
+
+// NodeEventAPIClientOptions define options for the NodeEventAPIClient.
+type NodeEventAPIClientOptions struct {
+	// The username and password used to authenticate against the broker.
+	username string
+	password string
+	// The provider of the token used as password to authenticate against the broker.
+	tokenProvider iotago.TokenProvider
+	// Additional headers sent along the websocket handshake.
+	headers http.Header
+	// The proxy through which websocket broker connections are established.
+	proxy func(req *http.Request) (*url.URL, error)
+	// Whether the client reconnects after losing its connection.
+	autoReconnect bool
+	// The policy defining the backoff between reconnect attempts.
+	reconnectPolicy *iotago.RetryPolicy
+	// The handler called on state changes.
+	stateHandler NodeEventAPIClientStateHandler
+}
+
+// applies the given NodeEventAPIClientOption.
+func (no *NodeEventAPIClientOptions) apply(opts ...NodeEventAPIClientOption) {
+	for _, opt := range opts {
+		opt(no)
+	}
+}
+
+// WithNodeEventAPIClientCredentials sets the username and password used to authenticate against the broker.
+func WithNodeEventAPIClientCredentials(username string, password string) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		opts.username = username
+		opts.password = password
+	}
+}
+
+// WithNodeEventAPIClientBearerToken sets a static token, i.e. a JWT, which is used as password to authenticate against the broker.
+func WithNodeEventAPIClientBearerToken(token string) NodeEventAPIClientOption {
+	return WithNodeEventAPIClientCredentials("", token)
+}
+
+// WithNodeEventAPIClientTokenProvider sets the TokenProvider whose token is used as password to authenticate against the broker.
+// The TokenProvider is called on every (re)connect and takes precedence over static credentials.
+func WithNodeEventAPIClientTokenProvider(tokenProvider iotago.TokenProvider) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		opts.tokenProvider = tokenProvider
+	}
+}
+
+// WithNodeEventAPIClientHeader adds the given header to the handshake of websocket broker connections.
+func WithNodeEventAPIClientHeader(key string, value string) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		if opts.headers == nil {
+			opts.headers = make(http.Header)
+		}
+		opts.headers.Add(key, value)
+	}
+}
+
+// WithNodeEventAPIClientProxy sets the function returning the proxy through which websocket broker connections are established.
+// Defaults to http.ProxyFromEnvironment.
+func WithNodeEventAPIClientProxy(proxy func(req *http.Request) (*url.URL, error)) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		opts.proxy = proxy
+	}
+}
+
+// WithNodeEventAPIClientAutoReconnect defines whether the client reconnects after losing its connection.
+// On reconnect, all topics previously registered on the client are subscribed to again.
+func WithNodeEventAPIClientAutoReconnect(enabled bool) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		opts.autoReconnect = enabled
+	}
+}
+
+// WithNodeEventAPIClientReconnectBackoff sets the backoff before the first reconnect attempt and its upper bound.
+// The backoff doubles with every failed attempt.
+func WithNodeEventAPIClientReconnectBackoff(initial time.Duration, max time.Duration) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		opts.reconnectPolicy = &iotago.RetryPolicy{
+			InitialBackoff: initial,
+			MaxBackoff:     max,
+			Multiplier:     iotago.DefaultRetryMultiplier,
+			Jitter:         iotago.DefaultRetryJitter,
+		}
 	}
 }
 
-// NodeEventAPIClient represents a handle to retrieve channels for node events.
-// Any registration will panic if the NodeEventAPIClient.Ctx is done or the client isn't connected.
-// Multiple calls to the same channel registration will override the previously created channel.
+// WithNodeEventAPIClientStateHandler sets the handler called whenever the client connects, loses its connection
+// or gives up reconnecting.
+func WithNodeEventAPIClientStateHandler(stateHandler NodeEventAPIClientStateHandler) NodeEventAPIClientOption {
+	return func(opts *NodeEventAPIClientOptions) {
+		opts.stateHandler = stateHandler
+	}
+}
+
+// the default options applied to the NodeEventAPIClient.
+var defaultNodeEventAPIClientOptions = []NodeEventAPIClientOption{
+	WithNodeEventAPIClientAutoReconnect(true),
+	WithNodeEventAPIClientReconnectBackoff(DefaultNodeEventAPIClientReconnectInitialBackoff, DefaultNodeEventAPIClientReconnectMaxBackoff),
+}
+
+// NewNodeEventAPIClient creates a new NodeEventAPIClient using an MQTTTransport to the given broker URI.
+// ws:// and wss:// URIs are connected to via MQTT over WebSocket.
+func NewNodeEventAPIClient(brokerURI string, opts ...NodeEventAPIClientOption) *NodeEventAPIClient {
+	transport := NewMQTTTransport(brokerURI, opts...)
+	neac := NewNodeEventAPIClientWithTransport(transport, opts...)
+	neac.MQTTClient = transport.Client()
+	return neac
+}
+
+// NewNodeEventAPIClientWithMQTTClient creates a new NodeEventAPIClient using the given MQTT client.
+// The client should not reconnect on its own and its connection lost handler must call
+// NodeEventAPIClient.OnConnectionLost for the NodeEventAPIClient to reconnect.
+// Options concerning the MQTT connection itself, i.e. credentials, have no effect.
+func NewNodeEventAPIClientWithMQTTClient(mqttClient mqtt.Client, opts ...NodeEventAPIClientOption) *NodeEventAPIClient {
+	neac := NewNodeEventAPIClientWithTransport(NewMQTTTransportWithClient(mqttClient), opts...)
+	neac.MQTTClient = mqttClient
+	return neac
+}
+
+// NewNodeEventAPIClientWithTransport creates a new NodeEventAPIClient receiving the events over the given NodeEventTransport.
+// Options concerning the connection itself, i.e. credentials, have no effect.
+func NewNodeEventAPIClientWithTransport(transport NodeEventTransport, opts ...NodeEventAPIClientOption) *NodeEventAPIClient {
+	options := &NodeEventAPIClientOptions{}
+	options.apply(defaultNodeEventAPIClientOptions...)
+	options.apply(opts...)
+
+	return &NodeEventAPIClient{Transport: transport, Errors: make(chan error), opts: options}
+}
+
+// NodeEventAPIClient represents a handle to register subscriptions for node events.
+// Any registration fails with ErrNodeEventAPIClientInactive if the NodeEventAPIClient.Ctx is done or the client isn't connected.
+// Every registration returns its own Subscription, multiple subscriptions for the same topic share the underlying broker subscription.
+// Each Subscription buffers its events as defined by the SubscriptionOption(s) passed to its registration.
+// The subscribed topics are remembered and subscribed to again whenever the client reconnects.
 type NodeEventAPIClient struct {
+	// The transport over which the events are received.
+	Transport NodeEventTransport
+	// The MQTT client used as transport if no Transport is set.
+	// It is set to the underlying MQTT client by NewNodeEventAPIClient and NewNodeEventAPIClientWithMQTTClient.
 	MQTTClient mqtt.Client
 	// The context over the EventChannelsHandle.
+	// It is set by Connect and must not be written concurrently to the client being used.
 	Ctx context.Context
 	// A channel up on which errors are returned from within subscriptions or when the connection is lost.
 	// It is the instantiater's job to ensure that the respective connection handlers are linked to this error channel
 	// if the client was created without NewNodeEventAPIClient.
 	// Errors are dropped silently if no receiver is listening for them or can consume them fast enough.
 	Errors chan error
+
+	// the options of the client, nil if the client was created without a constructor.
+	opts *NodeEventAPIClientOptions
+
+	mu     sync.Mutex
+	state  NodeEventAPIClientState
+	closed bool
+	topics map[string]*topicSubscriptions
+	// closed to stop the goroutine watching the context passed to the last Connect.
+	stopCtxWatch chan struct{}
+}
+
+// returns the context of the client.
+func (neac *NodeEventAPIClient) ctx() context.Context {
+	neac.mu.Lock()
+	defer neac.mu.Unlock()
+	return neac.Ctx
+}
+
+// stops the goroutine watching the context passed to the last Connect. The lock must be held.
+func (neac *NodeEventAPIClient) stopWatchingCtx() {
+	if neac.stopCtxWatch != nil {
+		close(neac.stopCtxWatch)
+		neac.stopCtxWatch = nil
+	}
 }
 
-func panicIfNodeEventAPIClientInactive(neac *NodeEventAPIClient) {
-	if err := neac.Ctx.Err(); err != nil {
-		panic(fmt.Errorf("%w: context is cancelled/done", ErrNodeEventAPIClientInactive))
+// returns an error wrapping ErrNodeEventAPIClientInactive if the client is not active.
+func (neac *NodeEventAPIClient) checkActive() error {
+	ctx := neac.ctx()
+	if ctx == nil {
+		return fmt.Errorf("%w: client was never connected", ErrNodeEventAPIClientInactive)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: context is cancelled/done", ErrNodeEventAPIClientInactive)
 	}
-	if !neac.MQTTClient.IsConnected() {
-		panic(fmt.Errorf("%w: client is not connected", ErrNodeEventAPIClientInactive))
+	if !neac.transport().IsConnected() {
+		return fmt.Errorf("%w: client is not connected", ErrNodeEventAPIClientInactive)
 	}
+	return nil
+}
+
+// returns the transport of the client, wrapping the MQTTClient if no Transport is set.
+func (neac *NodeEventAPIClient) transport() NodeEventTransport {
+	neac.mu.Lock()
+	defer neac.mu.Unlock()
+	if neac.Transport == nil && neac.MQTTClient != nil {
+		neac.Transport = NewMQTTTransportWithClient(neac.MQTTClient)
+	}
+	return neac.Transport
 }
 
 func sendErrOrDrop(errChan chan error, err error) {
@@ -100,221 +305,320 @@ func sendErrOrDrop(errChan chan error, err error) {
 	}
 }
 
-// Connect connects the NodeEventAPIClient to the specified brokers.
-// The NodeEventAPIClient remains active as long as the given context isn't done/cancelled.
+// Connect connects the NodeEventAPIClient via its transport.
+// The NodeEventAPIClient remains active as long as the given context isn't done/cancelled,
+// all subscriptions are closed once it is.
 func (neac *NodeEventAPIClient) Connect(ctx context.Context) error {
+	neac.mu.Lock()
 	neac.Ctx = ctx
-	if token := neac.MQTTClient.Connect(); token.Wait() && token.Error() != nil {
-		return token.Error()
+	neac.stopWatchingCtx()
+	neac.mu.Unlock()
+
+	if err := neac.transport().Connect(neac.onConnectionLost); err != nil {
+		return err
 	}
+
+	stop := make(chan struct{})
+	neac.mu.Lock()
+	neac.closed = false
+	neac.stopCtxWatch = stop
+	neac.mu.Unlock()
+	neac.setState(NodeEventAPIClientStateConnected, nil)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			neac.closeSubscriptions()
+		case <-stop:
+		}
+	}()
 	return nil
 }
 
-// Close disconnects the underlying MQTT client.
-// Call this function to clean up any registered channels.
+// Close disconnects the transport and closes all subscriptions.
 func (neac *NodeEventAPIClient) Close() {
-	neac.MQTTClient.Disconnect(0)
+	neac.mu.Lock()
+	neac.closed = true
+	neac.stopWatchingCtx()
+	neac.mu.Unlock()
+
+	neac.closeSubscriptions()
+	neac.transport().Disconnect()
+	neac.setState(NodeEventAPIClientStateDisconnected, nil)
 }
 
-// Messages returns a channel of newly received messages.
-func (neac *NodeEventAPIClient) Messages() <-chan *iotago.Message {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Message)
-	neac.MQTTClient.Subscribe(NodeEventMessages, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msg := &iotago.Message{}
-		if _, err := msg.Deserialize(mqttMsg.Payload(), serializer.DeSeriModePerformValidation); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
+// State returns the current connection state of the NodeEventAPIClient.
+func (neac *NodeEventAPIClient) State() NodeEventAPIClientState {
+	neac.mu.Lock()
+	defer neac.mu.Unlock()
+	return neac.state
+}
+
+// sets the state of the client and notifies the state handler if it changed.
+func (neac *NodeEventAPIClient) setState(state NodeEventAPIClientState, err error) {
+	neac.mu.Lock()
+	changed := neac.state != state
+	neac.state = state
+	neac.mu.Unlock()
+
+	if changed && neac.opts != nil && neac.opts.stateHandler != nil {
+		neac.opts.stateHandler(state, err)
+	}
+}
+
+// OnConnectionLost is the mqtt.ConnectionLostHandler of the NodeEventAPIClient.
+// It reports the error on the Errors channel and starts reconnecting if auto reconnect is enabled.
+func (neac *NodeEventAPIClient) OnConnectionLost(_ mqtt.Client, err error) {
+	neac.onConnectionLost(err)
+}
+
+// handles the loss of the connection of the transport.
+func (neac *NodeEventAPIClient) onConnectionLost(err error) {
+	sendErrOrDrop(neac.Errors, err)
+
+	neac.mu.Lock()
+	closed := neac.closed
+	neac.mu.Unlock()
+
+	ctx := neac.ctx()
+	if closed || neac.opts == nil || !neac.opts.autoReconnect || (ctx != nil && ctx.Err() != nil) {
+		neac.setState(NodeEventAPIClientStateDisconnected, err)
+		return
+	}
+
+	neac.setState(NodeEventAPIClientStateReconnecting, err)
+	go neac.reconnect()
+}
+
+// reconnects the client with backoff until it succeeds, the client is closed or its context is done.
+func (neac *NodeEventAPIClient) reconnect() {
+	ctx := neac.ctx()
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(neac.opts.reconnectPolicy.Backoff(attempt))
 		select {
-		case <-neac.Ctx.Done():
+		case <-done:
+			timer.Stop()
+			neac.setState(NodeEventAPIClientStateDisconnected, ctx.Err())
 			return
-		case channel <- msg:
+		case <-timer.C:
 		}
-	})
-	return channel
-}
-
-// ReferencedMessagesMetadata returns a channel of message metadata of newly referenced messages.
-func (neac *NodeEventAPIClient) ReferencedMessagesMetadata() <-chan *iotago.MessageMetadataResponse {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.MessageMetadataResponse)
-	neac.MQTTClient.Subscribe(NodeEventMessagesReferenced, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		metadataRes := &iotago.MessageMetadataResponse{}
-		if err := json.Unmarshal(mqttMsg.Payload(), metadataRes); err != nil {
-			sendErrOrDrop(neac.Errors, err)
+
+		neac.mu.Lock()
+		closed := neac.closed
+		neac.mu.Unlock()
+		if closed {
 			return
 		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- metadataRes:
+
+		if err := neac.transport().Connect(neac.onConnectionLost); err != nil {
+			sendErrOrDrop(neac.Errors, fmt.Errorf("unable to reconnect (attempt %d): %w", attempt, err))
+			continue
 		}
-	})
-	return channel
-}
-
-// ReferencedMessages returns a channel of newly referenced messages.
-func (neac *NodeEventAPIClient) ReferencedMessages(nodeHTTPAPIClient *iotago.NodeHTTPAPIClient) <-chan *iotago.Message {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Message)
-	neac.MQTTClient.Subscribe(NodeEventMessagesReferenced, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		metadataRes := &iotago.MessageMetadataResponse{}
-		if err := json.Unmarshal(mqttMsg.Payload(), metadataRes); err != nil {
-			sendErrOrDrop(neac.Errors, err)
+
+		// the client might have been closed while connecting
+		neac.mu.Lock()
+		closed = neac.closed
+		neac.mu.Unlock()
+		if closed {
+			neac.transport().Disconnect()
 			return
 		}
 
-		msg, err := nodeHTTPAPIClient.MessageByMessageID(context.Background(), iotago.MustMessageIDFromHexString(metadataRes.MessageID))
-		if err != nil {
-			return
+		neac.resubscribe()
+		neac.setState(NodeEventAPIClientStateConnected, nil)
+		return
+	}
+}
+
+// subscribes to all topics with subscriptions again.
+func (neac *NodeEventAPIClient) resubscribe() {
+	neac.mu.Lock()
+	topics := make([]string, 0, len(neac.topics))
+	for topic := range neac.topics {
+		topics = append(topics, topic)
+	}
+	neac.mu.Unlock()
+
+	for _, topic := range topics {
+		if err := neac.transport().Subscribe(topic, neac.dispatcher(topic)); err != nil {
+			sendErrOrDrop(neac.Errors, fmt.Errorf("unable to resubscribe to %s: %w", topic, err))
 		}
+	}
+}
 
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msg:
+// decodes a binary serialized message.
+func decodeMessage(payload []byte) (interface{}, error) {
+	msg := &iotago.Message{}
+	if _, err := msg.Deserialize(payload, serializer.DeSeriModePerformValidation); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// decodes a JSON encoded MessageMetadataResponse.
+func decodeMessageMetadata(payload []byte) (interface{}, error) {
+	metadataRes := &iotago.MessageMetadataResponse{}
+	if err := json.Unmarshal(payload, metadataRes); err != nil {
+		return nil, err
+	}
+	return metadataRes, nil
+}
+
+// decodes a JSON encoded NodeOutputResponse.
+func decodeOutput(payload []byte) (interface{}, error) {
+	res := &iotago.NodeOutputResponse{}
+	if err := json.Unmarshal(payload, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// decodes a JSON encoded Receipt.
+func decodeReceipt(payload []byte) (interface{}, error) {
+	receipt := &iotago.Receipt{}
+	if err := json.Unmarshal(payload, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// decodes a JSON encoded MilestonePointer.
+func decodeMilestonePointer(payload []byte) (interface{}, error) {
+	msPointer := &MilestonePointer{}
+	if err := json.Unmarshal(payload, msPointer); err != nil {
+		return nil, err
+	}
+	return msPointer, nil
+}
+
+// returns a decoder fetching the message of the milestone the decoded MilestonePointer points to.
+func (neac *NodeEventAPIClient) decodeMilestoneMessage(nodeHTTPAPIClient iotago.NodeAPI) eventDecoder {
+	return func(payload []byte) (interface{}, error) {
+		msPointer, err := decodeMilestonePointer(payload)
+		if err != nil {
+			return nil, err
 		}
-	})
-	return channel
-}
-
-// MessagesWithIndex returns a channel of newly received messages with the given index.
-func (neac *NodeEventAPIClient) MessagesWithIndex(index string) <-chan *iotago.Message {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Message)
-	neac.MQTTClient.Subscribe(strings.Replace(NodeEventMessagesIndexation, "{index}", index, 1), 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msg := &iotago.Message{}
-		if _, err := msg.Deserialize(mqttMsg.Payload(), serializer.DeSeriModePerformValidation); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
+		res, err := nodeHTTPAPIClient.MilestoneByIndex(neac.ctx(), msPointer.(*MilestonePointer).Index)
+		if err != nil {
+			return nil, err
 		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msg:
+		msgID, err := iotago.MessageIDFromHexString(res.MessageID)
+		if err != nil {
+			return nil, err
 		}
-	})
-	return channel
+		return nodeHTTPAPIClient.MessageByMessageID(neac.ctx(), msgID)
+	}
 }
 
-// MessageMetadataChange returns a channel of MessageMetadataResponse each time the given message's state changes.
-func (neac *NodeEventAPIClient) MessageMetadataChange(msgID iotago.MessageID) <-chan *iotago.MessageMetadataResponse {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.MessageMetadataResponse)
-	topic := strings.Replace(NodeEventMessagesMetadata, "{messageId}", iotago.MessageIDToHexString(msgID), 1)
-	neac.MQTTClient.Subscribe(topic, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		metadataRes := &iotago.MessageMetadataResponse{}
-		if err := json.Unmarshal(mqttMsg.Payload(), metadataRes); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
+// Messages returns a MessageSubscription for newly received messages.
+func (neac *NodeEventAPIClient) Messages(opts ...SubscriptionOption) (*MessageSubscription, error) {
+	sub, err := neac.register(NodeEventMessages, decodeMessage, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageSubscription(sub), nil
+}
+
+// ReferencedMessagesMetadata returns a MessageMetadataSubscription for the message metadata of newly referenced messages.
+func (neac *NodeEventAPIClient) ReferencedMessagesMetadata(opts ...SubscriptionOption) (*MessageMetadataSubscription, error) {
+	sub, err := neac.register(NodeEventMessagesReferenced, decodeMessageMetadata, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageMetadataSubscription(sub), nil
+}
+
+// ReferencedMessages returns a MessageSubscription for newly referenced messages.
+// The messages are fetched via the given NodeAPI.
+func (neac *NodeEventAPIClient) ReferencedMessages(nodeHTTPAPIClient iotago.NodeAPI, opts ...SubscriptionOption) (*MessageSubscription, error) {
+	sub, err := neac.register(NodeEventMessagesReferenced, func(payload []byte) (interface{}, error) {
+		metadataRes, err := decodeMessageMetadata(payload)
+		if err != nil {
+			return nil, err
 		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- metadataRes:
+		msgID, err := iotago.MessageIDFromHexString(metadataRes.(*iotago.MessageMetadataResponse).MessageID)
+		if err != nil {
+			return nil, err
 		}
-	})
-	return channel
+		return nodeHTTPAPIClient.MessageByMessageID(neac.ctx(), msgID)
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageSubscription(sub), nil
+}
+
+// MessagesWithIndex returns a MessageSubscription for newly received messages with the given index.
+func (neac *NodeEventAPIClient) MessagesWithIndex(index string, opts ...SubscriptionOption) (*MessageSubscription, error) {
+	sub, err := neac.register(strings.Replace(NodeEventMessagesIndexation, "{index}", index, 1), decodeMessage, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageSubscription(sub), nil
 }
 
-// AddressOutputs returns a channel of newly created or spent outputs on the given address.
-func (neac *NodeEventAPIClient) AddressOutputs(addr iotago.Address, netPrefix iotago.NetworkPrefix) <-chan *iotago.NodeOutputResponse {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.NodeOutputResponse)
+// MessageMetadataChange returns a MessageMetadataSubscription receiving the MessageMetadataResponse each time the given message's state changes.
+func (neac *NodeEventAPIClient) MessageMetadataChange(msgID iotago.MessageID, opts ...SubscriptionOption) (*MessageMetadataSubscription, error) {
+	topic := strings.Replace(NodeEventMessagesMetadata, "{messageId}", iotago.MessageIDToHexString(msgID), 1)
+	sub, err := neac.register(topic, decodeMessageMetadata, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageMetadataSubscription(sub), nil
+}
+
+// AddressOutputs returns an OutputSubscription for newly created or spent outputs on the given address.
+func (neac *NodeEventAPIClient) AddressOutputs(addr iotago.Address, netPrefix iotago.NetworkPrefix, opts ...SubscriptionOption) (*OutputSubscription, error) {
 	topic := strings.Replace(NodeEventAddressesOutput, "{address}", addr.Bech32(netPrefix), 1)
-	neac.MQTTClient.Subscribe(topic, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		res := &iotago.NodeOutputResponse{}
-		if err := json.Unmarshal(mqttMsg.Payload(), res); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- res:
-		}
-	})
-	return channel
+	sub, err := neac.register(topic, decodeOutput, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newOutputSubscription(sub), nil
 }
 
-// Ed25519AddressOutputs returns a channel of newly created or spent outputs on the given ed25519 address.
-func (neac *NodeEventAPIClient) Ed25519AddressOutputs(addr *iotago.Ed25519Address) <-chan *iotago.NodeOutputResponse {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.NodeOutputResponse)
+// Ed25519AddressOutputs returns an OutputSubscription for newly created or spent outputs on the given ed25519 address.
+func (neac *NodeEventAPIClient) Ed25519AddressOutputs(addr *iotago.Ed25519Address, opts ...SubscriptionOption) (*OutputSubscription, error) {
 	topic := strings.Replace(NodeEventAddressesEd25519Output, "{address}", addr.String(), 1)
-	neac.MQTTClient.Subscribe(topic, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		res := &iotago.NodeOutputResponse{}
-		if err := json.Unmarshal(mqttMsg.Payload(), res); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- res:
-		}
-	})
-	return channel
+	sub, err := neac.register(topic, decodeOutput, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newOutputSubscription(sub), nil
 }
 
-// TransactionIncludedMessage returns a channel of the included message which carries the transaction with the given ID.
-func (neac *NodeEventAPIClient) TransactionIncludedMessage(txID iotago.TransactionID) <-chan *iotago.Message {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Message)
+// TransactionIncludedMessage returns a MessageSubscription for the included message which carries the transaction with the given ID.
+func (neac *NodeEventAPIClient) TransactionIncludedMessage(txID iotago.TransactionID, opts ...SubscriptionOption) (*MessageSubscription, error) {
 	topic := strings.Replace(NodeEventTransactionsIncludedMessage, "{transactionId}", iotago.MessageIDToHexString(txID), 1)
-	neac.MQTTClient.Subscribe(topic, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msg := &iotago.Message{}
-		if _, err := msg.Deserialize(mqttMsg.Payload(), serializer.DeSeriModePerformValidation); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msg:
-		}
-	})
-	return channel
+	sub, err := neac.register(topic, decodeMessage, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageSubscription(sub), nil
 }
 
-// Output returns a channel which immediately returns the output with the given ID and afterwards when its state changes.
-func (neac *NodeEventAPIClient) Output(outputID iotago.UTXOInputID) <-chan *iotago.NodeOutputResponse {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.NodeOutputResponse)
+// Output returns an OutputSubscription which immediately receives the output with the given ID and afterwards when its state changes.
+func (neac *NodeEventAPIClient) Output(outputID iotago.UTXOInputID, opts ...SubscriptionOption) (*OutputSubscription, error) {
 	topic := strings.Replace(NodeEventOutputs, "{outputId}", hex.EncodeToString(outputID[:]), 1)
-	neac.MQTTClient.Subscribe(topic, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		res := &iotago.NodeOutputResponse{}
-		if err := json.Unmarshal(mqttMsg.Payload(), res); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- res:
-		}
-	})
-	return channel
-}
-
-// Receipts returns a channel which returns newly applied receipts.
-func (neac *NodeEventAPIClient) Receipts() <-chan *iotago.Receipt {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Receipt)
-	neac.MQTTClient.Subscribe(NodeEventReceipts, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		receipt := &iotago.Receipt{}
-		if err := json.Unmarshal(mqttMsg.Payload(), receipt); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- receipt:
-		}
-	})
-	return channel
+	sub, err := neac.register(topic, decodeOutput, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newOutputSubscription(sub), nil
+}
+
+// Receipts returns a ReceiptSubscription for newly applied receipts.
+func (neac *NodeEventAPIClient) Receipts(opts ...SubscriptionOption) (*ReceiptSubscription, error) {
+	sub, err := neac.register(NodeEventReceipts, decodeReceipt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newReceiptSubscription(sub), nil
 }
 
 // MilestonePointer is an informative struct holding a milestone index and timestamp.
@@ -323,98 +627,40 @@ type MilestonePointer struct {
 	Timestamp uint64 `json:"timestamp"`
 }
 
-// LatestMilestones returns a channel of newly seen latest milestones.
-func (neac *NodeEventAPIClient) LatestMilestones() <-chan *MilestonePointer {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *MilestonePointer)
-	neac.MQTTClient.Subscribe(NodeEventMilestonesLatest, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msPointer := &MilestonePointer{}
-		if err := json.Unmarshal(mqttMsg.Payload(), msPointer); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msPointer:
-		}
-	})
-	return channel
-}
-
-// LatestMilestoneMessages returns a channel of newly seen latest milestones messages.
-func (neac *NodeEventAPIClient) LatestMilestoneMessages(nodeHTTPAPIClient *iotago.NodeHTTPAPIClient) <-chan *iotago.Message {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Message)
-	neac.MQTTClient.Subscribe(NodeEventMilestonesLatest, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msPointer := &MilestonePointer{}
-		if err := json.Unmarshal(mqttMsg.Payload(), msPointer); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		res, err := nodeHTTPAPIClient.MilestoneByIndex(context.Background(), msPointer.Index)
-		if err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		msg, err := nodeHTTPAPIClient.MessageByMessageID(context.Background(), iotago.MustMessageIDFromHexString(res.MessageID))
-		if err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msg:
-		}
-	})
-	return channel
-}
-
-// ConfirmedMilestones returns a channel of newly confirmed milestones.
-func (neac *NodeEventAPIClient) ConfirmedMilestones() <-chan *MilestonePointer {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *MilestonePointer)
-	neac.MQTTClient.Subscribe(NodeEventMilestonesConfirmed, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msPointer := &MilestonePointer{}
-		if err := json.Unmarshal(mqttMsg.Payload(), msPointer); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msPointer:
-		}
-	})
-	return channel
-}
-
-// ConfirmedMilestoneMessages returns a channel of newly confirmed milestones messages.
-func (neac *NodeEventAPIClient) ConfirmedMilestoneMessages(nodeHTTPAPIClient *iotago.NodeHTTPAPIClient) <-chan *iotago.Message {
-	panicIfNodeEventAPIClientInactive(neac)
-	channel := make(chan *iotago.Message)
-	neac.MQTTClient.Subscribe(NodeEventMilestonesConfirmed, 2, func(client mqtt.Client, mqttMsg mqtt.Message) {
-		msPointer := &MilestonePointer{}
-		if err := json.Unmarshal(mqttMsg.Payload(), msPointer); err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		res, err := nodeHTTPAPIClient.MilestoneByIndex(context.Background(), msPointer.Index)
-		if err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		msg, err := nodeHTTPAPIClient.MessageByMessageID(context.Background(), iotago.MustMessageIDFromHexString(res.MessageID))
-		if err != nil {
-			sendErrOrDrop(neac.Errors, err)
-			return
-		}
-		select {
-		case <-neac.Ctx.Done():
-			return
-		case channel <- msg:
-		}
-	})
-	return channel
+// LatestMilestones returns a MilestonePointerSubscription for newly seen latest milestones.
+func (neac *NodeEventAPIClient) LatestMilestones(opts ...SubscriptionOption) (*MilestonePointerSubscription, error) {
+	sub, err := neac.register(NodeEventMilestonesLatest, decodeMilestonePointer, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMilestonePointerSubscription(sub), nil
+}
+
+// LatestMilestoneMessages returns a MessageSubscription for newly seen latest milestones messages.
+// The messages are fetched via the given NodeAPI.
+func (neac *NodeEventAPIClient) LatestMilestoneMessages(nodeHTTPAPIClient iotago.NodeAPI, opts ...SubscriptionOption) (*MessageSubscription, error) {
+	sub, err := neac.register(NodeEventMilestonesLatest, neac.decodeMilestoneMessage(nodeHTTPAPIClient), opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageSubscription(sub), nil
+}
+
+// ConfirmedMilestones returns a MilestonePointerSubscription for newly confirmed milestones.
+func (neac *NodeEventAPIClient) ConfirmedMilestones(opts ...SubscriptionOption) (*MilestonePointerSubscription, error) {
+	sub, err := neac.register(NodeEventMilestonesConfirmed, decodeMilestonePointer, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMilestonePointerSubscription(sub), nil
+}
+
+// ConfirmedMilestoneMessages returns a MessageSubscription for newly confirmed milestones messages.
+// The messages are fetched via the given NodeAPI.
+func (neac *NodeEventAPIClient) ConfirmedMilestoneMessages(nodeHTTPAPIClient iotago.NodeAPI, opts ...SubscriptionOption) (*MessageSubscription, error) {
+	sub, err := neac.register(NodeEventMilestonesConfirmed, neac.decodeMilestoneMessage(nodeHTTPAPIClient), opts)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageSubscription(sub), nil
 }
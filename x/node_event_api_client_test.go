@@ -2,9 +2,16 @@ package iotagox_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/finderAUT/hive.go/v2/serializer"
 	"github.com/iotaledger/iota.go/v2/tpkg"
 	"github.com/iotaledger/iota.go/v2/x"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -24,7 +31,9 @@ func TestNewNodeEventAPIClient(t *testing.T) {
 	}
 	require.NoError(t, eventAPIClient.Connect(ctx))
 
-	msgChan := eventAPIClient.Messages()
+	sub, err := eventAPIClient.Messages()
+	require.NoError(t, err)
+	msgChan := sub.C
 	require.Eventually(t, func() bool {
 		select {
 		case msg := <-msgChan:
@@ -96,8 +105,465 @@ func (m *mockMqttClient) SubscribeMultiple(filters map[string]byte, callback mqt
 	panic("implement me")
 }
 
-func (m *mockMqttClient) Unsubscribe(topics ...string) mqtt.Token { panic("implement me") }
+func (m *mockMqttClient) Unsubscribe(topics ...string) mqtt.Token { return &mockToken{} }
 
 func (m *mockMqttClient) AddRoute(topic string, callback mqtt.MessageHandler) { panic("implement me") }
 
 func (m *mockMqttClient) OptionsReader() mqtt.ClientOptionsReader { panic("implement me") }
+
+func TestNewNodeEventAPIClient_Credentials(t *testing.T) {
+	eventAPIClient := iotagox.NewNodeEventAPIClient("tcp://127.0.0.1:1883",
+		iotagox.WithNodeEventAPIClientBearerToken("static-token"),
+		iotagox.WithNodeEventAPIClientHeader("X-Custom", "value"),
+	)
+	opts := eventAPIClient.MQTTClient.OptionsReader()
+	require.Equal(t, "", opts.Username())
+	require.Equal(t, "static-token", opts.Password())
+	require.Equal(t, "value", opts.HTTPHeaders().Get("X-Custom"))
+
+	eventAPIClient = iotagox.NewNodeEventAPIClient("tcp://127.0.0.1:1883",
+		iotagox.WithNodeEventAPIClientCredentials("user", "pass"),
+	)
+	opts = eventAPIClient.MQTTClient.OptionsReader()
+	require.Equal(t, "user", opts.Username())
+	require.Equal(t, "pass", opts.Password())
+}
+
+type errToken struct {
+	mockToken
+	err error
+}
+
+func (t *errToken) Wait() bool { return true }
+
+func (t *errToken) Error() error { return t.err }
+
+// an MQTT client failing the given amount of connects and recording its subscriptions.
+type reconnectingMqttClient struct {
+	mockMqttClient
+	mu            sync.Mutex
+	failConnects  int
+	connects      int
+	subscriptions map[string]int
+	handlers      map[string]mqtt.MessageHandler
+	unsubscribed  []string
+}
+
+func (m *reconnectingMqttClient) Connect() mqtt.Token {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connects++
+	if m.connects > 1 && m.failConnects > 0 {
+		m.failConnects--
+		return &errToken{err: errors.New("connection refused")}
+	}
+	return &mockToken{}
+}
+
+func (m *reconnectingMqttClient) Disconnect(quiesce uint) {}
+
+func (m *reconnectingMqttClient) Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subscriptions == nil {
+		m.subscriptions = make(map[string]int)
+	}
+	m.subscriptions[topic]++
+	if m.handlers == nil {
+		m.handlers = make(map[string]mqtt.MessageHandler)
+	}
+	m.handlers[topic] = callback
+	return &mockToken{}
+}
+
+func (m *reconnectingMqttClient) Unsubscribe(topics ...string) mqtt.Token {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unsubscribed = append(m.unsubscribed, topics...)
+	for _, topic := range topics {
+		delete(m.handlers, topic)
+	}
+	return &mockToken{}
+}
+
+// publishes the given payload to the subscriber of the given topic.
+func (m *reconnectingMqttClient) publish(topic string, payload []byte) {
+	m.mu.Lock()
+	handler := m.handlers[topic]
+	m.mu.Unlock()
+	if handler != nil {
+		handler(m, &mockMsg{payload: payload})
+	}
+}
+
+func (m *reconnectingMqttClient) subscriptionCount(topic string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.subscriptions[topic]
+}
+
+func TestNodeEventAPIClient_Reconnect(t *testing.T) {
+	mock := &reconnectingMqttClient{failConnects: 2}
+
+	var statesMu sync.Mutex
+	var states []iotagox.NodeEventAPIClientState
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithMQTTClient(mock,
+		iotagox.WithNodeEventAPIClientReconnectBackoff(time.Millisecond, 5*time.Millisecond),
+		iotagox.WithNodeEventAPIClientStateHandler(func(state iotagox.NodeEventAPIClientState, err error) {
+			statesMu.Lock()
+			defer statesMu.Unlock()
+			states = append(states, state)
+		}),
+	)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	require.NoError(t, eventAPIClient.Connect(ctx))
+	require.Equal(t, iotagox.NodeEventAPIClientStateConnected, eventAPIClient.State())
+
+	_, err := eventAPIClient.Messages()
+	require.NoError(t, err)
+	_, err = eventAPIClient.ConfirmedMilestones()
+	require.NoError(t, err)
+	require.Equal(t, 1, mock.subscriptionCount(iotagox.NodeEventMessages))
+
+	eventAPIClient.OnConnectionLost(mock, errors.New("connection reset"))
+	require.Eventually(t, func() bool {
+		return eventAPIClient.State() == iotagox.NodeEventAPIClientStateConnected
+	}, 5*time.Second, time.Millisecond)
+
+	// the subscriptions are re-established after the failed attempts
+	require.Equal(t, 2, mock.subscriptionCount(iotagox.NodeEventMessages))
+	require.Equal(t, 2, mock.subscriptionCount(iotagox.NodeEventMilestonesConfirmed))
+	require.Equal(t, 4, mock.connects)
+
+	statesMu.Lock()
+	require.Equal(t, []iotagox.NodeEventAPIClientState{
+		iotagox.NodeEventAPIClientStateConnected,
+		iotagox.NodeEventAPIClientStateReconnecting,
+		iotagox.NodeEventAPIClientStateConnected,
+	}, states)
+	statesMu.Unlock()
+
+	// a closed client doesn't reconnect
+	eventAPIClient.Close()
+	eventAPIClient.OnConnectionLost(mock, errors.New("connection reset"))
+	require.Equal(t, iotagox.NodeEventAPIClientStateDisconnected, eventAPIClient.State())
+}
+
+func TestNodeEventAPIClient_ReconnectStopsWithContext(t *testing.T) {
+	mock := &reconnectingMqttClient{failConnects: math.MaxInt32}
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithMQTTClient(mock,
+		iotagox.WithNodeEventAPIClientReconnectBackoff(time.Millisecond, time.Millisecond),
+	)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	require.NoError(t, eventAPIClient.Connect(ctx))
+
+	eventAPIClient.OnConnectionLost(mock, errors.New("connection reset"))
+	require.Equal(t, iotagox.NodeEventAPIClientStateReconnecting, eventAPIClient.State())
+
+	cancelFunc()
+	require.Eventually(t, func() bool {
+		return eventAPIClient.State() == iotagox.NodeEventAPIClientStateDisconnected
+	}, 5*time.Second, time.Millisecond)
+}
+
+// a FakeNodeEventTransport whose reconnects block until released.
+type blockingReconnectTransport struct {
+	*iotagox.FakeNodeEventTransport
+	connects    int32
+	connecting  chan struct{}
+	release     chan struct{}
+	reconnected chan struct{}
+}
+
+func (b *blockingReconnectTransport) Connect(onConnectionLost func(err error)) error {
+	if atomic.AddInt32(&b.connects, 1) == 1 {
+		return b.FakeNodeEventTransport.Connect(onConnectionLost)
+	}
+	close(b.connecting)
+	<-b.release
+	defer close(b.reconnected)
+	return b.FakeNodeEventTransport.Connect(onConnectionLost)
+}
+
+func TestNodeEventAPIClient_CloseWhileReconnecting(t *testing.T) {
+	transport := &blockingReconnectTransport{
+		FakeNodeEventTransport: iotagox.NewFakeNodeEventTransport(),
+		connecting:             make(chan struct{}),
+		release:                make(chan struct{}),
+		reconnected:            make(chan struct{}),
+	}
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithTransport(transport,
+		iotagox.WithNodeEventAPIClientReconnectBackoff(time.Millisecond, time.Millisecond),
+	)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	require.NoError(t, eventAPIClient.Connect(ctx))
+	_, err := eventAPIClient.ConfirmedMilestones()
+	require.NoError(t, err)
+
+	transport.DropConnection(errors.New("connection reset"))
+	<-transport.connecting
+	eventAPIClient.Close()
+	close(transport.release)
+
+	// the connection established after the client was closed is torn down again
+	<-transport.reconnected
+	require.Eventually(t, func() bool {
+		return !transport.IsConnected()
+	}, 5*time.Second, time.Millisecond)
+	require.False(t, transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed))
+	require.Equal(t, iotagox.NodeEventAPIClientStateDisconnected, eventAPIClient.State())
+}
+
+// a FakeNodeEventTransport whose first subscribe blocks until released and then fails with the given error.
+type blockingSubscribeTransport struct {
+	*iotagox.FakeNodeEventTransport
+	subscribes  int32
+	subscribing chan struct{}
+	release     chan struct{}
+	err         error
+}
+
+func (b *blockingSubscribeTransport) Subscribe(topic string, handler iotagox.NodeEventHandler) error {
+	if atomic.AddInt32(&b.subscribes, 1) > 1 {
+		return b.FakeNodeEventTransport.Subscribe(topic, handler)
+	}
+	close(b.subscribing)
+	<-b.release
+	return b.err
+}
+
+func TestNodeEventAPIClient_ConcurrentRegistrations(t *testing.T) {
+	errSubscribe := errors.New("not authorized")
+	transport := &blockingSubscribeTransport{
+		FakeNodeEventTransport: iotagox.NewFakeNodeEventTransport(),
+		subscribing:            make(chan struct{}),
+		release:                make(chan struct{}),
+		err:                    errSubscribe,
+	}
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithTransport(transport)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	require.NoError(t, eventAPIClient.Connect(ctx))
+
+	register := func() <-chan error {
+		errs := make(chan error, 1)
+		go func() {
+			_, err := eventAPIClient.ConfirmedMilestones()
+			errs <- err
+		}()
+		return errs
+	}
+
+	first := register()
+	<-transport.subscribing
+	second := register()
+
+	// the second registration waits for the subscription of the first one
+	select {
+	case err := <-second:
+		require.Failf(t, "registration returned before the topic got subscribed to", "error: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// and shares its failure
+	close(transport.release)
+	require.True(t, errors.Is(<-first, errSubscribe))
+	require.True(t, errors.Is(<-second, errSubscribe))
+
+	// the failed topic isn't left behind, so the next registration subscribes again
+	_, err := eventAPIClient.ConfirmedMilestones()
+	require.NoError(t, err)
+	require.True(t, transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed))
+}
+
+func TestNodeEventAPIClient_RepeatedConnects(t *testing.T) {
+	transport := iotagox.NewFakeNodeEventTransport()
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithTransport(transport,
+		iotagox.WithNodeEventAPIClientReconnectBackoff(time.Millisecond, time.Millisecond),
+	)
+
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		require.NoError(t, eventAPIClient.Connect(context.Background()))
+		// reconnecting reads the context concurrently to the next Connect
+		transport.DropConnection(errors.New("connection reset"))
+		require.NoError(t, eventAPIClient.Connect(context.Background()))
+		eventAPIClient.Close()
+	}
+
+	// the goroutines watching the contexts don't pile up
+	require.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= goroutines+2
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestNodeEventAPIClient_Subscriptions(t *testing.T) {
+	mock := &reconnectingMqttClient{}
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithMQTTClient(mock)
+
+	// registrations on an inactive client fail
+	_, err := eventAPIClient.Messages()
+	require.True(t, errors.Is(err, iotagox.ErrNodeEventAPIClientInactive))
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	require.NoError(t, eventAPIClient.Connect(ctx))
+
+	// both subscriptions for the same topic receive the events
+	first, err := eventAPIClient.ConfirmedMilestones()
+	require.NoError(t, err)
+	second, err := eventAPIClient.ConfirmedMilestones()
+	require.NoError(t, err)
+	require.Equal(t, 1, mock.subscriptionCount(iotagox.NodeEventMilestonesConfirmed))
+
+	go mock.publish(iotagox.NodeEventMilestonesConfirmed, []byte(`{"index":1337,"timestamp":1}`))
+	require.EqualValues(t, 1337, (<-first.C).Index)
+	require.EqualValues(t, 1337, (<-second.C).Index)
+
+	// undecodable events are reported on the subscription
+	errs := first.Errors()
+	go func() {
+		// the error is dropped if it isn't received yet, so publish until it is
+		for i := 0; i < 100; i++ {
+			select {
+			case <-first.Done():
+				return
+			default:
+			}
+			mock.publish(iotagox.NodeEventMilestonesConfirmed, []byte(`{`))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	require.Contains(t, (<-errs).Error(), iotagox.NodeEventMilestonesConfirmed)
+
+	// the topic is only unsubscribed once the last subscription is gone
+	require.NoError(t, first.Unsubscribe())
+	_, open := <-first.C
+	require.False(t, open)
+	require.True(t, errors.Is(first.Unsubscribe(), iotagox.ErrSubscriptionClosed))
+	require.Equal(t, 1, mock.subscriptionCount(iotagox.NodeEventMilestonesConfirmed))
+
+	require.NoError(t, second.Unsubscribe())
+	mock.mu.Lock()
+	require.Equal(t, []string{iotagox.NodeEventMilestonesConfirmed}, mock.unsubscribed)
+	mock.mu.Unlock()
+
+	// closing the client closes all subscriptions
+	msgs, err := eventAPIClient.Messages()
+	require.NoError(t, err)
+	eventAPIClient.Close()
+	_, open = <-msgs.C
+	require.False(t, open)
+}
+
+func TestNodeEventAPIClient_SubscriptionBufferPolicies(t *testing.T) {
+	milestone := func(index int) []byte {
+		return []byte(fmt.Sprintf(`{"index":%d,"timestamp":1}`, index))
+	}
+
+	tests := []struct {
+		name        string
+		opts        []iotagox.SubscriptionOption
+		expected    []uint32
+		expectedErr error
+		stats       iotagox.SubscriptionStats
+		// whether publishing blocks until the events are consumed
+		block bool
+	}{
+		{
+			name: "ok - drop oldest",
+			opts: []iotagox.SubscriptionOption{
+				iotagox.WithSubscriptionBufferSize(2),
+				iotagox.WithSubscriptionBufferPolicy(iotagox.SubscriptionBufferPolicyDropOldest),
+			},
+			expected: []uint32{1, 3, 4},
+			stats:    iotagox.SubscriptionStats{Received: 4, Dropped: 1},
+		},
+		{
+			name: "ok - drop newest",
+			opts: []iotagox.SubscriptionOption{
+				iotagox.WithSubscriptionBufferSize(2),
+				iotagox.WithSubscriptionBufferPolicy(iotagox.SubscriptionBufferPolicyDropNewest),
+			},
+			expected: []uint32{1, 2, 3},
+			stats:    iotagox.SubscriptionStats{Received: 4, Dropped: 1},
+		},
+		{
+			name: "ok - spill to disk",
+			opts: []iotagox.SubscriptionOption{
+				iotagox.WithSubscriptionBufferSize(1),
+				iotagox.WithSubscriptionBufferPolicy(iotagox.SubscriptionBufferPolicySpillToDisk),
+			},
+			expected: []uint32{1, 2, 3, 4},
+			stats:    iotagox.SubscriptionStats{Received: 4, Spilled: 2},
+		},
+		{
+			name: "ok - block",
+			opts: []iotagox.SubscriptionOption{
+				iotagox.WithSubscriptionBufferSize(1),
+				iotagox.WithSubscriptionBufferPolicy(iotagox.SubscriptionBufferPolicyBlock),
+			},
+			expected: []uint32{1, 2, 3, 4},
+			stats:    iotagox.SubscriptionStats{Received: 4},
+			block:    true,
+		},
+		{
+			name:        "err - invalid buffer size",
+			opts:        []iotagox.SubscriptionOption{iotagox.WithSubscriptionBufferSize(0)},
+			expectedErr: iotagox.ErrSubscriptionBufferSizeInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spillDir := t.TempDir()
+			mock := &reconnectingMqttClient{}
+			eventAPIClient := iotagox.NewNodeEventAPIClientWithMQTTClient(mock)
+			ctx, cancelFunc := context.WithCancel(context.Background())
+			defer cancelFunc()
+			require.NoError(t, eventAPIClient.Connect(ctx))
+
+			sub, err := eventAPIClient.ConfirmedMilestones(append(test.opts, iotagox.WithSubscriptionSpillDir(spillDir))...)
+			if test.expectedErr != nil {
+				require.True(t, errors.Is(err, test.expectedErr))
+				return
+			}
+			require.NoError(t, err)
+
+			// wait for the first event to be taken out of the buffer so that the remaining ones fill it up
+			mock.publish(iotagox.NodeEventMilestonesConfirmed, milestone(1))
+			require.Eventually(t, func() bool { return sub.Stats().Pending == 0 }, time.Second, time.Millisecond)
+
+			published := make(chan struct{})
+			go func() {
+				defer close(published)
+				for i := 2; i <= 4; i++ {
+					mock.publish(iotagox.NodeEventMilestonesConfirmed, milestone(i))
+				}
+			}()
+
+			if !test.block {
+				<-published
+			}
+
+			var received []uint32
+			for len(received) < len(test.expected) {
+				received = append(received, (<-sub.C).Index)
+			}
+			<-published
+			require.Equal(t, test.expected, received)
+			require.Equal(t, test.stats, sub.Stats())
+
+			require.NoError(t, sub.Unsubscribe())
+			entries, err := os.ReadDir(spillDir)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package iotagox
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+var (
+	// ErrSubscriptionClosed gets returned when a Subscription is unsubscribed twice.
+	ErrSubscriptionClosed = errors.New("subscription is closed")
+)
+
+// decodes the payload of an event received on a topic.
+type eventDecoder func(payload []byte) (interface{}, error)
+
+// Subscription is a registration for the events of a topic on a NodeEventAPIClient.
+// The events are received on the typed channel of the subscription embedding it, which is closed once
+// the Subscription gets unsubscribed, the NodeEventAPIClient is closed or the context of the NodeEventAPIClient is done.
+// Received events are buffered per Subscription as defined by its SubscriptionOptions, so that a slow consumer
+// doesn't stall the delivery of events to other subscriptions.
+type Subscription struct {
+	neac   *NodeEventAPIClient
+	topic  string
+	decode eventDecoder
+	// the raw events to decode and forward onto the typed channel.
+	buffer *eventBuffer
+	errors chan error
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// creates a new Subscription for the given topic.
+func newSubscription(neac *NodeEventAPIClient, topic string, decode eventDecoder, opts *SubscriptionOptions) *Subscription {
+	return &Subscription{
+		neac:   neac,
+		topic:  topic,
+		decode: decode,
+		buffer: newEventBuffer(opts),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+}
+
+// Topic returns the topic of the Subscription.
+func (s *Subscription) Topic() string {
+	return s.topic
+}
+
+// Errors returns a channel up on which errors are returned which occurred while processing events of this Subscription,
+// i.e. events which could not be decoded or spilled to disk. Errors are dropped silently if no receiver is listening for them.
+func (s *Subscription) Errors() <-chan error {
+	return s.errors
+}
+
+// Stats returns the current event counters of the Subscription.
+func (s *Subscription) Stats() SubscriptionStats {
+	return s.buffer.statistics()
+}
+
+// Done returns a channel which is closed once the Subscription is closed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Unsubscribe removes the Subscription from its NodeEventAPIClient and closes its channel.
+// The topic is unsubscribed from the broker once no other Subscription for it remains.
+func (s *Subscription) Unsubscribe() error {
+	if !s.close() {
+		return fmt.Errorf("%w: topic %s", ErrSubscriptionClosed, s.topic)
+	}
+	return s.neac.unsubscribe(s)
+}
+
+// closes the Subscription and returns whether it was still open.
+func (s *Subscription) close() bool {
+	closed := false
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.buffer.close()
+		closed = true
+	})
+	return closed
+}
+
+// handles the payload of an event received on the topic of the Subscription.
+func (s *Subscription) handle(payload []byte) {
+	if err := s.buffer.push(payload); err != nil {
+		sendErrOrDrop(s.errors, fmt.Errorf("unable to buffer event on topic %s: %w", s.topic, err))
+	}
+}
+
+// decodes and forwards the buffered events of the Subscription using the given send function until the Subscription is closed.
+// send must return false if the Subscription got closed while sending. closeChan is called once the forwarding ends.
+func (s *Subscription) forward(send func(event interface{}) bool, closeChan func()) {
+	defer closeChan()
+	for {
+		payload, ok, err := s.buffer.pop()
+		if !ok {
+			return
+		}
+		if err != nil {
+			sendErrOrDrop(s.errors, fmt.Errorf("unable to read buffered event on topic %s: %w", s.topic, err))
+			continue
+		}
+
+		event, err := s.decode(payload)
+		if err != nil {
+			sendErrOrDrop(s.errors, fmt.Errorf("unable to process event on topic %s: %w", s.topic, err))
+			continue
+		}
+		if !send(event) {
+			return
+		}
+	}
+}
+
+// the subscriptions for a topic.
+type topicSubscriptions struct {
+	subs []*Subscription
+	// closed once the topic got subscribed to by the first registration.
+	subscribed chan struct{}
+	// the error of subscribing to the topic, only to be read once subscribed is closed.
+	err error
+}
+
+// returns the NodeEventHandler dispatching the events of the given topic to its subscriptions.
+func (neac *NodeEventAPIClient) dispatcher(topic string) NodeEventHandler {
+	return func(payload []byte) {
+		neac.mu.Lock()
+		var subs []*Subscription
+		if topicSubs, has := neac.topics[topic]; has {
+			subs = append(subs, topicSubs.subs...)
+		}
+		neac.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.handle(payload)
+		}
+	}
+}
+
+// registers a new Subscription for the given topic, subscribing to the topic if it's the first Subscription for it.
+func (neac *NodeEventAPIClient) register(topic string, decode eventDecoder, opts []SubscriptionOption) (*Subscription, error) {
+	if err := neac.checkActive(); err != nil {
+		return nil, err
+	}
+
+	subOpts := &SubscriptionOptions{}
+	subOpts.apply(defaultSubscriptionOptions...)
+	subOpts.apply(opts...)
+	if subOpts.bufferSize < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrSubscriptionBufferSizeInvalid, subOpts.bufferSize)
+	}
+
+	sub := newSubscription(neac, topic, decode, subOpts)
+
+	neac.mu.Lock()
+	if neac.topics == nil {
+		neac.topics = make(map[string]*topicSubscriptions)
+	}
+	topicSubs, has := neac.topics[topic]
+	if !has {
+		topicSubs = &topicSubscriptions{subscribed: make(chan struct{})}
+		neac.topics[topic] = topicSubs
+	}
+	topicSubs.subs = append(topicSubs.subs, sub)
+	neac.mu.Unlock()
+
+	if has {
+		// share the result of the first registration subscribing to the topic
+		<-topicSubs.subscribed
+		if topicSubs.err != nil {
+			sub.close()
+			return nil, topicSubs.err
+		}
+		return sub, nil
+	}
+
+	if err := neac.transport().Subscribe(topic, neac.dispatcher(topic)); err != nil {
+		// all registrations made meanwhile fail along with this one
+		neac.mu.Lock()
+		if neac.topics[topic] == topicSubs {
+			delete(neac.topics, topic)
+		}
+		neac.mu.Unlock()
+		topicSubs.err = fmt.Errorf("unable to subscribe to %s: %w", topic, err)
+		close(topicSubs.subscribed)
+		sub.close()
+		return nil, topicSubs.err
+	}
+	close(topicSubs.subscribed)
+	return sub, nil
+}
+
+// removes the given Subscription and returns whether it was the last one of its topic. The lock must be held.
+func (neac *NodeEventAPIClient) removeSubscription(sub *Subscription) bool {
+	topicSubs, has := neac.topics[sub.topic]
+	if !has {
+		return false
+	}
+	for i, other := range topicSubs.subs {
+		if other == sub {
+			topicSubs.subs = append(topicSubs.subs[:i], topicSubs.subs[i+1:]...)
+			break
+		}
+	}
+	if len(topicSubs.subs) > 0 {
+		return false
+	}
+	delete(neac.topics, sub.topic)
+	return true
+}
+
+// removes the given Subscription, unsubscribing from its topic if it was the last Subscription for it.
+func (neac *NodeEventAPIClient) unsubscribe(sub *Subscription) error {
+	neac.mu.Lock()
+	last := neac.removeSubscription(sub)
+	neac.mu.Unlock()
+
+	if !last || !neac.transport().IsConnected() {
+		return nil
+	}
+	if err := neac.transport().Unsubscribe(sub.topic); err != nil {
+		return fmt.Errorf("unable to unsubscribe from %s: %w", sub.topic, err)
+	}
+	return nil
+}
+
+// closes all subscriptions without unsubscribing from their topics.
+func (neac *NodeEventAPIClient) closeSubscriptions() {
+	neac.mu.Lock()
+	topics := neac.topics
+	neac.topics = nil
+	neac.mu.Unlock()
+
+	for _, topicSubs := range topics {
+		for _, sub := range topicSubs.subs {
+			sub.close()
+		}
+	}
+}
+
+// MessageSubscription is a Subscription for events carrying messages.
+type MessageSubscription struct {
+	*Subscription
+	// The channel up on which the messages are returned.
+	C <-chan *iotago.Message
+}
+
+// creates a new MessageSubscription forwarding the events of the given Subscription.
+func newMessageSubscription(sub *Subscription) *MessageSubscription {
+	c := make(chan *iotago.Message)
+	go sub.forward(func(event interface{}) bool {
+		select {
+		case <-sub.done:
+			return false
+		case c <- event.(*iotago.Message):
+			return true
+		}
+	}, func() { close(c) })
+	return &MessageSubscription{Subscription: sub, C: c}
+}
+
+// MessageMetadataSubscription is a Subscription for events carrying message metadata.
+type MessageMetadataSubscription struct {
+	*Subscription
+	// The channel up on which the message metadata is returned.
+	C <-chan *iotago.MessageMetadataResponse
+}
+
+// creates a new MessageMetadataSubscription forwarding the events of the given Subscription.
+func newMessageMetadataSubscription(sub *Subscription) *MessageMetadataSubscription {
+	c := make(chan *iotago.MessageMetadataResponse)
+	go sub.forward(func(event interface{}) bool {
+		select {
+		case <-sub.done:
+			return false
+		case c <- event.(*iotago.MessageMetadataResponse):
+			return true
+		}
+	}, func() { close(c) })
+	return &MessageMetadataSubscription{Subscription: sub, C: c}
+}
+
+// OutputSubscription is a Subscription for events carrying outputs.
+type OutputSubscription struct {
+	*Subscription
+	// The channel up on which the outputs are returned.
+	C <-chan *iotago.NodeOutputResponse
+}
+
+// creates a new OutputSubscription forwarding the events of the given Subscription.
+func newOutputSubscription(sub *Subscription) *OutputSubscription {
+	c := make(chan *iotago.NodeOutputResponse)
+	go sub.forward(func(event interface{}) bool {
+		select {
+		case <-sub.done:
+			return false
+		case c <- event.(*iotago.NodeOutputResponse):
+			return true
+		}
+	}, func() { close(c) })
+	return &OutputSubscription{Subscription: sub, C: c}
+}
+
+// ReceiptSubscription is a Subscription for events carrying receipts.
+type ReceiptSubscription struct {
+	*Subscription
+	// The channel up on which the receipts are returned.
+	C <-chan *iotago.Receipt
+}
+
+// creates a new ReceiptSubscription forwarding the events of the given Subscription.
+func newReceiptSubscription(sub *Subscription) *ReceiptSubscription {
+	c := make(chan *iotago.Receipt)
+	go sub.forward(func(event interface{}) bool {
+		select {
+		case <-sub.done:
+			return false
+		case c <- event.(*iotago.Receipt):
+			return true
+		}
+	}, func() { close(c) })
+	return &ReceiptSubscription{Subscription: sub, C: c}
+}
+
+// MilestonePointerSubscription is a Subscription for events carrying milestone pointers.
+type MilestonePointerSubscription struct {
+	*Subscription
+	// The channel up on which the milestone pointers are returned.
+	C <-chan *MilestonePointer
+}
+
+// creates a new MilestonePointerSubscription forwarding the events of the given Subscription.
+func newMilestonePointerSubscription(sub *Subscription) *MilestonePointerSubscription {
+	c := make(chan *MilestonePointer)
+	go sub.forward(func(event interface{}) bool {
+		select {
+		case <-sub.done:
+			return false
+		case c <- event.(*MilestonePointer):
+			return true
+		}
+	}, func() { close(c) })
+	return &MilestonePointerSubscription{Subscription: sub, C: c}
+}
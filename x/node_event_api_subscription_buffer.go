@@ -0,0 +1,316 @@
+package iotagox
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultSubscriptionBufferSize is the default amount of events buffered in memory per Subscription.
+	DefaultSubscriptionBufferSize = 100
+)
+
+var (
+	// ErrSubscriptionBufferSizeInvalid gets returned when a Subscription is registered with a buffer size smaller than one.
+	ErrSubscriptionBufferSizeInvalid = errors.New("subscription buffer size must be at least one")
+)
+
+// SubscriptionBufferPolicy defines what happens to an event received on a Subscription whose buffer is full.
+type SubscriptionBufferPolicy byte
+
+const (
+	// SubscriptionBufferPolicyBlock blocks the delivery of further events of the topic until the buffer has space again.
+	// Note that this stalls the delivery of events of other topics too.
+	SubscriptionBufferPolicyBlock SubscriptionBufferPolicy = iota
+	// SubscriptionBufferPolicyDropOldest drops the oldest buffered event in favor of the received one.
+	SubscriptionBufferPolicyDropOldest
+	// SubscriptionBufferPolicyDropNewest drops the received event.
+	SubscriptionBufferPolicyDropNewest
+	// SubscriptionBufferPolicySpillToDisk writes the received event to a temporary file from which it is read back
+	// once the events buffered in memory are consumed.
+	SubscriptionBufferPolicySpillToDisk
+)
+
+// String returns the name of the SubscriptionBufferPolicy.
+func (p SubscriptionBufferPolicy) String() string {
+	switch p {
+	case SubscriptionBufferPolicyBlock:
+		return "block"
+	case SubscriptionBufferPolicyDropOldest:
+		return "drop-oldest"
+	case SubscriptionBufferPolicyDropNewest:
+		return "drop-newest"
+	case SubscriptionBufferPolicySpillToDisk:
+		return "spill-to-disk"
+	default:
+		return fmt.Sprintf("unknown policy (%d)", p)
+	}
+}
+
+// SubscriptionOption is a function setting a SubscriptionOptions option.
+type SubscriptionOption func(opts *SubscriptionOptions)
+
+// SubscriptionOptions define options for a Subscription.
+type SubscriptionOptions struct {
+	// The amount of events buffered in memory.
+	bufferSize int
+	// What happens to events received while the buffer is full.
+	bufferPolicy SubscriptionBufferPolicy
+	// The directory in which the spill file is created, the default temporary directory if empty.
+	spillDir string
+}
+
+// the default options applied to a Subscription.
+var defaultSubscriptionOptions = []SubscriptionOption{
+	WithSubscriptionBufferSize(DefaultSubscriptionBufferSize),
+	WithSubscriptionBufferPolicy(SubscriptionBufferPolicyBlock),
+}
+
+// applies the given SubscriptionOption.
+func (so *SubscriptionOptions) apply(opts ...SubscriptionOption) {
+	for _, opt := range opts {
+		opt(so)
+	}
+}
+
+// WithSubscriptionBufferSize sets the amount of events buffered in memory.
+func WithSubscriptionBufferSize(size int) SubscriptionOption {
+	return func(opts *SubscriptionOptions) {
+		opts.bufferSize = size
+	}
+}
+
+// WithSubscriptionBufferPolicy sets what happens to events received while the buffer is full.
+func WithSubscriptionBufferPolicy(policy SubscriptionBufferPolicy) SubscriptionOption {
+	return func(opts *SubscriptionOptions) {
+		opts.bufferPolicy = policy
+	}
+}
+
+// WithSubscriptionSpillDir sets the directory in which the spill file of a Subscription
+// using SubscriptionBufferPolicySpillToDisk is created.
+func WithSubscriptionSpillDir(dir string) SubscriptionOption {
+	return func(opts *SubscriptionOptions) {
+		opts.spillDir = dir
+	}
+}
+
+// SubscriptionStats are the event counters of a Subscription.
+type SubscriptionStats struct {
+	// The amount of events received on the topic.
+	Received uint64
+	// The amount of events dropped because the buffer was full.
+	Dropped uint64
+	// The amount of events written to disk because the buffer was full.
+	Spilled uint64
+	// The amount of events currently buffered in memory and on disk.
+	Pending int
+}
+
+// buffers the raw payloads of the events of a Subscription according to its SubscriptionBufferPolicy.
+type eventBuffer struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	opts *SubscriptionOptions
+	// the payloads buffered in memory, oldest first.
+	mem [][]byte
+	// the payloads spilled to disk which are always newer than the ones in memory.
+	spill  *spillFile
+	closed bool
+	stats  SubscriptionStats
+}
+
+// creates a new eventBuffer.
+func newEventBuffer(opts *SubscriptionOptions) *eventBuffer {
+	b := &eventBuffer{opts: opts, mem: make([][]byte, 0, opts.bufferSize)}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// adds the given payload to the buffer, applying the SubscriptionBufferPolicy if the buffer is full.
+// returns an error if the payload couldn't be spilled to disk, in which case it is dropped.
+func (b *eventBuffer) push(payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.stats.Received++
+
+	switch {
+	case b.spill.len() > 0:
+		// keep the order as long as older payloads reside on disk
+		return b.spillLocked(payload)
+	case len(b.mem) < b.opts.bufferSize:
+	case b.opts.bufferPolicy == SubscriptionBufferPolicyDropOldest:
+		b.mem = append(b.mem[:0], b.mem[1:]...)
+		b.stats.Dropped++
+	case b.opts.bufferPolicy == SubscriptionBufferPolicyDropNewest:
+		b.stats.Dropped++
+		return nil
+	case b.opts.bufferPolicy == SubscriptionBufferPolicySpillToDisk:
+		return b.spillLocked(payload)
+	default:
+		for len(b.mem) >= b.opts.bufferSize && !b.closed {
+			b.cond.Wait()
+		}
+		if b.closed {
+			return nil
+		}
+	}
+
+	b.mem = append(b.mem, payload)
+	b.cond.Broadcast()
+	return nil
+}
+
+// writes the given payload to the spill file. The lock must be held.
+func (b *eventBuffer) spillLocked(payload []byte) error {
+	if b.spill == nil {
+		spill, err := newSpillFile(b.opts.spillDir)
+		if err != nil {
+			b.stats.Dropped++
+			return err
+		}
+		b.spill = spill
+	}
+	if err := b.spill.write(payload); err != nil {
+		b.stats.Dropped++
+		return err
+	}
+	b.stats.Spilled++
+	b.cond.Broadcast()
+	return nil
+}
+
+// returns the oldest buffered payload, blocking until one is available.
+// returns false if the buffer got closed.
+func (b *eventBuffer) pop() ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for len(b.mem) == 0 && b.spill.len() == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if b.closed {
+		return nil, false, nil
+	}
+
+	if len(b.mem) > 0 {
+		payload := b.mem[0]
+		b.mem[0] = nil
+		b.mem = b.mem[1:]
+		if len(b.mem) == 0 {
+			b.mem = make([][]byte, 0, b.opts.bufferSize)
+		}
+		b.cond.Broadcast()
+		return payload, true, nil
+	}
+
+	payload, err := b.spill.read()
+	if err != nil {
+		// the remaining spilled payloads can't be read back reliably
+		b.stats.Dropped += uint64(b.spill.len())
+		b.spill.reset()
+	}
+	return payload, true, err
+}
+
+// returns the current counters of the buffer.
+func (b *eventBuffer) statistics() SubscriptionStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := b.stats
+	stats.Pending = len(b.mem) + b.spill.len()
+	return stats
+}
+
+// closes the buffer, releasing waiting callers and removing the spill file.
+func (b *eventBuffer) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	b.mem = nil
+	if b.spill != nil {
+		_ = b.spill.remove()
+		b.spill = nil
+	}
+	b.cond.Broadcast()
+}
+
+// a temporary file holding length prefixed payloads.
+type spillFile struct {
+	file     *os.File
+	writeOff int64
+	readOff  int64
+	count    int
+}
+
+// creates a new spillFile in the given directory.
+func newSpillFile(dir string) (*spillFile, error) {
+	file, err := os.CreateTemp(dir, "iotago-subscription-*.spill")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create spill file: %w", err)
+	}
+	return &spillFile{file: file}, nil
+}
+
+// returns the amount of payloads in the spillFile, which is zero for a nil spillFile.
+func (s *spillFile) len() int {
+	if s == nil {
+		return 0
+	}
+	return s.count
+}
+
+// appends the given payload to the spillFile.
+func (s *spillFile) write(payload []byte) error {
+	buf := make([]byte, 4+len(payload))
+	binary.LittleEndian.PutUint32(buf, uint32(len(payload)))
+	copy(buf[4:], payload)
+	if _, err := s.file.WriteAt(buf, s.writeOff); err != nil {
+		return fmt.Errorf("unable to write to spill file: %w", err)
+	}
+	s.writeOff += int64(len(buf))
+	s.count++
+	return nil
+}
+
+// reads the oldest payload from the spillFile. The file is truncated once all payloads are read.
+func (s *spillFile) read() ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := s.file.ReadAt(lenBuf[:], s.readOff); err != nil {
+		return nil, fmt.Errorf("unable to read from spill file: %w", err)
+	}
+	payload := make([]byte, binary.LittleEndian.Uint32(lenBuf[:]))
+	if _, err := s.file.ReadAt(payload, s.readOff+4); err != nil {
+		return nil, fmt.Errorf("unable to read from spill file: %w", err)
+	}
+	s.readOff += int64(4 + len(payload))
+	s.count--
+	if s.count == 0 {
+		s.reset()
+	}
+	return payload, nil
+}
+
+// discards all payloads of the spillFile.
+func (s *spillFile) reset() {
+	_ = s.file.Truncate(0)
+	s.writeOff, s.readOff, s.count = 0, 0, 0
+}
+
+// closes and deletes the spillFile.
+func (s *spillFile) remove() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(s.file.Name())
+}
@@ -0,0 +1,173 @@
+package iotagox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+var (
+	// ErrNodeEventTransportSchemeUnsupported gets returned when a transport is created for a URI with an unsupported scheme.
+	ErrNodeEventTransportSchemeUnsupported = errors.New("unsupported transport scheme")
+)
+
+// NodeEventHandler handles the payload of an event published on a topic.
+type NodeEventHandler func(payload []byte)
+
+// NodeEventTransport delivers the events published on the topics of a node's event API,
+// i.e. NodeEventMilestonesLatest or NodeEventAddressesOutput with its placeholder replaced.
+// A NodeEventTransport doesn't reconnect on its own, the NodeEventAPIClient using it does so
+// once the transport reports a lost connection.
+type NodeEventTransport interface {
+	// Connect establishes the connection. onConnectionLost must be called once an established connection is lost.
+	Connect(onConnectionLost func(err error)) error
+	// Disconnect closes the connection.
+	Disconnect()
+	// IsConnected tells whether the connection is established.
+	IsConnected() bool
+	// Subscribe subscribes to the given topic, calling the handler for each of its events.
+	// Subscribing to a topic again replaces its handler.
+	Subscribe(topic string, handler NodeEventHandler) error
+	// Unsubscribe unsubscribes from the given topics.
+	Unsubscribe(topics ...string) error
+}
+
+// MQTTTransport is a NodeEventTransport receiving the events from an MQTT broker,
+// either over a plain TCP/TLS connection or over a WebSocket connection.
+type MQTTTransport struct {
+	client mqtt.Client
+	// the token provider whose token is fetched on every connect.
+	tokenProvider iotago.TokenProvider
+
+	mu               sync.Mutex
+	token            string
+	onConnectionLost func(err error)
+}
+
+// NewMQTTTransport creates a new MQTTTransport connecting to the given broker URI, i.e. tcp://host:1883 or ws://host/mqtt.
+// Options concerning reconnects or state handling have no effect.
+func NewMQTTTransport(brokerURI string, opts ...NodeEventAPIClientOption) *MQTTTransport {
+	options := &NodeEventAPIClientOptions{}
+	options.apply(defaultNodeEventAPIClientOptions...)
+	options.apply(opts...)
+
+	t := &MQTTTransport{tokenProvider: options.tokenProvider}
+
+	clientOpts := mqtt.NewClientOptions()
+	clientOpts.Order = false
+	clientOpts.ClientID = randMQTTClientID()
+	clientOpts.AddBroker(brokerURI)
+	// reconnects are done by the NodeEventAPIClient itself so that it can re-establish its subscriptions
+	clientOpts.SetAutoReconnect(false)
+	clientOpts.OnConnectionLost = t.OnConnectionLost
+	clientOpts.SetUsername(options.username)
+	clientOpts.SetPassword(options.password)
+	if options.tokenProvider != nil {
+		clientOpts.SetCredentialsProvider(func() (string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return options.username, t.token
+		})
+	}
+	if options.headers != nil {
+		clientOpts.SetHTTPHeaders(options.headers)
+	}
+	if options.proxy != nil {
+		clientOpts.SetWebsocketOptions(&mqtt.WebsocketOptions{Proxy: options.proxy})
+	}
+
+	t.client = mqtt.NewClient(clientOpts)
+	return t
+}
+
+// NewMQTTWebSocketTransport creates a new MQTTTransport speaking MQTT over a WebSocket connection to the given ws:// or wss:// URI.
+// The connection is established through the proxy set via WithNodeEventAPIClientProxy or else through the one
+// defined by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func NewMQTTWebSocketTransport(uri string, opts ...NodeEventAPIClientOption) (*MQTTTransport, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse websocket URI: %w", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("%w: %s, expected ws or wss", ErrNodeEventTransportSchemeUnsupported, u.Scheme)
+	}
+	return NewMQTTTransport(uri, opts...), nil
+}
+
+// NewMQTTTransportWithClient creates a new MQTTTransport using the given MQTT client.
+// The client should not reconnect on its own and its connection lost handler must call MQTTTransport.OnConnectionLost.
+func NewMQTTTransportWithClient(mqttClient mqtt.Client) *MQTTTransport {
+	return &MQTTTransport{client: mqttClient}
+}
+
+// Client returns the underlying MQTT client.
+func (t *MQTTTransport) Client() mqtt.Client {
+	return t.client
+}
+
+// OnConnectionLost is the mqtt.ConnectionLostHandler of the MQTTTransport.
+func (t *MQTTTransport) OnConnectionLost(_ mqtt.Client, err error) {
+	t.mu.Lock()
+	onConnectionLost := t.onConnectionLost
+	t.mu.Unlock()
+
+	if onConnectionLost != nil {
+		onConnectionLost(err)
+	}
+}
+
+// Connect connects to the broker, fetching a fresh token from the token provider beforehand if one is set.
+func (t *MQTTTransport) Connect(onConnectionLost func(err error)) error {
+	if t.tokenProvider != nil {
+		token, err := t.tokenProvider(context.Background())
+		if err != nil {
+			return fmt.Errorf("unable to get token from token provider: %w", err)
+		}
+		t.mu.Lock()
+		t.token = token
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	t.onConnectionLost = onConnectionLost
+	t.mu.Unlock()
+
+	if token := t.client.Connect(); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	return nil
+}
+
+// Disconnect disconnects from the broker.
+func (t *MQTTTransport) Disconnect() {
+	t.client.Disconnect(0)
+}
+
+// IsConnected tells whether the client is connected to the broker.
+func (t *MQTTTransport) IsConnected() bool {
+	return t.client.IsConnected()
+}
+
+// Subscribe subscribes to the given topic with QoS 2.
+func (t *MQTTTransport) Subscribe(topic string, handler NodeEventHandler) error {
+	if token := t.client.Subscribe(topic, 2, func(_ mqtt.Client, mqttMsg mqtt.Message) {
+		handler(mqttMsg.Payload())
+	}); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	return nil
+}
+
+// Unsubscribe unsubscribes from the given topics.
+func (t *MQTTTransport) Unsubscribe(topics ...string) error {
+	if token := t.client.Unsubscribe(topics...); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	return nil
+}
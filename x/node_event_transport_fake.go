@@ -0,0 +1,139 @@
+package iotagox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/finderAUT/hive.go/v2/serializer"
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+var (
+	// ErrFakeNodeEventTransportNotConnected gets returned when a FakeNodeEventTransport is used while not being connected.
+	ErrFakeNodeEventTransportNotConnected = errors.New("fake transport is not connected")
+)
+
+// FakeNodeEventTransport is an in-memory NodeEventTransport which delivers the events published on it
+// to its subscribers. It allows testing consumers of a NodeEventAPIClient without a broker.
+type FakeNodeEventTransport struct {
+	mu               sync.Mutex
+	connected        bool
+	connectErr       error
+	handlers         map[string]NodeEventHandler
+	onConnectionLost func(err error)
+}
+
+// NewFakeNodeEventTransport creates a new FakeNodeEventTransport.
+func NewFakeNodeEventTransport() *FakeNodeEventTransport {
+	return &FakeNodeEventTransport{handlers: make(map[string]NodeEventHandler)}
+}
+
+// Connect connects the FakeNodeEventTransport or returns the error set via SetConnectError.
+func (f *FakeNodeEventTransport) Connect(onConnectionLost func(err error)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.connectErr != nil {
+		return f.connectErr
+	}
+	f.connected = true
+	f.onConnectionLost = onConnectionLost
+	return nil
+}
+
+// Disconnect disconnects the FakeNodeEventTransport, dropping its subscriptions.
+func (f *FakeNodeEventTransport) Disconnect() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = false
+	f.handlers = make(map[string]NodeEventHandler)
+}
+
+// IsConnected tells whether the FakeNodeEventTransport is connected.
+func (f *FakeNodeEventTransport) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+// Subscribe subscribes to the given topic.
+func (f *FakeNodeEventTransport) Subscribe(topic string, handler NodeEventHandler) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected {
+		return fmt.Errorf("%w: unable to subscribe to %s", ErrFakeNodeEventTransportNotConnected, topic)
+	}
+	f.handlers[topic] = handler
+	return nil
+}
+
+// Unsubscribe unsubscribes from the given topics.
+func (f *FakeNodeEventTransport) Unsubscribe(topics ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, topic := range topics {
+		delete(f.handlers, topic)
+	}
+	return nil
+}
+
+// IsSubscribed tells whether the given topic is subscribed to.
+func (f *FakeNodeEventTransport) IsSubscribed(topic string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, has := f.handlers[topic]
+	return has
+}
+
+// SetConnectError sets the error returned by subsequent connects, nil lets them succeed again.
+func (f *FakeNodeEventTransport) SetConnectError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connectErr = err
+}
+
+// DropConnection simulates the loss of the connection with the given error.
+func (f *FakeNodeEventTransport) DropConnection(err error) {
+	f.mu.Lock()
+	f.connected = false
+	f.handlers = make(map[string]NodeEventHandler)
+	onConnectionLost := f.onConnectionLost
+	f.mu.Unlock()
+
+	if onConnectionLost != nil {
+		onConnectionLost(err)
+	}
+}
+
+// Publish delivers the given payload to the subscriber of the given topic and returns whether there was one.
+// Publish blocks as long as the subscriber does.
+func (f *FakeNodeEventTransport) Publish(topic string, payload []byte) bool {
+	f.mu.Lock()
+	handler, has := f.handlers[topic]
+	f.mu.Unlock()
+
+	if !has {
+		return false
+	}
+	handler(payload)
+	return true
+}
+
+// PublishJSON publishes the JSON encoding of the given value, as the node does for i.e. milestones, metadata and outputs.
+func (f *FakeNodeEventTransport) PublishJSON(topic string, value interface{}) (bool, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return f.Publish(topic, payload), nil
+}
+
+// PublishMessage publishes the binary serialized form of the given message, as the node does for messages.
+func (f *FakeNodeEventTransport) PublishMessage(topic string, msg *iotago.Message) (bool, error) {
+	payload, err := msg.Serialize(serializer.DeSeriModePerformValidation)
+	if err != nil {
+		return false, err
+	}
+	return f.Publish(topic, payload), nil
+}
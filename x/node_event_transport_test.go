@@ -0,0 +1,94 @@
+package iotagox_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+	"github.com/iotaledger/iota.go/v2/x"
+)
+
+func TestNewMQTTWebSocketTransport(t *testing.T) {
+	proxyURL, err := url.Parse("http://proxy:3128")
+	require.NoError(t, err)
+
+	transport, err := iotagox.NewMQTTWebSocketTransport("wss://127.0.0.1/api/plugins/mqtt/v1",
+		iotagox.WithNodeEventAPIClientProxy(http.ProxyURL(proxyURL)),
+		iotagox.WithNodeEventAPIClientHeader("X-Custom", "value"),
+	)
+	require.NoError(t, err)
+
+	opts := transport.Client().OptionsReader()
+	require.Equal(t, "wss", opts.Servers()[0].Scheme)
+	require.Equal(t, "value", opts.HTTPHeaders().Get("X-Custom"))
+	gottenProxyURL, err := opts.WebsocketOptions().Proxy(&http.Request{})
+	require.NoError(t, err)
+	require.Equal(t, proxyURL, gottenProxyURL)
+
+	_, err = iotagox.NewMQTTWebSocketTransport("tcp://127.0.0.1:1883")
+	require.True(t, errors.Is(err, iotagox.ErrNodeEventTransportSchemeUnsupported))
+}
+
+func TestMQTTTransport_TokenProviderError(t *testing.T) {
+	errProvider := errors.New("provider unavailable")
+	transport := iotagox.NewMQTTTransport("tcp://127.0.0.1:1883",
+		iotagox.WithNodeEventAPIClientTokenProvider(func(ctx context.Context) (string, error) {
+			return "", errProvider
+		}),
+	)
+	require.True(t, errors.Is(transport.Connect(nil), errProvider))
+}
+
+func TestFakeNodeEventTransport(t *testing.T) {
+	transport := iotagox.NewFakeNodeEventTransport()
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithTransport(transport,
+		iotagox.WithNodeEventAPIClientReconnectBackoff(time.Millisecond, time.Millisecond),
+	)
+
+	transport.SetConnectError(errors.New("connection refused"))
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	require.Error(t, eventAPIClient.Connect(ctx))
+
+	transport.SetConnectError(nil)
+	require.NoError(t, eventAPIClient.Connect(ctx))
+
+	milestones, err := eventAPIClient.ConfirmedMilestones()
+	require.NoError(t, err)
+	msgs, err := eventAPIClient.Messages()
+	require.NoError(t, err)
+	require.True(t, transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed))
+
+	published, err := transport.PublishJSON(iotagox.NodeEventMilestonesConfirmed, &iotagox.MilestonePointer{Index: 1337, Timestamp: 1})
+	require.NoError(t, err)
+	require.True(t, published)
+	require.EqualValues(t, 1337, (<-milestones.C).Index)
+
+	msg, _ := tpkg.RandMessage(iotago.IndexationPayloadTypeID)
+	published, err = transport.PublishMessage(iotagox.NodeEventMessages, msg)
+	require.NoError(t, err)
+	require.True(t, published)
+	require.Equal(t, msg.Nonce, (<-msgs.C).Nonce)
+
+	// the topics are subscribed to again once the client reconnected
+	transport.DropConnection(errors.New("connection reset"))
+	require.False(t, transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed))
+	require.Eventually(t, func() bool {
+		return eventAPIClient.State() == iotagox.NodeEventAPIClientStateConnected
+	}, time.Second, time.Millisecond)
+	require.True(t, transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed))
+	require.True(t, transport.IsSubscribed(iotagox.NodeEventMessages))
+
+	require.NoError(t, milestones.Unsubscribe())
+	require.False(t, transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed))
+
+	eventAPIClient.Close()
+	require.False(t, transport.IsConnected())
+}
@@ -0,0 +1,314 @@
+package iotagox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+)
+
+var (
+	// ErrReliableConfirmedStreamMilestonePruned gets returned when a milestone which has yet to be processed
+	// was already pruned by the node, so that the stream can't continue without a gap.
+	ErrReliableConfirmedStreamMilestonePruned = errors.New("milestone to process is pruned")
+)
+
+// LedgerDiffOutput is an output created or consumed by a milestone.
+type LedgerDiffOutput struct {
+	// The ID of the output.
+	ID iotago.UTXOInputID
+	// The output itself.
+	Output iotago.Output
+}
+
+// MilestoneLedgerDiff holds the outputs created and consumed by the messages a milestone confirmed.
+type MilestoneLedgerDiff struct {
+	// The index of the milestone.
+	Index uint32
+	// The unix time of the milestone payload.
+	Timestamp int64
+	// The ID of the message holding the milestone.
+	MessageID iotago.MessageID
+	// The outputs created by the milestone, in the order reported by the node.
+	Created []*LedgerDiffOutput
+	// The outputs consumed by the milestone, in the order reported by the node.
+	Consumed []*LedgerDiffOutput
+}
+
+// ReliableConfirmedStreamOption is a function setting a ReliableConfirmedStream option.
+type ReliableConfirmedStreamOption func(opts *ReliableConfirmedStreamOptions)
+
+// ReliableConfirmedStreamOptions define options for the ReliableConfirmedStream.
+type ReliableConfirmedStreamOptions struct {
+	// The interval in which the node is polled for its confirmed milestone index.
+	pollInterval time.Duration
+	// The event API client used to get notified about confirmed milestones.
+	eventAPIClient *NodeEventAPIClient
+	// The index of the last milestone the consumer processed, 0 to start with the currently confirmed milestone.
+	lastProcessedIndex uint32
+	// The policy defining the backoff between failed attempts to catch up.
+	retryPolicy *iotago.RetryPolicy
+	// Called with every error which made an attempt to catch up fail.
+	onError func(err error)
+}
+
+// applies the given ReliableConfirmedStreamOption.
+func (rcso *ReliableConfirmedStreamOptions) apply(opts ...ReliableConfirmedStreamOption) {
+	for _, opt := range opts {
+		opt(rcso)
+	}
+}
+
+// WithReliableConfirmedStreamPollInterval defines the interval in which the node is polled for its confirmed milestone index.
+// Polling catches up on milestones whose events were missed, i.e. while the NodeEventAPIClient was disconnected.
+func WithReliableConfirmedStreamPollInterval(interval time.Duration) ReliableConfirmedStreamOption {
+	return func(opts *ReliableConfirmedStreamOptions) {
+		opts.pollInterval = interval
+	}
+}
+
+// WithReliableConfirmedStreamEventAPIClient defines a connected NodeEventAPIClient over which the stream gets
+// notified about confirmed milestones in addition to polling for them.
+func WithReliableConfirmedStreamEventAPIClient(eventAPIClient *NodeEventAPIClient) ReliableConfirmedStreamOption {
+	return func(opts *ReliableConfirmedStreamOptions) {
+		opts.eventAPIClient = eventAPIClient
+	}
+}
+
+// WithReliableConfirmedStreamLastProcessedIndex defines the index of the last milestone the consumer processed,
+// i.e. the one persisted from ReliableConfirmedStream.LastProcessedIndex before a restart.
+// The stream continues with the milestone following it. With 0, the stream starts with the currently confirmed milestone.
+func WithReliableConfirmedStreamLastProcessedIndex(index uint32) ReliableConfirmedStreamOption {
+	return func(opts *ReliableConfirmedStreamOptions) {
+		opts.lastProcessedIndex = index
+	}
+}
+
+// WithReliableConfirmedStreamRetryPolicy defines the backoff between failed attempts to catch up with the node.
+// Attempts are retried until they succeed, MaxAttempts of the policy is ignored.
+func WithReliableConfirmedStreamRetryPolicy(retryPolicy *iotago.RetryPolicy) ReliableConfirmedStreamOption {
+	return func(opts *ReliableConfirmedStreamOptions) {
+		opts.retryPolicy = retryPolicy
+	}
+}
+
+// WithReliableConfirmedStreamErrorHandler defines a function which is called with every error
+// which made an attempt to catch up with the node fail before the attempt is retried.
+func WithReliableConfirmedStreamErrorHandler(onError func(err error)) ReliableConfirmedStreamOption {
+	return func(opts *ReliableConfirmedStreamOptions) {
+		opts.onError = onError
+	}
+}
+
+// the default options applied to the ReliableConfirmedStream.
+var defaultReliableConfirmedStreamOptions = []ReliableConfirmedStreamOption{
+	WithReliableConfirmedStreamPollInterval(10 * time.Second),
+	WithReliableConfirmedStreamRetryPolicy(iotago.DefaultRetryPolicy()),
+}
+
+// NewReliableConfirmedStream creates a new ReliableConfirmedStream using the given NodeHTTPAPIClient.
+func NewReliableConfirmedStream(nodeHTTPAPIClient iotago.NodeAPI, opts ...ReliableConfirmedStreamOption) *ReliableConfirmedStream {
+	options := &ReliableConfirmedStreamOptions{}
+	options.apply(defaultReliableConfirmedStreamOptions...)
+	options.apply(opts...)
+	return &ReliableConfirmedStream{nodeAPI: nodeHTTPAPIClient, opts: options, lastProcessed: options.lastProcessedIndex}
+}
+
+// ReliableConfirmedStream hands the ledger diffs of confirmed milestones to a consumer, without gaps,
+// ordered by milestone index and each milestone exactly once per run.
+// Confirmed milestone events of the NodeEventAPIClient only serve as a trigger, the confirmed milestone index
+// of the node is authoritative, so that milestones missed while being disconnected are backfilled
+// via MilestoneByIndex and MilestoneUTXOChangesByIndex.
+type ReliableConfirmedStream struct {
+	nodeAPI iotago.NodeAPI
+	opts    *ReliableConfirmedStreamOptions
+
+	mu            sync.Mutex
+	lastProcessed uint32
+	// whether the start index was determined.
+	started bool
+}
+
+// LastProcessedIndex returns the index of the last milestone whose ledger diff was processed successfully by the consumer.
+func (rcs *ReliableConfirmedStream) LastProcessedIndex() uint32 {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+	return rcs.lastProcessed
+}
+
+// Run hands the ledger diff of every milestone following the last processed one to the given handler
+// until the given context is done or an error occurs. A milestone only counts as processed once the handler
+// returns nil for it, an error of the handler ends Run. Errors while querying the node are retried according
+// to the configured RetryPolicy, only ErrReliableConfirmedStreamMilestonePruned ends Run as the stream can't
+// continue without a gap. Run fails right away if the confirmed milestones of the NodeEventAPIClient
+// can't be subscribed to, i.e. as it isn't connected.
+func (rcs *ReliableConfirmedStream) Run(ctx context.Context, handler func(diff *MilestoneLedgerDiff) error) error {
+	var confirmed <-chan *MilestonePointer
+	if neac := rcs.opts.eventAPIClient; neac != nil {
+		// only the latest event matters as it merely triggers catching up
+		sub, err := neac.ConfirmedMilestones(
+			WithSubscriptionBufferSize(1),
+			WithSubscriptionBufferPolicy(SubscriptionBufferPolicyDropOldest),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to subscribe to confirmed milestones: %w", err)
+		}
+		defer func() { _ = sub.Unsubscribe() }()
+		confirmed = sub.C
+	}
+
+	var handlerErr error
+	handle := func(diff *MilestoneLedgerDiff) error {
+		handlerErr = handler(diff)
+		return handlerErr
+	}
+
+	ticker := time.NewTicker(rcs.opts.pollInterval)
+	defer ticker.Stop()
+
+	for attempt := 1; ; attempt++ {
+		err := rcs.catchUp(ctx, handle)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err == nil:
+			attempt = 0
+		case handlerErr != nil || errors.Is(err, ErrReliableConfirmedStreamMilestonePruned):
+			return err
+		default:
+			if rcs.opts.onError != nil {
+				rcs.opts.onError(err)
+			}
+			timer := time.NewTimer(rcs.opts.retryPolicy.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-confirmed:
+			if !ok {
+				// the subscription got closed, rely on polling alone
+				confirmed = nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// processes all milestones up to the confirmed milestone index of the node.
+func (rcs *ReliableConfirmedStream) catchUp(ctx context.Context, handler func(diff *MilestoneLedgerDiff) error) error {
+	info, err := rcs.nodeAPI.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to query node info: %w", err)
+	}
+
+	rcs.mu.Lock()
+	if !rcs.started && rcs.lastProcessed == 0 && info.ConfirmedMilestoneIndex > 0 {
+		// nothing was processed yet, start with the currently confirmed milestone
+		rcs.lastProcessed = info.ConfirmedMilestoneIndex - 1
+	}
+	rcs.started = true
+	next := rcs.lastProcessed + 1
+	rcs.mu.Unlock()
+
+	if next <= info.PruningIndex && next <= info.ConfirmedMilestoneIndex {
+		return fmt.Errorf("%w: milestone %d, pruning index %d", ErrReliableConfirmedStreamMilestonePruned, next, info.PruningIndex)
+	}
+
+	for index := next; index <= info.ConfirmedMilestoneIndex; index++ {
+		diff, err := rcs.ledgerDiff(ctx, index)
+		if err != nil {
+			return err
+		}
+		if err := handler(diff); err != nil {
+			return fmt.Errorf("unable to process milestone %d: %w", index, err)
+		}
+
+		rcs.mu.Lock()
+		rcs.lastProcessed = index
+		rcs.mu.Unlock()
+	}
+	return nil
+}
+
+// fetches the ledger diff of the milestone with the given index.
+func (rcs *ReliableConfirmedStream) ledgerDiff(ctx context.Context, index uint32) (*MilestoneLedgerDiff, error) {
+	msRes, err := rcs.nodeAPI.MilestoneByIndex(ctx, index)
+	if err != nil {
+		if errors.Is(err, iotago.ErrHTTPNotFound) {
+			return nil, fmt.Errorf("%w: milestone %d: %s", ErrReliableConfirmedStreamMilestonePruned, index, err)
+		}
+		return nil, fmt.Errorf("unable to query milestone %d: %w", index, err)
+	}
+
+	msgID, err := iotago.MessageIDFromHexString(msRes.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode message ID of milestone %d: %w", index, err)
+	}
+
+	changes, err := rcs.nodeAPI.MilestoneUTXOChangesByIndex(ctx, index)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query UTXO changes of milestone %d: %w", index, err)
+	}
+
+	created, err := rcs.outputs(ctx, changes.CreatedOutputs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query outputs created by milestone %d: %w", index, err)
+	}
+
+	consumed, err := rcs.outputs(ctx, changes.ConsumedOutputs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query outputs consumed by milestone %d: %w", index, err)
+	}
+
+	return &MilestoneLedgerDiff{
+		Index:     index,
+		Timestamp: msRes.Time,
+		MessageID: msgID,
+		Created:   created,
+		Consumed:  consumed,
+	}, nil
+}
+
+// fetches the outputs with the given IDs, keeping their order.
+func (rcs *ReliableConfirmedStream) outputs(ctx context.Context, outputIDs []string) ([]*LedgerDiffOutput, error) {
+	outputIDHexes := make([]iotago.OutputIDHex, len(outputIDs))
+	for i, outputID := range outputIDs {
+		outputIDHexes[i] = iotago.OutputIDHex(outputID)
+	}
+
+	outputs, err := rcs.nodeAPI.OutputsByIDs(ctx, outputIDHexes)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[iotago.UTXOInputID]iotago.Output, len(outputs))
+	for utxoInput, output := range outputs {
+		byID[utxoInput.ID()] = output
+	}
+
+	diffOutputs := make([]*LedgerDiffOutput, 0, len(outputIDHexes))
+	for _, outputIDHex := range outputIDHexes {
+		utxoInput, err := outputIDHex.AsUTXOInput()
+		if err != nil {
+			return nil, err
+		}
+		id := utxoInput.ID()
+		output, has := byID[id]
+		if !has {
+			return nil, fmt.Errorf("output %s is missing in the response", outputIDHex)
+		}
+		diffOutputs = append(diffOutputs, &LedgerDiffOutput{ID: id, Output: output})
+	}
+	return diffOutputs, nil
+}
@@ -0,0 +1,194 @@
+package iotagox_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	iotago "github.com/iotaledger/iota.go/v2"
+	"github.com/iotaledger/iota.go/v2/ed25519"
+	"github.com/iotaledger/iota.go/v2/ledger"
+	"github.com/iotaledger/iota.go/v2/nodetest"
+	"github.com/iotaledger/iota.go/v2/tpkg"
+	"github.com/iotaledger/iota.go/v2/x"
+)
+
+func TestReliableConfirmedStream(t *testing.T) {
+	const mi = iotago.OutputSigLockedDustAllowanceOutputMinDeposit
+
+	prvKey := tpkg.RandEd25519PrivateKey()
+	alice := iotago.AddressFromEd25519PubKey(prvKey.Public().(ed25519.PublicKey))
+	bob, _ := tpkg.RandEd25519Address()
+
+	l := ledger.New()
+	genesis := &iotago.UTXOInput{TransactionID: tpkg.Rand32ByteArray()}
+	_, err := l.AddOutputs(&ledger.Output{ID: genesis.ID(), Output: &iotago.SigLockedSingleOutput{Address: &alice, Amount: 10 * mi}})
+	require.NoError(t, err)
+
+	node, err := nodetest.NewNode(nodetest.WithLedger(l))
+	require.NoError(t, err)
+	defer node.Close()
+	nodeAPI := node.Client()
+
+	transport := iotagox.NewFakeNodeEventTransport()
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithTransport(transport)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	require.NoError(t, eventAPIClient.Connect(ctx))
+
+	// only the events trigger catching up as the poll interval never elapses
+	stream := iotagox.NewReliableConfirmedStream(nodeAPI,
+		iotagox.WithReliableConfirmedStreamEventAPIClient(eventAPIClient),
+		iotagox.WithReliableConfirmedStreamPollInterval(time.Hour),
+	)
+
+	diffs := make(chan *iotagox.MilestoneLedgerDiff)
+	runErr := make(chan error, 1)
+	runCtx, cancelRun := context.WithCancel(ctx)
+	go func() {
+		runErr <- stream.Run(runCtx, func(diff *iotagox.MilestoneLedgerDiff) error {
+			diffs <- diff
+			return nil
+		})
+	}()
+	require.Eventually(t, func() bool {
+		return transport.IsSubscribed(iotagox.NodeEventMilestonesConfirmed)
+	}, time.Second, time.Millisecond)
+
+	publishConfirmed := func(index uint32) {
+		_, err := transport.PublishJSON(iotagox.NodeEventMilestonesConfirmed, &iotagox.MilestonePointer{Index: index})
+		require.NoError(t, err)
+	}
+
+	tx, err := iotago.NewTransactionBuilder().
+		AddInput(&iotago.ToBeSignedUTXOInput{Address: &alice, Input: genesis}).
+		AddOutput(&iotago.SigLockedSingleOutput{Address: bob, Amount: 4 * mi}).
+		AddOutput(&iotago.SigLockedSingleOutput{Address: &alice, Amount: 6 * mi}).
+		Build(iotago.NewInMemoryAddressSigner(iotago.NewAddressKeysForEd25519Address(&alice, prvKey)))
+	require.NoError(t, err)
+	_, err = nodeAPI.SubmitMessage(ctx, &iotago.Message{Payload: tx})
+	require.NoError(t, err)
+	msMsg, err := node.IssueMilestone()
+	require.NoError(t, err)
+	publishConfirmed(1)
+
+	diff := <-diffs
+	require.EqualValues(t, 1, diff.Index)
+	require.Equal(t, msMsg.MustID(), diff.MessageID)
+	require.Len(t, diff.Consumed, 1)
+	require.Equal(t, genesis.ID(), diff.Consumed[0].ID)
+	require.EqualValues(t, 10*mi, diff.Consumed[0].Output.(*iotago.SigLockedSingleOutput).Amount)
+	require.Len(t, diff.Created, 2)
+	var createdAmount uint64
+	for _, created := range diff.Created {
+		createdAmount += created.Output.(*iotago.SigLockedSingleOutput).Amount
+	}
+	require.EqualValues(t, 10*mi, createdAmount)
+
+	// the events of milestones 2 and 3 are missed, they get backfilled once milestone 4 is announced
+	for i := 0; i < 3; i++ {
+		_, err := node.IssueMilestone()
+		require.NoError(t, err)
+	}
+	publishConfirmed(4)
+	for index := uint32(2); index <= 4; index++ {
+		require.Equal(t, index, (<-diffs).Index)
+	}
+
+	// already processed milestones are not handed out again
+	publishConfirmed(3)
+	select {
+	case diff := <-diffs:
+		require.Failf(t, "unexpected ledger diff", "milestone %d", diff.Index)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancelRun()
+	require.True(t, errors.Is(<-runErr, context.Canceled))
+	require.EqualValues(t, 4, stream.LastProcessedIndex())
+
+	// a restarted stream continues after the last processed milestone by polling
+	_, err = node.IssueMilestone()
+	require.NoError(t, err)
+	_, err = node.IssueMilestone()
+	require.NoError(t, err)
+
+	errHandler := errors.New("unable to persist")
+	stream = iotagox.NewReliableConfirmedStream(nodeAPI,
+		iotagox.WithReliableConfirmedStreamLastProcessedIndex(stream.LastProcessedIndex()),
+		iotagox.WithReliableConfirmedStreamPollInterval(time.Millisecond),
+	)
+	var handled []uint32
+	err = stream.Run(ctx, func(diff *iotagox.MilestoneLedgerDiff) error {
+		if diff.Index == 6 {
+			return errHandler
+		}
+		handled = append(handled, diff.Index)
+		return nil
+	})
+	require.True(t, errors.Is(err, errHandler))
+	require.Equal(t, []uint32{5}, handled)
+	require.EqualValues(t, 5, stream.LastProcessedIndex())
+}
+
+func TestReliableConfirmedStream_EventAPIClientInactive(t *testing.T) {
+	// the event API client was never connected
+	eventAPIClient := iotagox.NewNodeEventAPIClientWithTransport(iotagox.NewFakeNodeEventTransport())
+	stream := iotagox.NewReliableConfirmedStream(iotago.NewNodeHTTPAPIClient("http://127.0.0.1:14265"), iotagox.WithReliableConfirmedStreamEventAPIClient(eventAPIClient))
+
+	err := stream.Run(context.Background(), func(diff *iotagox.MilestoneLedgerDiff) error {
+		return nil
+	})
+	require.True(t, errors.Is(err, iotagox.ErrNodeEventAPIClientInactive))
+}
+
+// a NodeAPI whose Info fails the given amount of times.
+type flakyInfoNodeAPI struct {
+	iotago.NodeAPI
+	mu       sync.Mutex
+	failures int
+}
+
+func (f *flakyInfoNodeAPI) Info(ctx context.Context) (*iotago.NodeInfoResponse, error) {
+	f.mu.Lock()
+	if f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		return nil, fmt.Errorf("%w: node restarting", iotago.ErrHTTPServiceUnavailable)
+	}
+	f.mu.Unlock()
+	return f.NodeAPI.Info(ctx)
+}
+
+func TestReliableConfirmedStream_RetriesNodeErrors(t *testing.T) {
+	node, err := nodetest.NewNode()
+	require.NoError(t, err)
+	defer node.Close()
+	_, err = node.IssueMilestone()
+	require.NoError(t, err)
+
+	var nodeErrs []error
+	stream := iotagox.NewReliableConfirmedStream(&flakyInfoNodeAPI{NodeAPI: node.Client(), failures: 2},
+		iotagox.WithReliableConfirmedStreamPollInterval(time.Hour),
+		iotagox.WithReliableConfirmedStreamRetryPolicy(&iotago.RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 1}),
+		iotagox.WithReliableConfirmedStreamErrorHandler(func(err error) {
+			nodeErrs = append(nodeErrs, err)
+		}),
+	)
+
+	// the handler ends Run once the node is reachable again
+	errDone := errors.New("done")
+	err = stream.Run(context.Background(), func(diff *iotagox.MilestoneLedgerDiff) error {
+		require.EqualValues(t, 1, diff.Index)
+		return errDone
+	})
+	require.True(t, errors.Is(err, errDone))
+	require.Len(t, nodeErrs, 2)
+	require.True(t, errors.Is(nodeErrs[0], iotago.ErrHTTPServiceUnavailable))
+	require.Zero(t, stream.LastProcessedIndex())
+}